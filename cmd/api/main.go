@@ -1,20 +1,33 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/joho/godotenv"
 
+	"github.com/fkhayef/splitwise/internal/activity"
+	"github.com/fkhayef/splitwise/internal/budget"
 	"github.com/fkhayef/splitwise/internal/config"
+	"github.com/fkhayef/splitwise/internal/dashboard"
 	"github.com/fkhayef/splitwise/internal/database"
+	"github.com/fkhayef/splitwise/internal/delivery"
 	"github.com/fkhayef/splitwise/internal/expense"
 	expensesplit "github.com/fkhayef/splitwise/internal/expense/split"
 	"github.com/fkhayef/splitwise/internal/group"
+	"github.com/fkhayef/splitwise/internal/importer"
+	"github.com/fkhayef/splitwise/internal/mailer"
 	"github.com/fkhayef/splitwise/internal/notification"
+	"github.com/fkhayef/splitwise/internal/reminder"
 	"github.com/fkhayef/splitwise/internal/settlement"
+	"github.com/fkhayef/splitwise/internal/statement"
+	"github.com/fkhayef/splitwise/internal/stream"
 	"github.com/fkhayef/splitwise/internal/user"
 	mw "github.com/fkhayef/splitwise/pkg/middleware"
 )
@@ -37,33 +50,127 @@ func main() {
 
 	log.Println("Connected to database successfully")
 
+	// Outgoing email
+	mail, err := mailer.New(mailer.Config{
+		Transport:    cfg.MailTransport,
+		From:         cfg.MailFrom,
+		Dir:          cfg.MailDir,
+		SMTPAddr:     cfg.MailSMTPAddr,
+		SMTPUsername: cfg.MailSMTPUsername,
+		SMTPPassword: cfg.MailSMTPPassword,
+	})
+	if err != nil {
+		log.Fatalf("Failed to configure mailer: %v", err)
+	}
+
 	// Split Strategy Factory (Factory Pattern)
 	splitFactory := expensesplit.NewSplitStrategyFactory()
 
+	// Activity feed (shared by group, expense and settlement features)
+	activityRepo := activity.NewRepository(db)
+	activityService := activity.NewService(activityRepo)
+
 	// User feature
 	userRepo := user.NewRepository(db)
 	userService := user.NewService(userRepo)
 	userHandler := user.NewHandler(userService)
 
+	// Notification delivery (outbox of email, Web Push and webhook sends, drained by a worker)
+	deliveryRepo := delivery.NewRepository(db)
+	channels := []delivery.Channel{
+		delivery.NewEmailChannel(mail, cfg.AppBaseURL),
+		delivery.NewWebhookChannel(deliveryRepo, cfg.AllowLocalWebhooks),
+	}
+	if cfg.VAPIDPrivateKey != "" {
+		push, err := delivery.NewWebPushChannel(deliveryRepo, cfg.VAPIDPrivateKey, cfg.VAPIDSubject)
+		if err != nil {
+			log.Fatalf("Failed to configure web push: %v", err)
+		}
+		channels = append(channels, push)
+	}
+	deliveryService := delivery.NewService(deliveryRepo, cfg.DeliveryMaxAttempts, cfg.AllowLocalWebhooks, channels...)
+	deliveryHandler := delivery.NewHandler(deliveryService)
+	deliveryWorker := delivery.NewWorker(deliveryService, deliveryRepo,
+		time.Duration(cfg.DeliveryIntervalSeconds)*time.Second)
+	deliveryWorker.Start(context.Background())
+
+	// Notification feature
+	notificationRepo := notification.NewRepository(db)
+	notificationService := notification.NewService(notificationRepo, deliveryService)
+	notificationHandler := notification.NewHandler(notificationService)
+
 	// Group feature
 	groupRepo := group.NewRepository(db)
-	groupService := group.NewService(groupRepo)
+	if cfg.InviteSecret == "" {
+		// A random key keeps development working without a shared, guessable one,
+		// but invitation links stop working when the server restarts
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			log.Fatalf("Failed to generate invitation key: %v", err)
+		}
+		cfg.InviteSecret = hex.EncodeToString(key)
+		log.Println("WARNING: INVITE_SECRET is not set; using a random key, so invitation links won't survive a restart")
+	}
+	groupInviter := group.NewInviter(mail, cfg.InviteSecret, time.Duration(cfg.InviteTTLHours)*time.Hour, cfg.AppBaseURL)
+	groupService := group.NewService(groupRepo, userRepo, activityService, groupInviter)
 	groupHandler := group.NewHandler(groupService)
 
+	// Temporary group reminders and auto-archiving
+	groupLifecycle := group.NewLifecycle(groupService, groupRepo, notificationService,
+		time.Duration(cfg.LifecycleIntervalMinutes)*time.Minute, cfg.SettleReminderDays)
+	groupLifecycle.Start(context.Background())
+
+	// Group budgets (alerts members through notifications as spending crosses thresholds)
+	budgetRepo := budget.NewRepository(db)
+	budgetService := budget.NewService(budgetRepo, groupService, notificationService)
+	budgetHandler := budget.NewHandler(budgetService)
+
 	// Expense feature (with split factory injected)
 	expenseRepo := expense.NewRepository(db)
-	expenseService := expense.NewService(expenseRepo, splitFactory)
+	expenseReports := expense.NewReportingRepository(db)
+	expenseService := expense.NewService(expenseRepo, expenseReports, splitFactory, activityService, groupService, budgetService)
 	expenseHandler := expense.NewHandler(expenseService)
 
-	// Settlement feature
+	// Settlement feature (with the configured payment providers)
+	var paymentProviders []settlement.PaymentProvider
+	if cfg.FakePaymentSecret != "" {
+		paymentProviders = append(paymentProviders, settlement.NewFakeProvider(cfg.FakePaymentSecret))
+	}
 	settlementRepo := settlement.NewRepository(db)
-	settlementService := settlement.NewService(settlementRepo, expenseRepo)
+	settlementService := settlement.NewService(settlementRepo, expenseRepo, activityService, groupService,
+		time.Duration(cfg.SettlementReversalDays)*24*time.Hour, paymentProviders...)
 	settlementHandler := settlement.NewHandler(settlementService)
 
-	// Notification feature
-	notificationRepo := notification.NewRepository(db)
-	notificationService := notification.NewService(notificationRepo)
-	notificationHandler := notification.NewHandler(notificationService)
+	// Dashboard (composes expense, settlement and activity data)
+	dashboardService := dashboard.NewService(expenseRepo, settlementRepo, activityService)
+	dashboardHandler := dashboard.NewHandler(dashboardService)
+
+	// Member statements (printable HTML/PDF)
+	statementService := statement.NewService(expenseReports, groupService)
+	statementHandler := statement.NewHandler(statementService)
+
+	// Payment reminders (scheduled reminders to debtors and manual nudges)
+	reminderRepo := reminder.NewRepository(db)
+	reminderService := reminder.NewService(reminderRepo, expenseRepo, settlementRepo, notificationService,
+		time.Duration(cfg.NudgeCooldownHours)*time.Hour)
+	reminderHandler := reminder.NewHandler(reminderService)
+	reminderScheduler := reminder.NewScheduler(reminderService, reminderRepo,
+		time.Duration(cfg.ReminderIntervalMinutes)*time.Minute,
+		time.Duration(cfg.ReminderAfterDays)*24*time.Hour,
+		time.Duration(cfg.ReminderRepeatDays)*24*time.Hour)
+	reminderScheduler.Start(context.Background())
+
+	// Real-time stream (events written by database triggers, fanned out with LISTEN/NOTIFY)
+	streamRepo := stream.NewRepository(db)
+	streamHub := stream.NewHub(streamRepo, cfg.DatabaseURL, time.Duration(cfg.StreamRetentionHours)*time.Hour)
+	streamHub.Start(context.Background())
+	streamService := stream.NewService(streamRepo, notificationService, activityService, settlementService)
+	streamHandler := stream.NewHandler(streamService, streamHub, time.Duration(cfg.StreamHeartbeatSeconds)*time.Second)
+
+	// Importers (create expenses through the expense service)
+	importRepo := importer.NewRepository(db)
+	importService := importer.NewService(importRepo, expenseService, expenseRepo, groupService)
+	importHandler := importer.NewHandler(importService)
 
 	r := chi.NewRouter()
 
@@ -71,20 +178,57 @@ func main() {
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.RequestID)
 	r.Use(mw.TestUserMiddleware)
+	r.Use(mw.LanguageMiddleware)
 
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(`{"status":"ok"}`))
 	})
 
+	// Endpoints served by features that depend on users and groups
+	userRoutes := userHandler.Routes()
+	userRoutes.Get("/me/dashboard", dashboardHandler.Get)
+	userRoutes.Get("/me/reminder-settings", reminderHandler.GetSettings)
+	userRoutes.Put("/me/reminder-settings", reminderHandler.UpdateSettings)
+	userRoutes.Get("/me/notification-preferences", deliveryHandler.GetPreferences)
+	userRoutes.Put("/me/notification-preferences", deliveryHandler.UpdatePreferences)
+	userRoutes.Get("/me/push-subscriptions", deliveryHandler.ListPushSubscriptions)
+	userRoutes.Post("/me/push-subscriptions", deliveryHandler.AddPushSubscription)
+	userRoutes.Delete("/me/push-subscriptions/{id}", deliveryHandler.RemovePushSubscription)
+	userRoutes.Get("/me/webhook", deliveryHandler.GetWebhook)
+	userRoutes.Put("/me/webhook", deliveryHandler.SetWebhook)
+	userRoutes.Delete("/me/webhook", deliveryHandler.DeleteWebhook)
+
+	groupRoutes := groupHandler.Routes()
+	groupRoutes.Get("/{id}/summary", expenseHandler.GroupSummary)
+	groupRoutes.Get("/{id}/export", expenseHandler.ExportGroupLedger)
+	groupRoutes.Get("/{id}/export/settlements", settlementHandler.ExportGroupSettlements)
+	groupRoutes.Post("/{id}/import/splitwise", importHandler.ImportSplitwise)
+	groupRoutes.Get("/{id}/statements/{userId}", statementHandler.Get)
+	groupRoutes.Mount("/{id}/budgets", budgetHandler.Routes())
+
+	expenseRoutes := expenseHandler.Routes()
+	expenseRoutes.Post("/splits/{splitId}/remind", reminderHandler.RemindSplit)
+
+	settlementRoutes := settlementHandler.Routes()
+	settlementRoutes.Post("/{id}/remind", reminderHandler.RemindSettlement)
+
+	notificationRoutes := notificationHandler.Routes()
+	notificationRoutes.Get("/{id}/deliveries", deliveryHandler.ListDeliveries)
+
 	// API routes
 	r.Route("/api/v1", func(r chi.Router) {
 		// Mount feature routers
-		r.Mount("/users", userHandler.Routes())
-		r.Mount("/groups", groupHandler.Routes())
-		r.Mount("/expenses", expenseHandler.Routes())
-		r.Mount("/settlements", settlementHandler.Routes())
-		r.Mount("/notifications", notificationHandler.Routes())
+		r.Mount("/users", userRoutes)
+		r.Mount("/groups", groupRoutes)
+		r.Mount("/expenses", expenseRoutes)
+		r.Mount("/settlements", settlementRoutes)
+		r.Mount("/notifications", notificationRoutes)
+		r.Mount("/imports", importHandler.Routes())
+		r.Get("/stream", streamHandler.Stream)
+
+		// Payment provider callbacks
+		r.Post("/webhooks/payments/{provider}", settlementHandler.PaymentWebhook)
 	})
 
 	// Start server
@@ -3,6 +3,8 @@ module github.com/fkhayef/splitwise
 go 1.25.6
 
 require (
+	codeberg.org/go-fonts/dejavu v0.4.0
+	codeberg.org/go-pdf/fpdf v0.12.0
 	github.com/go-chi/chi/v5 v5.2.4
 	github.com/joho/godotenv v1.5.1
 	github.com/lib/pq v1.10.9
@@ -0,0 +1,111 @@
+package activity
+
+import (
+	"fmt"
+
+	"github.com/fkhayef/splitwise/pkg/i18n"
+)
+
+// ActivityResponse represents a single entry in a group's activity feed
+type ActivityResponse struct {
+	ID            int64   `json:"id"`
+	GroupID       int64   `json:"group_id"`
+	ActorID       int64   `json:"actor_id,omitempty"`
+	ActorUsername string  `json:"actor_username,omitempty"`
+	Type          Type    `json:"type"`
+	Message       string  `json:"message"` // e.g., "john_doe added \"Dinner\" (SAR 90.00)"
+	EntityType    *string `json:"entity_type,omitempty"`
+	EntityID      *int64  `json:"entity_id,omitempty"`
+	Link          *string `json:"link,omitempty"` // API path of the related entity, if it still exists
+	CreatedAt     string  `json:"created_at"`
+}
+
+// ToResponse converts an Activity model to an ActivityResponse DTO, with its message in lang
+func (a *Activity) ToResponse(lang string) *ActivityResponse {
+	return &ActivityResponse{
+		ID:            a.ID,
+		GroupID:       a.GroupID,
+		ActorID:       a.ActorID,
+		ActorUsername: a.ActorUsername,
+		Type:          a.Type,
+		Message:       a.Message(lang),
+		EntityType:    a.EntityType,
+		EntityID:      a.EntityID,
+		Link:          a.Link(),
+		CreatedAt:     a.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+// Message renders a human-readable description of the activity in lang
+func (a *Activity) Message(lang string) string {
+	actor := a.ActorUsername
+	d := a.Details
+	amount := i18n.FormatMoney(lang, d.Amount, i18n.DefaultCurrency)
+
+	switch a.Type {
+	case TypeExpenseAdded:
+		return i18n.T(lang, "activity.expense_added", actor, d.Description, amount)
+	case TypeExpenseUpdated:
+		return i18n.T(lang, "activity.expense_updated", actor, d.Description)
+	case TypeExpenseDeleted:
+		return i18n.T(lang, "activity.expense_deleted", actor, d.Description, amount)
+	case TypeSplitPaid:
+		return i18n.T(lang, "activity.split_paid", actor, d.Description, amount)
+	case TypeSettlementConfirmed:
+		return i18n.T(lang, "activity.settlement_confirmed", actor, amount, d.Username)
+	case TypeSettlementReversed:
+		return i18n.T(lang, "activity.settlement_reversed", actor, amount, d.Username, d.Reason)
+	case TypeMemberJoined:
+		return i18n.T(lang, "activity.member_joined", actor)
+	case TypeMemberLeft:
+		return i18n.T(lang, "activity.member_left", actor)
+	case TypeMemberRemoved:
+		return i18n.T(lang, "activity.member_removed", actor, d.Username)
+	case TypeGroupArchived:
+		if a.ActorID == 0 {
+			return i18n.T(lang, "activity.group_auto_archived")
+		}
+		return i18n.T(lang, "activity.group_archived", actor)
+	case TypeGroupUnarchived:
+		return i18n.T(lang, "activity.group_unarchived", actor)
+	case TypeMemberRoleChanged:
+		if d.Role == "ADMIN" {
+			return i18n.T(lang, "activity.member_made_admin", actor, d.Username)
+		}
+		return i18n.T(lang, "activity.member_made_member", actor, d.Username)
+	case TypeOwnershipTransferred:
+		return i18n.T(lang, "activity.ownership_transferred", actor, d.Username)
+	default:
+		return i18n.T(lang, "activity.other", actor, string(a.Type))
+	}
+}
+
+// Link returns the API path of the entity the activity refers to.
+// Deleted expenses have no link.
+func (a *Activity) Link() *string {
+	if a.EntityType == nil || a.EntityID == nil {
+		return nil
+	}
+
+	var link string
+	switch *a.EntityType {
+	case EntityExpense:
+		if a.Type == TypeExpenseDeleted {
+			return nil
+		}
+		link = fmt.Sprintf("/api/v1/expenses/%d", *a.EntityID)
+	case EntitySplit:
+		// Splits have no endpoint of their own; point to the parent expense
+		if a.Details.ExpenseID == 0 {
+			return nil
+		}
+		link = fmt.Sprintf("/api/v1/expenses/%d", a.Details.ExpenseID)
+	case EntitySettlement:
+		link = fmt.Sprintf("/api/v1/settlements/%d", *a.EntityID)
+	case EntityGroup:
+		link = fmt.Sprintf("/api/v1/groups/%d", *a.EntityID)
+	default:
+		return nil
+	}
+	return &link
+}
@@ -0,0 +1,57 @@
+package activity
+
+import "time"
+
+// Type represents the kind of event recorded in a group's activity feed
+type Type string
+
+const (
+	TypeExpenseAdded         Type = "EXPENSE_ADDED"
+	TypeExpenseUpdated       Type = "EXPENSE_UPDATED"
+	TypeExpenseDeleted       Type = "EXPENSE_DELETED"
+	TypeSplitPaid            Type = "SPLIT_PAID"
+	TypeSettlementConfirmed  Type = "SETTLEMENT_CONFIRMED"
+	TypeSettlementReversed   Type = "SETTLEMENT_REVERSED"
+	TypeMemberJoined         Type = "MEMBER_JOINED"
+	TypeMemberLeft           Type = "MEMBER_LEFT"
+	TypeMemberRemoved        Type = "MEMBER_REMOVED"
+	TypeGroupArchived        Type = "GROUP_ARCHIVED"
+	TypeGroupUnarchived      Type = "GROUP_UNARCHIVED"
+	TypeMemberRoleChanged    Type = "MEMBER_ROLE_CHANGED"
+	TypeOwnershipTransferred Type = "OWNERSHIP_TRANSFERRED"
+)
+
+// Entity types an activity can point to
+const (
+	EntityExpense    = "EXPENSE"
+	EntitySplit      = "SPLIT"
+	EntitySettlement = "SETTLEMENT"
+	EntityGroup      = "GROUP"
+)
+
+// Activity represents a single event in a group's activity feed
+type Activity struct {
+	ID         int64     `json:"id"`
+	GroupID    int64     `json:"group_id"`
+	ActorID    int64     `json:"actor_id"` // Who performed the action; 0 for system events
+	Type       Type      `json:"type"`
+	EntityType *string   `json:"entity_type,omitempty"`
+	EntityID   *int64    `json:"entity_id,omitempty"`
+	Details    Details   `json:"details"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	// Populated via JOIN
+	ActorUsername string `json:"actor_username,omitempty"`
+}
+
+// Details holds the event-specific values used to render the message.
+// They are captured at the time of the event so the feed still reads
+// correctly after the underlying entity is edited or deleted.
+type Details struct {
+	Description string  `json:"description,omitempty"` // Expense description
+	Amount      float64 `json:"amount,omitempty"`
+	ExpenseID   int64   `json:"expense_id,omitempty"` // Parent expense for split events
+	Username    string  `json:"username,omitempty"`   // The other user involved, if any
+	Role        string  `json:"role,omitempty"`       // New role for role changes
+	Reason      string  `json:"reason,omitempty"`     // Why a settlement was reversed
+}
@@ -0,0 +1,167 @@
+package activity
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// Repository handles activity data persistence
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository creates a new activity repository
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Create inserts a new activity into the database
+func (r *Repository) Create(ctx context.Context, a *Activity) (*Activity, error) {
+	details, err := json.Marshal(a.Details)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode activity details: %w", err)
+	}
+
+	query := `
+		INSERT INTO group_activities (group_id, actor_id, type, entity_type, entity_id, details)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	// Actor 0 means the event was triggered by the system
+	var actorID sql.NullInt64
+	if a.ActorID != 0 {
+		actorID = sql.NullInt64{Int64: a.ActorID, Valid: true}
+	}
+
+	created := *a
+	err = r.db.QueryRowContext(ctx, query,
+		a.GroupID,
+		actorID,
+		a.Type,
+		a.EntityType,
+		a.EntityID,
+		details,
+	).Scan(&created.ID, &created.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create activity: %w", err)
+	}
+
+	return &created, nil
+}
+
+// GetByID retrieves an activity with its actor's username
+func (r *Repository) GetByID(ctx context.Context, id int64) (*Activity, error) {
+	query := `
+		SELECT a.id, a.group_id, a.actor_id, a.type, a.entity_type, a.entity_id, a.details, a.created_at,
+		       COALESCE(u.username, '')
+		FROM group_activities a
+		LEFT JOIN users u ON a.actor_id = u.id
+		WHERE a.id = $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity: %w", err)
+	}
+	defer rows.Close()
+
+	activities, err := scanActivities(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(activities) == 0 {
+		return nil, nil
+	}
+
+	return activities[0], nil
+}
+
+// ListByGroupID retrieves the activity feed for a group, newest first
+func (r *Repository) ListByGroupID(ctx context.Context, groupID int64, limit, offset int) ([]*Activity, int, error) {
+	// Get total count
+	var total int
+	countQuery := `SELECT COUNT(*) FROM group_activities WHERE group_id = $1`
+	if err := r.db.QueryRowContext(ctx, countQuery, groupID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count activities: %w", err)
+	}
+
+	// Get activities
+	query := `
+		SELECT a.id, a.group_id, a.actor_id, a.type, a.entity_type, a.entity_id, a.details, a.created_at,
+		       COALESCE(u.username, '')
+		FROM group_activities a
+		LEFT JOIN users u ON a.actor_id = u.id
+		WHERE a.group_id = $1
+		ORDER BY a.created_at DESC, a.id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, groupID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list activities: %w", err)
+	}
+	defer rows.Close()
+
+	activities, err := scanActivities(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return activities, total, nil
+}
+
+// ListForUser retrieves the most recent activity across all groups the user belongs
+// or used to belong to, newest first
+func (r *Repository) ListForUser(ctx context.Context, userID int64, limit int) ([]*Activity, error) {
+	query := `
+		SELECT a.id, a.group_id, a.actor_id, a.type, a.entity_type, a.entity_id, a.details, a.created_at,
+		       COALESCE(u.username, '')
+		FROM group_activities a
+		LEFT JOIN users u ON a.actor_id = u.id
+		WHERE a.group_id IN (
+			SELECT group_id FROM group_members WHERE user_id = $1 AND status != 'INVITED'
+		)
+		ORDER BY a.created_at DESC, a.id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list activities: %w", err)
+	}
+	defer rows.Close()
+
+	return scanActivities(rows)
+}
+
+// scanActivities reads activity rows selected with the actor's username
+func scanActivities(rows *sql.Rows) ([]*Activity, error) {
+	var activities []*Activity
+	for rows.Next() {
+		a := &Activity{}
+		var actorID sql.NullInt64
+		var details []byte
+		if err := rows.Scan(
+			&a.ID,
+			&a.GroupID,
+			&actorID,
+			&a.Type,
+			&a.EntityType,
+			&a.EntityID,
+			&details,
+			&a.CreatedAt,
+			&a.ActorUsername,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan activity: %w", err)
+		}
+		a.ActorID = actorID.Int64
+		if err := json.Unmarshal(details, &a.Details); err != nil {
+			return nil, fmt.Errorf("failed to decode activity details: %w", err)
+		}
+		activities = append(activities, a)
+	}
+	return activities, rows.Err()
+}
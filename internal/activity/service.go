@@ -0,0 +1,59 @@
+package activity
+
+import (
+	"context"
+	"log"
+)
+
+// Service handles activity feed business logic
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a new activity service
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Record appends an event to a group's activity feed.
+// Recording is best-effort: the action it describes has already happened,
+// so a failure here is logged rather than returned to the caller.
+func (s *Service) Record(ctx context.Context, groupID, actorID int64, activityType Type, entityType string, entityID int64, details Details) {
+	_, err := s.repo.Create(ctx, &Activity{
+		GroupID:    groupID,
+		ActorID:    actorID,
+		Type:       activityType,
+		EntityType: &entityType,
+		EntityID:   &entityID,
+		Details:    details,
+	})
+	if err != nil {
+		log.Printf("activity: failed to record %s for group %d: %v", activityType, groupID, err)
+	}
+}
+
+// GetByID retrieves an activity, or nil if it doesn't exist
+func (s *Service) GetByID(ctx context.Context, id int64) (*Activity, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListByGroupID retrieves the activity feed for a group
+func (s *Service) ListByGroupID(ctx context.Context, groupID int64, page, perPage int) ([]*Activity, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	offset := (page - 1) * perPage
+	return s.repo.ListByGroupID(ctx, groupID, perPage, offset)
+}
+
+// ListForUser retrieves recent activity across all of a user's groups
+func (s *Service) ListForUser(ctx context.Context, userID int64, limit int) ([]*Activity, error) {
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return s.repo.ListForUser(ctx, userID, limit)
+}
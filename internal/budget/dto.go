@@ -0,0 +1,48 @@
+package budget
+
+// CreateBudgetRequest represents the request to create a budget
+type CreateBudgetRequest struct {
+	Category     *string `json:"category,omitempty" validate:"omitempty,max=50"` // Omit for the overall budget
+	MonthlyLimit float64 `json:"monthly_limit" validate:"required,gt=0"`
+}
+
+// UpdateBudgetRequest represents the request to change a budget's limit
+type UpdateBudgetRequest struct {
+	MonthlyLimit float64 `json:"monthly_limit" validate:"required,gt=0"`
+}
+
+// BudgetResponse represents a budget with its progress in API responses
+type BudgetResponse struct {
+	ID             int64          `json:"id"`
+	GroupID        int64          `json:"group_id"`
+	Category       *string        `json:"category,omitempty"`
+	MonthlyLimit   float64        `json:"monthly_limit"`
+	Month          string         `json:"month"` // YYYY-MM
+	Spent          float64        `json:"spent"`
+	Remaining      float64        `json:"remaining"`
+	Percent        float64        `json:"percent"`
+	Projected      *float64       `json:"projected,omitempty"`
+	DailyAllowance *float64       `json:"daily_allowance,omitempty"`
+	Status         ProgressStatus `json:"status"`
+	CreatedAt      string         `json:"created_at"`
+	UpdatedAt      string         `json:"updated_at"`
+}
+
+// ToResponse converts a Progress model to a BudgetResponse DTO
+func (p *Progress) ToResponse() *BudgetResponse {
+	return &BudgetResponse{
+		ID:             p.Budget.ID,
+		GroupID:        p.Budget.GroupID,
+		Category:       p.Budget.Category,
+		MonthlyLimit:   p.Budget.MonthlyLimit,
+		Month:          p.Month.Format("2006-01"),
+		Spent:          p.Spent,
+		Remaining:      p.Remaining,
+		Percent:        p.Percent,
+		Projected:      p.Projected,
+		DailyAllowance: p.DailyAllowance,
+		Status:         p.Status,
+		CreatedAt:      p.Budget.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:      p.Budget.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
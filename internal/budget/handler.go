@@ -0,0 +1,204 @@
+package budget
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/fkhayef/splitwise/internal/group"
+	"github.com/fkhayef/splitwise/pkg/middleware"
+	"github.com/fkhayef/splitwise/pkg/response"
+)
+
+// Handler handles HTTP requests for group budgets
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new budget handler with service dependency injected
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes returns the router for budget endpoints.
+// It is mounted at /groups/{id}/budgets by main, since groups can't depend on budgets.
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.List)
+	r.Post("/", h.Create)
+	r.Get("/{budgetId}", h.Get)
+	r.Put("/{budgetId}", h.Update)
+	r.Delete("/{budgetId}", h.Delete)
+
+	return r
+}
+
+// List handles GET /groups/{id}/budgets?month=YYYY-MM
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid group ID")
+		return
+	}
+
+	month, err := ParseMonth(r.URL.Query().Get("month"))
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	progress, err := h.service.List(r.Context(), groupID, month)
+	if err != nil {
+		h.handleError(w, err, "Failed to list budgets")
+		return
+	}
+
+	responses := make([]*BudgetResponse, len(progress))
+	for i, p := range progress {
+		responses[i] = p.ToResponse()
+	}
+
+	response.JSON(w, http.StatusOK, responses)
+}
+
+// Create handles POST /groups/{id}/budgets
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid group ID")
+		return
+	}
+
+	var req CreateBudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+	if req.MonthlyLimit <= 0 {
+		response.BadRequest(w, "monthly_limit must be greater than zero")
+		return
+	}
+	if req.Category != nil && len(*req.Category) > 50 {
+		response.BadRequest(w, "category must be at most 50 characters")
+		return
+	}
+
+	progress, err := h.service.Create(r.Context(), groupID, userID, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to create budget")
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, progress.ToResponse())
+}
+
+// Get handles GET /groups/{id}/budgets/{budgetId}?month=YYYY-MM
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	groupID, budgetID, ok := parseIDs(w, r)
+	if !ok {
+		return
+	}
+
+	month, err := ParseMonth(r.URL.Query().Get("month"))
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	progress, err := h.service.Get(r.Context(), groupID, budgetID, month)
+	if err != nil {
+		h.handleError(w, err, "Failed to get budget")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, progress.ToResponse())
+}
+
+// Update handles PUT /groups/{id}/budgets/{budgetId}
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	groupID, budgetID, ok := parseIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateBudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+	if req.MonthlyLimit <= 0 {
+		response.BadRequest(w, "monthly_limit must be greater than zero")
+		return
+	}
+
+	progress, err := h.service.Update(r.Context(), groupID, budgetID, userID, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to update budget")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, progress.ToResponse())
+}
+
+// Delete handles DELETE /groups/{id}/budgets/{budgetId}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	groupID, budgetID, ok := parseIDs(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), groupID, budgetID, userID); err != nil {
+		h.handleError(w, err, "Failed to delete budget")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{"message": "Budget deleted successfully"})
+}
+
+// parseIDs reads the group and budget IDs from the URL, writing a 400 if either is invalid
+func parseIDs(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid group ID")
+		return 0, 0, false
+	}
+	budgetID, err := strconv.ParseInt(chi.URLParam(r, "budgetId"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid budget ID")
+		return 0, 0, false
+	}
+	return groupID, budgetID, true
+}
+
+// handleError maps service errors to responses
+func (h *Handler) handleError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, group.ErrGroupNotFound), errors.Is(err, ErrBudgetNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, group.ErrNotAuthorized):
+		response.Forbidden(w, err.Error())
+	case errors.Is(err, ErrBudgetExists), errors.Is(err, group.ErrGroupArchived):
+		response.Conflict(w, err.Error())
+	default:
+		response.InternalError(w, fallback)
+	}
+}
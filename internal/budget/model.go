@@ -0,0 +1,49 @@
+package budget
+
+import (
+	"strings"
+	"time"
+)
+
+// Budget is a monthly spending limit for a group, overall or for one expense category
+type Budget struct {
+	ID           int64     `json:"id"`
+	GroupID      int64     `json:"group_id"`
+	Category     *string   `json:"category,omitempty"` // nil for the overall budget
+	MonthlyLimit float64   `json:"monthly_limit"`
+	CreatedBy    *int64    `json:"created_by,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Matches reports whether an expense category counts towards the budget
+func (b *Budget) Matches(category string) bool {
+	return b.Category == nil || strings.EqualFold(*b.Category, category)
+}
+
+// ProgressStatus summarises how a budget is doing this month
+type ProgressStatus string
+
+const (
+	ProgressStatusOnTrack ProgressStatus = "ON_TRACK"
+	ProgressStatusWarning ProgressStatus = "WARNING" // Past the first alert threshold
+	ProgressStatusOver    ProgressStatus = "OVER"
+)
+
+// Progress is a budget's actual spend for one month
+type Progress struct {
+	Budget    *Budget
+	Month     time.Time // First day of the month, UTC
+	Spent     float64
+	Remaining float64 // Negative when over budget
+	Percent   float64 // Spent as a percentage of the limit
+
+	// Projected is the month-end spend if the pace so far continues;
+	// only set for the current month
+	Projected *float64
+	// DailyAllowance is what can be spent per remaining day, including today,
+	// to stay within the limit; only set for the current month
+	DailyAllowance *float64
+
+	Status ProgressStatus
+}
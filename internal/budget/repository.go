@@ -0,0 +1,199 @@
+package budget
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Repository handles budget data persistence
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository creates a new budget repository
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Create inserts a new budget
+func (r *Repository) Create(ctx context.Context, groupID, createdBy int64, req *CreateBudgetRequest) (*Budget, error) {
+	query := `
+		INSERT INTO group_budgets (group_id, category, monthly_limit, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, group_id, category, monthly_limit, created_by, created_at, updated_at
+	`
+
+	b := &Budget{}
+	err := r.db.QueryRowContext(ctx, query, groupID, req.Category, req.MonthlyLimit, createdBy).Scan(
+		&b.ID,
+		&b.GroupID,
+		&b.Category,
+		&b.MonthlyLimit,
+		&b.CreatedBy,
+		&b.CreatedAt,
+		&b.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create budget: %w", err)
+	}
+
+	return b, nil
+}
+
+// GetByID retrieves a budget by its ID
+func (r *Repository) GetByID(ctx context.Context, id int64) (*Budget, error) {
+	query := `
+		SELECT id, group_id, category, monthly_limit, created_by, created_at, updated_at
+		FROM group_budgets
+		WHERE id = $1
+	`
+
+	b := &Budget{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&b.ID,
+		&b.GroupID,
+		&b.Category,
+		&b.MonthlyLimit,
+		&b.CreatedBy,
+		&b.CreatedAt,
+		&b.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	return b, nil
+}
+
+// ListByGroupID retrieves a group's budgets, the overall budget first
+func (r *Repository) ListByGroupID(ctx context.Context, groupID int64) ([]*Budget, error) {
+	query := `
+		SELECT id, group_id, category, monthly_limit, created_by, created_at, updated_at
+		FROM group_budgets
+		WHERE group_id = $1
+		ORDER BY category NULLS FIRST, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list budgets: %w", err)
+	}
+	defer rows.Close()
+
+	var budgets []*Budget
+	for rows.Next() {
+		b := &Budget{}
+		if err := rows.Scan(
+			&b.ID,
+			&b.GroupID,
+			&b.Category,
+			&b.MonthlyLimit,
+			&b.CreatedBy,
+			&b.CreatedAt,
+			&b.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan budget: %w", err)
+		}
+		budgets = append(budgets, b)
+	}
+
+	return budgets, rows.Err()
+}
+
+// UpdateLimit changes a budget's monthly limit
+func (r *Repository) UpdateLimit(ctx context.Context, id int64, monthlyLimit float64) (*Budget, error) {
+	query := `
+		UPDATE group_budgets
+		SET monthly_limit = $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING id, group_id, category, monthly_limit, created_by, created_at, updated_at
+	`
+
+	b := &Budget{}
+	err := r.db.QueryRowContext(ctx, query, id, monthlyLimit).Scan(
+		&b.ID,
+		&b.GroupID,
+		&b.Category,
+		&b.MonthlyLimit,
+		&b.CreatedBy,
+		&b.CreatedAt,
+		&b.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update budget: %w", err)
+	}
+
+	return b, nil
+}
+
+// Delete removes a budget and its alert history
+func (r *Repository) Delete(ctx context.Context, id int64) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM group_budgets WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+	return nil
+}
+
+// GetCategorySpend returns the total spent per expense category in a group in [from, to)
+func (r *Repository) GetCategorySpend(ctx context.Context, groupID int64, from, to time.Time) (map[string]float64, error) {
+	query := `
+		SELECT category, SUM(amount)
+		FROM expenses
+		WHERE group_id = $1 AND created_at >= $2 AND created_at < $3
+		GROUP BY category
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, groupID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category spend: %w", err)
+	}
+	defer rows.Close()
+
+	spend := map[string]float64{}
+	for rows.Next() {
+		var category string
+		var total float64
+		if err := rows.Scan(&category, &total); err != nil {
+			return nil, fmt.Errorf("failed to scan category spend: %w", err)
+		}
+		spend[category] = total
+	}
+
+	return spend, rows.Err()
+}
+
+// RecordAlert remembers that a threshold alert was sent for a budget's month.
+// It returns false if the alert had already been recorded.
+func (r *Repository) RecordAlert(ctx context.Context, budgetID int64, month time.Time, threshold int) (bool, error) {
+	query := `
+		INSERT INTO budget_alerts (budget_id, month, threshold)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query, budgetID, month, threshold)
+	if err != nil {
+		return false, fmt.Errorf("failed to record budget alert: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to record budget alert: %w", err)
+	}
+	return n > 0, nil
+}
+
+// ClearAlertsAbove forgets a month's alerts for thresholds above percent,
+// so they can fire again after a budget's limit is raised
+func (r *Repository) ClearAlertsAbove(ctx context.Context, budgetID int64, month time.Time, percent float64) error {
+	query := `DELETE FROM budget_alerts WHERE budget_id = $1 AND month = $2 AND threshold > $3`
+
+	if _, err := r.db.ExecContext(ctx, query, budgetID, month, percent); err != nil {
+		return fmt.Errorf("failed to clear budget alerts: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/fkhayef/splitwise/internal/group"
+	"github.com/fkhayef/splitwise/internal/notification"
+)
+
+// Common errors
+var (
+	ErrBudgetNotFound = errors.New("budget not found")
+	ErrBudgetExists   = errors.New("the group already has a budget for this category")
+	ErrInvalidMonth   = errors.New("month must be formatted as YYYY-MM")
+)
+
+// alertThresholds are the percentages of a budget at which members are notified
+var alertThresholds = []int{80, 100}
+
+// Service handles budget business logic
+type Service struct {
+	repo          *Repository
+	groups        *group.Service
+	notifications *notification.Service
+}
+
+// NewService creates a new budget service
+func NewService(repo *Repository, groupService *group.Service, notificationService *notification.Service) *Service {
+	return &Service{
+		repo:          repo,
+		groups:        groupService,
+		notifications: notificationService,
+	}
+}
+
+// Create adds a monthly budget to a group (admins only).
+// Leaving out the category creates the overall budget.
+func (s *Service) Create(ctx context.Context, groupID, actorID int64, req *CreateBudgetRequest) (*Progress, error) {
+	if err := s.requireAdmin(ctx, groupID, actorID); err != nil {
+		return nil, err
+	}
+
+	if req.Category != nil {
+		category := strings.TrimSpace(*req.Category)
+		req.Category = &category
+		if category == "" {
+			req.Category = nil
+		}
+	}
+
+	existing, err := s.repo.ListByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range existing {
+		if sameScope(b.Category, req.Category) {
+			return nil, ErrBudgetExists
+		}
+	}
+
+	b, err := s.repo.Create(ctx, groupID, actorID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// The month may already be past a threshold
+	s.CheckThresholds(ctx, groupID, time.Now())
+
+	return s.progress(ctx, b, monthStart(time.Now()))
+}
+
+// List returns a group's budgets with their progress in a month
+func (s *Service) List(ctx context.Context, groupID int64, month time.Time) ([]*Progress, error) {
+	if _, err := s.groups.GetByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	budgets, err := s.repo.ListByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return s.progressAll(ctx, groupID, budgets, monthStart(month))
+}
+
+// Get returns one budget with its progress in a month
+func (s *Service) Get(ctx context.Context, groupID, budgetID int64, month time.Time) (*Progress, error) {
+	b, err := s.getBudget(ctx, groupID, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	return s.progress(ctx, b, monthStart(month))
+}
+
+// Update changes a budget's monthly limit (admins only).
+// Alerts this month for thresholds no longer reached can fire again.
+func (s *Service) Update(ctx context.Context, groupID, budgetID, actorID int64, req *UpdateBudgetRequest) (*Progress, error) {
+	if err := s.requireAdmin(ctx, groupID, actorID); err != nil {
+		return nil, err
+	}
+	if _, err := s.getBudget(ctx, groupID, budgetID); err != nil {
+		return nil, err
+	}
+
+	b, err := s.repo.UpdateLimit(ctx, budgetID, req.MonthlyLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	month := monthStart(time.Now())
+	p, err := s.progress(ctx, b, month)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ClearAlertsAbove(ctx, b.ID, month, p.Percent); err != nil {
+		return nil, err
+	}
+	s.CheckThresholds(ctx, groupID, time.Now())
+
+	return p, nil
+}
+
+// Delete removes a budget (admins only)
+func (s *Service) Delete(ctx context.Context, groupID, budgetID, actorID int64) error {
+	if err := s.requireAdmin(ctx, groupID, actorID); err != nil {
+		return err
+	}
+	if _, err := s.getBudget(ctx, groupID, budgetID); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, budgetID)
+}
+
+// CheckThresholds notifies the group's members when spending this month has
+// crossed an alert threshold of one of its budgets. Each threshold fires once
+// per budget and month. Expenses dated in other months don't raise alerts.
+// Like activity recording it is best-effort: failures are logged, not returned.
+func (s *Service) CheckThresholds(ctx context.Context, groupID int64, expenseDate time.Time) {
+	now := time.Now()
+	month := monthStart(now)
+	if !monthStart(expenseDate).Equal(month) {
+		return
+	}
+
+	budgets, err := s.repo.ListByGroupID(ctx, groupID)
+	if err != nil || len(budgets) == 0 {
+		if err != nil {
+			log.Printf("budget: failed to list budgets for group %d: %v", groupID, err)
+		}
+		return
+	}
+
+	progress, err := s.progressAll(ctx, groupID, budgets, month)
+	if err != nil {
+		log.Printf("budget: failed to compute progress for group %d: %v", groupID, err)
+		return
+	}
+
+	var g *group.Group
+	var members []*group.GroupMember
+	for _, p := range progress {
+		for _, threshold := range alertThresholds {
+			if p.Percent < float64(threshold) {
+				continue
+			}
+
+			recorded, err := s.repo.RecordAlert(ctx, p.Budget.ID, month, threshold)
+			if err != nil {
+				log.Printf("budget: %v", err)
+				continue
+			}
+			if !recorded {
+				continue
+			}
+
+			if g == nil {
+				if g, members, err = s.groups.GetByIDWithMembers(ctx, groupID); err != nil {
+					log.Printf("budget: failed to load group %d: %v", groupID, err)
+					return
+				}
+			}
+			for _, m := range members {
+				if m.Status != group.MemberStatusJoined {
+					continue
+				}
+				if _, err := s.notifications.NotifyBudgetThreshold(ctx, m.UserID, g.Name, p.Budget.Category,
+					month, threshold, p.Spent, p.Budget.MonthlyLimit, groupID); err != nil {
+					log.Printf("budget: failed to notify user %d in group %d: %v", m.UserID, groupID, err)
+				}
+			}
+		}
+	}
+}
+
+// ParseMonth parses a YYYY-MM month; empty means the current month
+func ParseMonth(s string) (time.Time, error) {
+	if s == "" {
+		return monthStart(time.Now()), nil
+	}
+	t, err := time.Parse("2006-01", s)
+	if err != nil {
+		return time.Time{}, ErrInvalidMonth
+	}
+	return t, nil
+}
+
+// getBudget retrieves a budget, checking it belongs to the group
+func (s *Service) getBudget(ctx context.Context, groupID, budgetID int64) (*Budget, error) {
+	b, err := s.repo.GetByID(ctx, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil || b.GroupID != groupID {
+		return nil, ErrBudgetNotFound
+	}
+	return b, nil
+}
+
+// requireAdmin checks that the group can be changed and the user is one of its admins
+func (s *Service) requireAdmin(ctx context.Context, groupID, userID int64) error {
+	if err := s.groups.EnsureWritable(ctx, groupID); err != nil {
+		return err
+	}
+	isAdmin, err := s.groups.IsAdmin(ctx, groupID, userID)
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return group.ErrNotAuthorized
+	}
+	return nil
+}
+
+// progress computes one budget's progress in a month
+func (s *Service) progress(ctx context.Context, b *Budget, month time.Time) (*Progress, error) {
+	all, err := s.progressAll(ctx, b.GroupID, []*Budget{b}, month)
+	if err != nil {
+		return nil, err
+	}
+	return all[0], nil
+}
+
+// progressAll computes the progress of a group's budgets in a month from a single spend query
+func (s *Service) progressAll(ctx context.Context, groupID int64, budgets []*Budget, month time.Time) ([]*Progress, error) {
+	next := month.AddDate(0, 1, 0)
+	spend, err := s.repo.GetCategorySpend(ctx, groupID, month, next)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	isCurrent := monthStart(now).Equal(month)
+	daysInMonth := next.AddDate(0, 0, -1).Day()
+
+	progress := make([]*Progress, len(budgets))
+	for i, b := range budgets {
+		p := &Progress{Budget: b, Month: month}
+		for category, total := range spend {
+			if b.Matches(category) {
+				p.Spent += total
+			}
+		}
+		p.Spent = roundCents(p.Spent)
+		p.Remaining = roundCents(b.MonthlyLimit - p.Spent)
+		p.Percent = math.Round(p.Spent/b.MonthlyLimit*1000) / 10
+
+		if isCurrent {
+			projected := roundCents(p.Spent / float64(now.Day()) * float64(daysInMonth))
+			allowance := roundCents(math.Max(p.Remaining, 0) / float64(daysInMonth-now.Day()+1))
+			p.Projected = &projected
+			p.DailyAllowance = &allowance
+		}
+
+		switch {
+		case p.Percent >= 100:
+			p.Status = ProgressStatusOver
+		case p.Percent >= float64(alertThresholds[0]):
+			p.Status = ProgressStatusWarning
+		default:
+			p.Status = ProgressStatusOnTrack
+		}
+		progress[i] = p
+	}
+
+	return progress, nil
+}
+
+// sameScope reports whether two budgets would cover the same spending
+func sameScope(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return strings.EqualFold(*a, *b)
+}
+
+// monthStart returns midnight UTC on the first day of t's month
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// roundCents rounds an amount to two decimal places
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,90 @@
+package dashboard
+
+import (
+	"github.com/fkhayef/splitwise/internal/activity"
+	"github.com/fkhayef/splitwise/internal/expense"
+	"github.com/fkhayef/splitwise/internal/settlement"
+)
+
+// DashboardResponse represents the caller's dashboard
+type DashboardResponse struct {
+	UserID         int64                        `json:"user_id"`
+	TotalOwedToYou float64                      `json:"total_owed_to_you"`
+	TotalYouOwe    float64                      `json:"total_you_owe"`
+	NetBalance     float64                      `json:"net_balance"` // Positive = you owe overall
+	Groups         []*GroupBalanceResponse      `json:"groups"`
+	RecentActivity []*activity.ActivityResponse `json:"recent_activity"`
+
+	PendingConfirmations *PendingConfirmationsResponse `json:"pending_confirmations"`
+	Disputes             []*SplitItemResponse          `json:"disputes"`
+}
+
+// GroupBalanceResponse represents the caller's open balance within one group
+type GroupBalanceResponse struct {
+	GroupID    int64   `json:"group_id"`
+	GroupName  string  `json:"group_name"`
+	OwedToYou  float64 `json:"owed_to_you"`
+	YouOwe     float64 `json:"you_owe"`
+	NetBalance float64 `json:"net_balance"` // Positive = you owe
+}
+
+// PendingConfirmationsResponse lists payments the caller needs to confirm
+type PendingConfirmationsResponse struct {
+	Splits      []*SplitItemResponse             `json:"splits"`
+	Settlements []*settlement.SettlementResponse `json:"settlements"`
+}
+
+// SplitItemResponse represents a split awaiting the caller, with its expense
+type SplitItemResponse struct {
+	*expense.SplitResponse
+	ExpenseDescription string `json:"expense_description"`
+	GroupID            int64  `json:"group_id"`
+}
+
+// ToResponse converts a Dashboard model to a DashboardResponse DTO, with activity messages in lang
+func (d *Dashboard) ToResponse(lang string) *DashboardResponse {
+	resp := &DashboardResponse{
+		UserID:         d.UserID,
+		TotalOwedToYou: d.TotalOwedToYou,
+		TotalYouOwe:    d.TotalYouOwe,
+		NetBalance:     d.TotalYouOwe - d.TotalOwedToYou,
+		Groups:         make([]*GroupBalanceResponse, len(d.Groups)),
+		RecentActivity: make([]*activity.ActivityResponse, len(d.RecentActivity)),
+		PendingConfirmations: &PendingConfirmationsResponse{
+			Splits:      splitItems(d.SplitsToConfirm),
+			Settlements: make([]*settlement.SettlementResponse, len(d.SettlementsToConfirm)),
+		},
+		Disputes: splitItems(d.Disputes),
+	}
+
+	for i, g := range d.Groups {
+		resp.Groups[i] = &GroupBalanceResponse{
+			GroupID:    g.GroupID,
+			GroupName:  g.GroupName,
+			OwedToYou:  g.OwedToUser,
+			YouOwe:     g.UserOwes,
+			NetBalance: g.UserOwes - g.OwedToUser,
+		}
+	}
+	for i, a := range d.RecentActivity {
+		resp.RecentActivity[i] = a.ToResponse(lang)
+	}
+	for i, s := range d.SettlementsToConfirm {
+		resp.PendingConfirmations.Settlements[i] = s.ToResponse()
+	}
+
+	return resp
+}
+
+// splitItems converts splits with their expense details to response DTOs
+func splitItems(splits []*expense.SplitWithExpense) []*SplitItemResponse {
+	items := make([]*SplitItemResponse, len(splits))
+	for i, s := range splits {
+		items[i] = &SplitItemResponse{
+			SplitResponse:      s.Split.ToResponse(),
+			ExpenseDescription: s.ExpenseDescription,
+			GroupID:            s.GroupID,
+		}
+	}
+	return items
+}
@@ -0,0 +1,35 @@
+package dashboard
+
+import (
+	"net/http"
+
+	"github.com/fkhayef/splitwise/pkg/i18n"
+	"github.com/fkhayef/splitwise/pkg/middleware"
+	"github.com/fkhayef/splitwise/pkg/response"
+)
+
+// Handler handles HTTP requests for the dashboard
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new dashboard handler with service dependency injected
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Get handles GET /users/me/dashboard
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	d, err := h.service.Get(r.Context(), userID)
+	if err != nil {
+		response.InternalError(w, "Failed to load dashboard")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, d.ToResponse(i18n.Language(r.Context())))
+}
@@ -0,0 +1,21 @@
+package dashboard
+
+import (
+	"github.com/fkhayef/splitwise/internal/activity"
+	"github.com/fkhayef/splitwise/internal/expense"
+	"github.com/fkhayef/splitwise/internal/settlement"
+)
+
+// Dashboard is a user's overview across all of their groups
+type Dashboard struct {
+	UserID         int64
+	TotalOwedToYou float64
+	TotalYouOwe    float64
+	Groups         []*expense.GroupBalance
+	RecentActivity []*activity.Activity
+
+	// Things waiting on the user
+	SplitsToConfirm      []*expense.SplitWithExpense // PAID splits on expenses they paid
+	SettlementsToConfirm []*settlement.Settlement    // PAID settlements they are receiving
+	Disputes             []*expense.SplitWithExpense // DISPUTED splits on expenses they paid
+}
@@ -0,0 +1,64 @@
+package dashboard
+
+import (
+	"context"
+
+	"github.com/fkhayef/splitwise/internal/activity"
+	"github.com/fkhayef/splitwise/internal/expense"
+	"github.com/fkhayef/splitwise/internal/settlement"
+)
+
+// recentActivityLimit is the number of feed entries shown on the dashboard
+const recentActivityLimit = 10
+
+// Service assembles a user's dashboard from the expense, settlement and activity features
+type Service struct {
+	expenseRepo    *expense.Repository
+	settlementRepo *settlement.Repository
+	activity       *activity.Service
+}
+
+// NewService creates a new dashboard service
+func NewService(expenseRepo *expense.Repository, settlementRepo *settlement.Repository, activityService *activity.Service) *Service {
+	return &Service{
+		expenseRepo:    expenseRepo,
+		settlementRepo: settlementRepo,
+		activity:       activityService,
+	}
+}
+
+// Get builds the dashboard for a user
+func (s *Service) Get(ctx context.Context, userID int64) (*Dashboard, error) {
+	d := &Dashboard{UserID: userID}
+
+	// Overall totals use the same per-person netting as settlements
+	balances, err := s.settlementRepo.GetNetBalancesForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range balances {
+		if b.Amount > 0 {
+			d.TotalYouOwe += b.Amount
+		} else {
+			d.TotalOwedToYou -= b.Amount
+		}
+	}
+
+	if d.Groups, err = s.expenseRepo.GetGroupBalancesForUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if d.RecentActivity, err = s.activity.ListForUser(ctx, userID, recentActivityLimit); err != nil {
+		return nil, err
+	}
+	if d.SplitsToConfirm, err = s.expenseRepo.ListSplitsForPayer(ctx, userID, expense.SplitStatusPaid); err != nil {
+		return nil, err
+	}
+	if d.SettlementsToConfirm, err = s.settlementRepo.ListByReceiverAndStatus(ctx, userID, settlement.SettlementStatusPaid); err != nil {
+		return nil, err
+	}
+	if d.Disputes, err = s.expenseRepo.ListSplitsForPayer(ctx, userID, expense.SplitStatusDisputed); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
@@ -0,0 +1,35 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fkhayef/splitwise/pkg/i18n"
+)
+
+// ErrNoDestination is returned by channels with nowhere to send a delivery,
+// e.g. when every push subscription has expired. The delivery is skipped.
+var ErrNoDestination = errors.New("nowhere to deliver to")
+
+// Channel is the interface that all delivery channels must implement
+type Channel interface {
+	// Name identifies the channel in preferences and the outbox, e.g. "EMAIL"
+	Name() string
+	// Ready reports whether the user has set the channel up, so deliveries
+	// are only queued for users who can receive them
+	Ready(ctx context.Context, userID int64) (bool, error)
+	// Send delivers a notification, returning an error to have it retried
+	Send(ctx context.Context, d *Delivery) error
+}
+
+// subject is the short title of a delivery: the email subject or push title
+func subject(d *Delivery) string {
+	lang := d.Recipient.Lang()
+	if d.Notification.Type != "" {
+		key := "delivery.subject." + string(d.Notification.Type)
+		if s := i18n.T(lang, key); s != key {
+			return s
+		}
+	}
+	return i18n.T(lang, "delivery.subject")
+}
@@ -0,0 +1,86 @@
+package delivery
+
+import (
+	"time"
+
+	"github.com/fkhayef/splitwise/internal/notification"
+)
+
+// PreferenceRequest turns one notification type on or off for one channel
+type PreferenceRequest struct {
+	Type    notification.NotificationType `json:"type"`
+	Channel string                        `json:"channel"`
+	Enabled bool                          `json:"enabled"`
+}
+
+// UpdatePreferencesRequest changes the given preferences; others are kept
+type UpdatePreferencesRequest struct {
+	Preferences []PreferenceRequest `json:"preferences"`
+}
+
+// PreferenceResponse is whether one notification type goes out on each channel
+type PreferenceResponse struct {
+	Type     notification.NotificationType `json:"type"`
+	Channels map[string]bool               `json:"channels"`
+}
+
+// PushSubscriptionRequest is a browser's PushSubscription, as returned by its toJSON()
+type PushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// PushSubscriptionsResponse lists a user's push subscriptions with the key to subscribe with
+type PushSubscriptionsResponse struct {
+	VAPIDPublicKey string              `json:"vapid_public_key,omitempty"`
+	Subscriptions  []*PushSubscription `json:"subscriptions"`
+}
+
+// WebhookRequest sets the URL a user's notifications are posted to
+type WebhookRequest struct {
+	URL string `json:"url"`
+}
+
+// WebhookResponse represents a user's webhook in API responses
+type WebhookResponse struct {
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"` // Only returned when the webhook is set
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ToResponse converts WebhookEndpoint to WebhookResponse, without the secret
+func (e *WebhookEndpoint) ToResponse() *WebhookResponse {
+	return &WebhookResponse{
+		URL:       e.URL,
+		CreatedAt: e.CreatedAt,
+		UpdatedAt: e.UpdatedAt,
+	}
+}
+
+// DeliveryResponse represents a delivery in API responses
+type DeliveryResponse struct {
+	ID        int64      `json:"id"`
+	Channel   string     `json:"channel"`
+	Status    Status     `json:"status"`
+	Attempts  int        `json:"attempts"`
+	LastError *string    `json:"last_error,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	SentAt    *time.Time `json:"sent_at,omitempty"`
+}
+
+// ToResponse converts Delivery to DeliveryResponse
+func (d *Delivery) ToResponse() *DeliveryResponse {
+	return &DeliveryResponse{
+		ID:        d.ID,
+		Channel:   d.Channel,
+		Status:    d.Status,
+		Attempts:  d.Attempts,
+		LastError: d.LastError,
+		CreatedAt: d.CreatedAt,
+		SentAt:    d.SentAt,
+	}
+}
@@ -0,0 +1,43 @@
+package delivery
+
+import (
+	"context"
+
+	"github.com/fkhayef/splitwise/internal/mailer"
+	"github.com/fkhayef/splitwise/pkg/i18n"
+)
+
+// EmailChannel sends notifications to the recipient's email address
+type EmailChannel struct {
+	mailer     mailer.Mailer
+	appBaseURL string // Linked from the footer so users can change their preferences
+}
+
+// NewEmailChannel creates an email channel that sends through the given mailer
+func NewEmailChannel(m mailer.Mailer, appBaseURL string) *EmailChannel {
+	return &EmailChannel{mailer: m, appBaseURL: appBaseURL}
+}
+
+// Name implements Channel
+func (c *EmailChannel) Name() string {
+	return ChannelEmail
+}
+
+// Ready implements Channel; every user has an email address
+func (c *EmailChannel) Ready(ctx context.Context, userID int64) (bool, error) {
+	return true, nil
+}
+
+// Send implements Channel
+func (c *EmailChannel) Send(ctx context.Context, d *Delivery) error {
+	if d.Recipient.Email == "" {
+		return ErrNoDestination
+	}
+
+	lang := d.Recipient.Lang()
+	return c.mailer.Send(ctx, &mailer.Message{
+		To:      d.Recipient.Email,
+		Subject: subject(d),
+		Body:    d.Notification.Message + "\n\n" + i18n.T(lang, "delivery.email_footer", c.appBaseURL) + "\n",
+	})
+}
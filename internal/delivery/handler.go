@@ -0,0 +1,227 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/fkhayef/splitwise/pkg/middleware"
+	"github.com/fkhayef/splitwise/pkg/response"
+)
+
+// Handler handles HTTP requests for notification delivery
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new delivery handler with service dependency injected
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Delivery endpoints live under the users and notifications routers,
+// so main mounts these handlers there instead of a router of their own.
+
+// GetPreferences handles GET /users/me/notification-preferences
+func (h *Handler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	prefs, err := h.service.GetPreferences(r.Context(), userID)
+	if err != nil {
+		h.handleError(w, err, "Failed to get notification preferences")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, prefs)
+}
+
+// UpdatePreferences handles PUT /users/me/notification-preferences
+func (h *Handler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	var req UpdatePreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	prefs, err := h.service.UpdatePreferences(r.Context(), userID, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to update notification preferences")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, prefs)
+}
+
+// ListPushSubscriptions handles GET /users/me/push-subscriptions
+func (h *Handler) ListPushSubscriptions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	subs, err := h.service.ListPushSubscriptions(r.Context(), userID)
+	if err != nil {
+		h.handleError(w, err, "Failed to list push subscriptions")
+		return
+	}
+	if subs == nil {
+		subs = []*PushSubscription{}
+	}
+
+	response.JSON(w, http.StatusOK, &PushSubscriptionsResponse{
+		VAPIDPublicKey: h.service.VAPIDPublicKey(),
+		Subscriptions:  subs,
+	})
+}
+
+// AddPushSubscription handles POST /users/me/push-subscriptions
+func (h *Handler) AddPushSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	var req PushSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	sub, err := h.service.AddPushSubscription(r.Context(), userID, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to save push subscription")
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, sub)
+}
+
+// RemovePushSubscription handles DELETE /users/me/push-subscriptions/{id}
+func (h *Handler) RemovePushSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid subscription ID")
+		return
+	}
+
+	if err := h.service.RemovePushSubscription(r.Context(), id, userID); err != nil {
+		h.handleError(w, err, "Failed to remove push subscription")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{"message": "Push subscription removed"})
+}
+
+// GetWebhook handles GET /users/me/webhook
+func (h *Handler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	endpoint, err := h.service.GetWebhook(r.Context(), userID)
+	if err != nil {
+		h.handleError(w, err, "Failed to get webhook")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, endpoint.ToResponse())
+}
+
+// SetWebhook handles PUT /users/me/webhook. The response carries a new
+// signing secret, which is not shown again.
+func (h *Handler) SetWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	endpoint, err := h.service.SetWebhook(r.Context(), userID, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to set webhook")
+		return
+	}
+
+	resp := endpoint.ToResponse()
+	resp.Secret = endpoint.Secret
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// DeleteWebhook handles DELETE /users/me/webhook
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	if err := h.service.DeleteWebhook(r.Context(), userID); err != nil {
+		h.handleError(w, err, "Failed to delete webhook")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{"message": "Webhook deleted"})
+}
+
+// ListDeliveries handles GET /notifications/{id}/deliveries
+func (h *Handler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	notificationID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid notification ID")
+		return
+	}
+
+	deliveries, err := h.service.ListDeliveries(r.Context(), notificationID, userID)
+	if err != nil {
+		h.handleError(w, err, "Failed to list deliveries")
+		return
+	}
+
+	responses := make([]*DeliveryResponse, len(deliveries))
+	for i, d := range deliveries {
+		responses[i] = d.ToResponse()
+	}
+
+	response.JSON(w, http.StatusOK, responses)
+}
+
+// handleError maps delivery errors to HTTP responses
+func (h *Handler) handleError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrUnknownType), errors.Is(err, ErrUnknownChannel),
+		errors.Is(err, ErrInvalidSubscription), errors.Is(err, ErrInvalidWebhookURL):
+		response.BadRequest(w, err.Error())
+	case errors.Is(err, ErrSubscriptionNotFound), errors.Is(err, ErrWebhookNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, ErrPushDisabled):
+		response.Conflict(w, err.Error())
+	default:
+		response.InternalError(w, fallback)
+	}
+}
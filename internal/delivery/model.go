@@ -0,0 +1,102 @@
+package delivery
+
+import (
+	"time"
+
+	"github.com/fkhayef/splitwise/internal/notification"
+	"github.com/fkhayef/splitwise/pkg/i18n"
+)
+
+// Channel names, as stored in preferences and the outbox
+const (
+	ChannelEmail   = "EMAIL"
+	ChannelPush    = "PUSH"
+	ChannelWebhook = "WEBHOOK"
+)
+
+// Status is where a delivery stands in the outbox
+type Status string
+
+const (
+	StatusPending Status = "PENDING" // Waiting for its first or next attempt
+	StatusSent    Status = "SENT"
+	StatusFailed  Status = "FAILED"  // Ran out of attempts
+	StatusSkipped Status = "SKIPPED" // Nowhere to send it by the time it was due, e.g. no push subscriptions left
+)
+
+// Delivery is one notification to send on one channel, with what the channel needs to send it
+type Delivery struct {
+	ID             int64
+	NotificationID int64
+	Channel        string
+	Status         Status
+	Attempts       int // Including the attempt in progress
+	LastError      *string
+	CreatedAt      time.Time
+	SentAt         *time.Time
+
+	Recipient    Recipient
+	Notification *notification.Notification
+}
+
+// Recipient is the user a delivery is for
+type Recipient struct {
+	ID       int64
+	Username string
+	Email    string
+	Language string
+}
+
+// Lang returns the language to write to the recipient in
+func (r Recipient) Lang() string {
+	lang, err := i18n.Normalize(r.Language)
+	if err != nil {
+		return i18n.Default
+	}
+	return lang
+}
+
+// Preferences are the channels a user gets each notification type on
+type Preferences map[notification.NotificationType]map[string]bool
+
+// Enabled reports whether a notification type goes out on a channel,
+// falling back to the channel's default if the user hasn't chosen
+func (p Preferences) Enabled(t notification.NotificationType, channel string) bool {
+	if enabled, ok := p[t][channel]; ok {
+		return enabled
+	}
+	return defaultEnabled(t, channel)
+}
+
+// defaultEnabled is whether a type goes out on a channel for users who haven't chosen.
+// Email is kept to notifications that ask the user to do something; push and
+// webhooks only reach users who set them up, so they get everything.
+func defaultEnabled(t notification.NotificationType, channel string) bool {
+	if channel != ChannelEmail {
+		return true
+	}
+	switch t {
+	case notification.NotificationTypeGroupInvite, notification.NotificationTypeSettlement, notification.NotificationTypeReminder:
+		return true
+	}
+	return false
+}
+
+// PushSubscription is a browser's Web Push subscription
+type PushSubscription struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Endpoint  string    `json:"endpoint"`
+	P256dh    string    `json:"p256dh"` // Base64url public key of the subscription
+	Auth      string    `json:"auth"`   // Base64url auth secret of the subscription
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookEndpoint is where a user's notifications are posted
+type WebhookEndpoint struct {
+	UserID    int64     `json:"user_id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"-"` // Signs each request; only shown when the endpoint is set
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,348 @@
+package delivery
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/fkhayef/splitwise/internal/notification"
+)
+
+// Repository handles delivery data persistence
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository creates a new delivery repository
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// =============================================================================
+// OUTBOX
+// =============================================================================
+
+// Enqueue adds a pending delivery of a notification for each channel
+func (r *Repository) Enqueue(ctx context.Context, notificationID, userID int64, channels []string) error {
+	query := `
+		INSERT INTO notification_deliveries (notification_id, user_id, channel)
+		SELECT $1, $2, unnest($3::text[])
+		ON CONFLICT (notification_id, channel) DO NOTHING
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, notificationID, userID, pq.Array(channels)); err != nil {
+		return fmt.Errorf("failed to enqueue deliveries: %w", err)
+	}
+	return nil
+}
+
+// Claim takes up to limit due deliveries, counting the attempt and pushing their
+// next attempt back by lease so other instances leave them alone. Deliveries whose
+// instance dies mid-send are picked up again once the lease runs out.
+func (r *Repository) Claim(ctx context.Context, limit int, lease time.Duration) ([]*Delivery, error) {
+	query := `
+		UPDATE notification_deliveries d
+		SET attempts = d.attempts + 1, next_attempt_at = NOW() + make_interval(secs => $2)
+		FROM notifications n, users u
+		WHERE d.id IN (
+			SELECT id FROM notification_deliveries
+			WHERE status = 'PENDING' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		  AND n.id = d.notification_id
+		  AND u.id = d.user_id
+		RETURNING d.id, d.notification_id, d.channel, d.status, d.attempts, d.created_at,
+		          u.id, u.username, u.email, u.language,
+		          n.id, n.recipient_id, COALESCE(n.type, ''), n.message, n.is_read,
+		          n.related_entity_type, n.related_entity_id, n.created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*Delivery
+	for rows.Next() {
+		d := &Delivery{Notification: &notification.Notification{}}
+		n := d.Notification
+		if err := rows.Scan(
+			&d.ID, &d.NotificationID, &d.Channel, &d.Status, &d.Attempts, &d.CreatedAt,
+			&d.Recipient.ID, &d.Recipient.Username, &d.Recipient.Email, &d.Recipient.Language,
+			&n.ID, &n.RecipientID, &n.Type, &n.Message, &n.IsRead,
+			&n.RelatedEntityType, &n.RelatedEntityID, &n.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// MarkSent records a successful delivery
+func (r *Repository) MarkSent(ctx context.Context, id int64) error {
+	query := `UPDATE notification_deliveries SET status = 'SENT', sent_at = NOW(), last_error = NULL WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to mark delivery sent: %w", err)
+	}
+	return nil
+}
+
+// MarkRetry records a failed attempt and when to try again
+func (r *Repository) MarkRetry(ctx context.Context, id int64, lastError string, delay time.Duration) error {
+	query := `
+		UPDATE notification_deliveries
+		SET last_error = $2, next_attempt_at = NOW() + make_interval(secs => $3)
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, lastError, delay.Seconds()); err != nil {
+		return fmt.Errorf("failed to schedule delivery retry: %w", err)
+	}
+	return nil
+}
+
+// MarkDone records a delivery that won't be attempted again, as FAILED or SKIPPED
+func (r *Repository) MarkDone(ctx context.Context, id int64, status Status, lastError string) error {
+	query := `UPDATE notification_deliveries SET status = $2, last_error = $3 WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id, status, lastError); err != nil {
+		return fmt.Errorf("failed to update delivery: %w", err)
+	}
+	return nil
+}
+
+// ListByNotification retrieves the deliveries of one of a user's notifications
+func (r *Repository) ListByNotification(ctx context.Context, notificationID, userID int64) ([]*Delivery, error) {
+	query := `
+		SELECT id, notification_id, channel, status, attempts, last_error, created_at, sent_at
+		FROM notification_deliveries
+		WHERE notification_id = $1 AND user_id = $2
+		ORDER BY channel
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, notificationID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*Delivery
+	for rows.Next() {
+		d := &Delivery{}
+		if err := rows.Scan(&d.ID, &d.NotificationID, &d.Channel, &d.Status, &d.Attempts, &d.LastError, &d.CreatedAt, &d.SentAt); err != nil {
+			return nil, fmt.Errorf("failed to scan delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// =============================================================================
+// PREFERENCES
+// =============================================================================
+
+// GetPreferences retrieves the channel choices a user has made
+func (r *Repository) GetPreferences(ctx context.Context, userID int64) (Preferences, error) {
+	query := `SELECT notification_type, channel, enabled FROM notification_preferences WHERE user_id = $1`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	defer rows.Close()
+
+	prefs := Preferences{}
+	for rows.Next() {
+		var t notification.NotificationType
+		var channel string
+		var enabled bool
+		if err := rows.Scan(&t, &channel, &enabled); err != nil {
+			return nil, fmt.Errorf("failed to scan notification preference: %w", err)
+		}
+		if prefs[t] == nil {
+			prefs[t] = map[string]bool{}
+		}
+		prefs[t][channel] = enabled
+	}
+
+	return prefs, rows.Err()
+}
+
+// SavePreference creates or replaces one of a user's channel choices
+func (r *Repository) SavePreference(ctx context.Context, userID int64, t notification.NotificationType, channel string, enabled bool) error {
+	query := `
+		INSERT INTO notification_preferences (user_id, notification_type, channel, enabled)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, notification_type, channel) DO UPDATE
+		SET enabled = EXCLUDED.enabled, updated_at = NOW()
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, t, channel, enabled); err != nil {
+		return fmt.Errorf("failed to save notification preference: %w", err)
+	}
+	return nil
+}
+
+// =============================================================================
+// PUSH SUBSCRIPTIONS
+// =============================================================================
+
+// SavePushSubscription stores a subscription, moving it to the user if the
+// browser subscribed for someone else before
+func (r *Repository) SavePushSubscription(ctx context.Context, sub *PushSubscription) (*PushSubscription, error) {
+	query := `
+		INSERT INTO push_subscriptions (user_id, endpoint, p256dh, auth)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (endpoint) DO UPDATE
+		SET user_id = EXCLUDED.user_id, p256dh = EXCLUDED.p256dh, auth = EXCLUDED.auth
+		RETURNING id, user_id, endpoint, p256dh, auth, created_at
+	`
+
+	saved := &PushSubscription{}
+	err := r.db.QueryRowContext(ctx, query, sub.UserID, sub.Endpoint, sub.P256dh, sub.Auth).Scan(
+		&saved.ID,
+		&saved.UserID,
+		&saved.Endpoint,
+		&saved.P256dh,
+		&saved.Auth,
+		&saved.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save push subscription: %w", err)
+	}
+
+	return saved, nil
+}
+
+// ListPushSubscriptions retrieves a user's push subscriptions
+func (r *Repository) ListPushSubscriptions(ctx context.Context, userID int64) ([]*PushSubscription, error) {
+	query := `
+		SELECT id, user_id, endpoint, p256dh, auth, created_at
+		FROM push_subscriptions
+		WHERE user_id = $1
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list push subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []*PushSubscription
+	for rows.Next() {
+		sub := &PushSubscription{}
+		if err := rows.Scan(&sub.ID, &sub.UserID, &sub.Endpoint, &sub.P256dh, &sub.Auth, &sub.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan push subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+// HasPushSubscriptions reports whether a user has any push subscriptions
+func (r *Repository) HasPushSubscriptions(ctx context.Context, userID int64) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM push_subscriptions WHERE user_id = $1)`, userID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check push subscriptions: %w", err)
+	}
+	return exists, nil
+}
+
+// DeletePushSubscription removes one of a user's push subscriptions,
+// returning false if they have no such subscription
+func (r *Repository) DeletePushSubscription(ctx context.Context, id, userID int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM push_subscriptions WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete push subscription: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete push subscription: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// DeletePushEndpoint removes a subscription the push service says no longer exists
+func (r *Repository) DeletePushEndpoint(ctx context.Context, endpoint string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM push_subscriptions WHERE endpoint = $1`, endpoint); err != nil {
+		return fmt.Errorf("failed to delete push subscription: %w", err)
+	}
+	return nil
+}
+
+// =============================================================================
+// WEBHOOK ENDPOINTS
+// =============================================================================
+
+// GetWebhook retrieves a user's webhook endpoint, or nil if they have none
+func (r *Repository) GetWebhook(ctx context.Context, userID int64) (*WebhookEndpoint, error) {
+	query := `SELECT user_id, url, secret, created_at, updated_at FROM webhook_endpoints WHERE user_id = $1`
+
+	endpoint := &WebhookEndpoint{}
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&endpoint.UserID,
+		&endpoint.URL,
+		&endpoint.Secret,
+		&endpoint.CreatedAt,
+		&endpoint.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+
+	return endpoint, nil
+}
+
+// SaveWebhook creates or replaces a user's webhook endpoint
+func (r *Repository) SaveWebhook(ctx context.Context, userID int64, url, secret string) (*WebhookEndpoint, error) {
+	query := `
+		INSERT INTO webhook_endpoints (user_id, url, secret)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET url = EXCLUDED.url, secret = EXCLUDED.secret, updated_at = NOW()
+		RETURNING user_id, url, secret, created_at, updated_at
+	`
+
+	endpoint := &WebhookEndpoint{}
+	err := r.db.QueryRowContext(ctx, query, userID, url, secret).Scan(
+		&endpoint.UserID,
+		&endpoint.URL,
+		&endpoint.Secret,
+		&endpoint.CreatedAt,
+		&endpoint.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save webhook endpoint: %w", err)
+	}
+
+	return endpoint, nil
+}
+
+// DeleteWebhook removes a user's webhook endpoint, returning false if they had none
+func (r *Repository) DeleteWebhook(ctx context.Context, userID int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_endpoints WHERE user_id = $1`, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+	return affected > 0, nil
+}
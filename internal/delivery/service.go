@@ -0,0 +1,274 @@
+package delivery
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/fkhayef/splitwise/internal/notification"
+)
+
+// Business logic errors
+var (
+	ErrUnknownType          = errors.New("unknown notification type")
+	ErrUnknownChannel       = errors.New("unknown delivery channel")
+	ErrPushDisabled         = errors.New("web push is not configured on this server")
+	ErrInvalidSubscription  = errors.New("push subscription needs an https endpoint and valid p256dh and auth keys")
+	ErrSubscriptionNotFound = errors.New("push subscription not found")
+	ErrInvalidWebhookURL    = errors.New("webhook url must be an absolute https url to a public address")
+	ErrWebhookNotFound      = errors.New("no webhook is set up")
+)
+
+// Retry backoff: the first retry waits retryBase, doubling up to retryMax
+const (
+	retryBase = 30 * time.Second
+	retryMax  = 6 * time.Hour
+)
+
+// Service handles notification delivery business logic
+type Service struct {
+	repo               *Repository
+	channels           map[string]Channel
+	names              []string // Registered channel names, sorted
+	maxAttempts        int
+	allowLocalWebhooks bool // Development only: accept http://localhost and private addresses
+}
+
+// NewService creates a delivery service sending on the given channels
+func NewService(repo *Repository, maxAttempts int, allowLocalWebhooks bool, channels ...Channel) *Service {
+	s := &Service{
+		repo:               repo,
+		channels:           make(map[string]Channel, len(channels)),
+		maxAttempts:        maxAttempts,
+		allowLocalWebhooks: allowLocalWebhooks,
+	}
+	for _, c := range channels {
+		s.channels[c.Name()] = c
+		s.names = append(s.names, c.Name())
+	}
+	sort.Strings(s.names)
+	return s
+}
+
+// Enqueue implements notification.Deliverer. It queues the notification on every
+// channel the recipient has enabled for its type and set up.
+func (s *Service) Enqueue(ctx context.Context, n *notification.Notification) error {
+	if n.Type == "" {
+		return nil
+	}
+
+	prefs, err := s.repo.GetPreferences(ctx, n.RecipientID)
+	if err != nil {
+		return err
+	}
+
+	var channels []string
+	for _, name := range s.names {
+		if !prefs.Enabled(n.Type, name) {
+			continue
+		}
+		ready, err := s.channels[name].Ready(ctx, n.RecipientID)
+		if err != nil {
+			return err
+		}
+		if ready {
+			channels = append(channels, name)
+		}
+	}
+	if len(channels) == 0 {
+		return nil
+	}
+
+	return s.repo.Enqueue(ctx, n.ID, n.RecipientID, channels)
+}
+
+// GetPreferences returns whether each notification type goes out on each channel
+func (s *Service) GetPreferences(ctx context.Context, userID int64) ([]*PreferenceResponse, error) {
+	prefs, err := s.repo.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.preferenceResponses(prefs), nil
+}
+
+// UpdatePreferences changes the given preferences, keeping the rest
+func (s *Service) UpdatePreferences(ctx context.Context, userID int64, req *UpdatePreferencesRequest) ([]*PreferenceResponse, error) {
+	for _, p := range req.Preferences {
+		if !p.Type.Valid() {
+			return nil, ErrUnknownType
+		}
+		if _, ok := s.channels[p.Channel]; !ok {
+			return nil, ErrUnknownChannel
+		}
+	}
+
+	for _, p := range req.Preferences {
+		if err := s.repo.SavePreference(ctx, userID, p.Type, p.Channel, p.Enabled); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetPreferences(ctx, userID)
+}
+
+func (s *Service) preferenceResponses(prefs Preferences) []*PreferenceResponse {
+	responses := make([]*PreferenceResponse, 0, len(notification.NotificationTypes))
+	for _, t := range notification.NotificationTypes {
+		channels := make(map[string]bool, len(s.names))
+		for _, name := range s.names {
+			channels[name] = prefs.Enabled(t, name)
+		}
+		responses = append(responses, &PreferenceResponse{Type: t, Channels: channels})
+	}
+	return responses
+}
+
+// VAPIDPublicKey returns the key browsers subscribe with, or "" if push is off
+func (s *Service) VAPIDPublicKey() string {
+	if push, ok := s.channels[ChannelPush].(*WebPushChannel); ok {
+		return push.PublicKey()
+	}
+	return ""
+}
+
+// ListPushSubscriptions returns the browsers a user gets push notifications on
+func (s *Service) ListPushSubscriptions(ctx context.Context, userID int64) ([]*PushSubscription, error) {
+	return s.repo.ListPushSubscriptions(ctx, userID)
+}
+
+// AddPushSubscription saves a browser's push subscription for a user
+func (s *Service) AddPushSubscription(ctx context.Context, userID int64, req *PushSubscriptionRequest) (*PushSubscription, error) {
+	if _, ok := s.channels[ChannelPush]; !ok {
+		return nil, ErrPushDisabled
+	}
+
+	endpoint, err := url.Parse(req.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return nil, ErrInvalidSubscription
+	}
+	if key, err := decodeBase64URL(req.Keys.P256dh); err != nil || len(key) != 65 {
+		return nil, ErrInvalidSubscription
+	}
+	if auth, err := decodeBase64URL(req.Keys.Auth); err != nil || len(auth) != 16 {
+		return nil, ErrInvalidSubscription
+	}
+
+	return s.repo.SavePushSubscription(ctx, &PushSubscription{
+		UserID:   userID,
+		Endpoint: req.Endpoint,
+		P256dh:   req.Keys.P256dh,
+		Auth:     req.Keys.Auth,
+	})
+}
+
+// RemovePushSubscription removes one of a user's push subscriptions
+func (s *Service) RemovePushSubscription(ctx context.Context, id, userID int64) error {
+	deleted, err := s.repo.DeletePushSubscription(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+// GetWebhook returns the webhook a user has set up
+func (s *Service) GetWebhook(ctx context.Context, userID int64) (*WebhookEndpoint, error) {
+	endpoint, err := s.repo.GetWebhook(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint == nil {
+		return nil, ErrWebhookNotFound
+	}
+	return endpoint, nil
+}
+
+// SetWebhook points a user's webhook at a URL with a new signing secret
+func (s *Service) SetWebhook(ctx context.Context, userID int64, req *WebhookRequest) (*WebhookEndpoint, error) {
+	if !validWebhookURL(req.URL, s.allowLocalWebhooks) {
+		return nil, ErrInvalidWebhookURL
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	return s.repo.SaveWebhook(ctx, userID, req.URL, hex.EncodeToString(secret))
+}
+
+// DeleteWebhook removes a user's webhook
+func (s *Service) DeleteWebhook(ctx context.Context, userID int64) error {
+	deleted, err := s.repo.DeleteWebhook(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// validWebhookURL accepts https URLs to hosts that aren't obviously internal. With
+// allowLocal, for development, plain http to localhost and private addresses are
+// accepted too. Where a host name resolves to is checked when posting.
+func validWebhookURL(raw string, allowLocal bool) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	host := u.Hostname()
+	ip := net.ParseIP(host)
+	local := host == "localhost" || ip != nil && !publicIP(ip)
+	switch u.Scheme {
+	case "https":
+		return allowLocal || !local
+	case "http":
+		return allowLocal && local
+	}
+	return false
+}
+
+// ListDeliveries returns where a user's notification has been delivered
+func (s *Service) ListDeliveries(ctx context.Context, notificationID, userID int64) ([]*Delivery, error) {
+	return s.repo.ListByNotification(ctx, notificationID, userID)
+}
+
+// deliver sends a claimed delivery and records the outcome
+func (s *Service) deliver(ctx context.Context, d *Delivery) (Status, error) {
+	channel, ok := s.channels[d.Channel]
+	if !ok {
+		return StatusSkipped, s.repo.MarkDone(ctx, d.ID, StatusSkipped, "channel is not enabled on this server")
+	}
+
+	err := channel.Send(ctx, d)
+	switch {
+	case err == nil:
+		return StatusSent, s.repo.MarkSent(ctx, d.ID)
+	case errors.Is(err, ErrNoDestination):
+		return StatusSkipped, s.repo.MarkDone(ctx, d.ID, StatusSkipped, err.Error())
+	case d.Attempts >= s.maxAttempts:
+		log.Printf("delivery %d (%s): giving up after %d attempts: %v", d.ID, d.Channel, d.Attempts, err)
+		return StatusFailed, s.repo.MarkDone(ctx, d.ID, StatusFailed, err.Error())
+	default:
+		return StatusPending, s.repo.MarkRetry(ctx, d.ID, err.Error(), backoff(d.Attempts))
+	}
+}
+
+// backoff is how long to wait before retrying after the given number of attempts
+func backoff(attempts int) time.Duration {
+	delay := retryBase
+	for i := 1; i < attempts && delay < retryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, retryMax)
+}
@@ -0,0 +1,176 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/fkhayef/splitwise/internal/notification"
+)
+
+// Webhook request headers
+const (
+	// WebhookSignatureHeader carries the hex HMAC-SHA256 of the body, keyed with the endpoint's secret
+	WebhookSignatureHeader = "X-Splitwise-Signature"
+	// WebhookDeliveryHeader carries the delivery ID, the same on every retry, so receivers can drop repeats
+	WebhookDeliveryHeader = "X-Splitwise-Delivery"
+)
+
+// webhookTimeout bounds how long a receiver has to answer
+const webhookTimeout = 10 * time.Second
+
+// WebhookPayload is the JSON body posted to webhook endpoints
+type WebhookPayload struct {
+	DeliveryID   int64                              `json:"delivery_id"`
+	RecipientID  int64                              `json:"recipient_id"`
+	Notification *notification.NotificationResponse `json:"notification"`
+}
+
+// errBlockedAddress is returned when a webhook URL resolves to an address it may not reach
+var errBlockedAddress = errors.New("webhook address is not public")
+
+// blockedNets are special-purpose ranges not covered by the net.IP checks in publicIP
+var blockedNets = mustParseCIDRs(
+	"0.0.0.0/8",     // "This network"
+	"100.64.0.0/10", // Carrier-grade NAT
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // Benchmarking
+	"240.0.0.0/4",   // Reserved
+	"64:ff9b::/96",  // NAT64, which can reach any IPv4 address
+)
+
+// WebhookChannel posts notifications to the URL each user has set up
+type WebhookChannel struct {
+	repo   *Repository
+	client *http.Client
+}
+
+// NewWebhookChannel creates a new webhook channel. Unless allowLocal is set,
+// which is for development only, webhooks are only posted to public addresses:
+// the address is checked as each connection is made, so a host can't resolve
+// to a public address when the URL is saved and to an internal one later.
+func NewWebhookChannel(repo *Repository, allowLocal bool) *WebhookChannel {
+	dialer := &net.Dialer{Timeout: webhookTimeout}
+	if !allowLocal {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return fmt.Errorf("%w: %s", errBlockedAddress, host)
+			}
+			return nil
+		}
+	}
+
+	client := &http.Client{
+		Timeout: webhookTimeout,
+		// No proxy, so the address checked is the receiver's own
+		Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: webhookTimeout},
+		// A redirect could lead anywhere; it is treated as a failed delivery
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return &WebhookChannel{repo: repo, client: client}
+}
+
+// Name implements Channel
+func (c *WebhookChannel) Name() string {
+	return ChannelWebhook
+}
+
+// Ready implements Channel; users need to have set a webhook URL
+func (c *WebhookChannel) Ready(ctx context.Context, userID int64) (bool, error) {
+	endpoint, err := c.repo.GetWebhook(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return endpoint != nil, nil
+}
+
+// Send implements Channel. Any response other than 2xx is retried.
+func (c *WebhookChannel) Send(ctx context.Context, d *Delivery) error {
+	endpoint, err := c.repo.GetWebhook(ctx, d.Recipient.ID)
+	if err != nil {
+		return err
+	}
+	if endpoint == nil {
+		return ErrNoDestination
+	}
+
+	body, err := json.Marshal(&WebhookPayload{
+		DeliveryID:   d.ID,
+		RecipientID:  d.Recipient.ID,
+		Notification: d.Notification.ToResponse(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Splitwise-Webhooks")
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(WebhookSignatureHeader, signWebhook(endpoint.Secret, body))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+// signWebhook returns the hex HMAC-SHA256 of a webhook body
+func signWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// publicIP reports whether ip is a public unicast address a webhook may be posted to
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, n := range blockedNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets[i] = n
+	}
+	return nets
+}
@@ -0,0 +1,300 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	pushTTL        = 24 * time.Hour // How long push services keep a notification for an offline device
+	pushTimeout    = 10 * time.Second
+	pushRecordSize = 4096           // aes128gcm record size; a notification fits in one record
+	vapidLifetime  = 12 * time.Hour // Push services reject VAPID tokens valid for more than 24 hours
+)
+
+// errSubscriptionGone is returned when the push service no longer knows a subscription
+var errSubscriptionGone = errors.New("push subscription has expired")
+
+// PushPayload is the JSON a service worker receives in its push event
+type PushPayload struct {
+	Title             string  `json:"title"`
+	Body              string  `json:"body"`
+	NotificationID    int64   `json:"notification_id"`
+	Type              string  `json:"type,omitempty"`
+	RelatedEntityType *string `json:"related_entity_type,omitempty"`
+	RelatedEntityID   *int64  `json:"related_entity_id,omitempty"`
+}
+
+// WebPushChannel sends notifications to users' browsers through the Web Push
+// protocol (RFC 8030), with VAPID authentication (RFC 8292) and payloads
+// encrypted for each subscription (RFC 8291)
+type WebPushChannel struct {
+	repo      *Repository
+	key       *ecdsa.PrivateKey
+	publicKey string // Base64url uncompressed point, given to browsers as applicationServerKey
+	subject   string // Contact for push services, a mailto: or https: URL
+	client    *http.Client
+}
+
+// NewWebPushChannel creates a Web Push channel. privateKey is the VAPID
+// P-256 private key as a base64url-encoded 32-byte scalar.
+func NewWebPushChannel(repo *Repository, privateKey, subject string) (*WebPushChannel, error) {
+	raw, err := decodeBase64URL(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	public, err := key.PublicKey.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	return &WebPushChannel{
+		repo:      repo,
+		key:       key,
+		publicKey: base64.RawURLEncoding.EncodeToString(public),
+		subject:   subject,
+		client:    &http.Client{Timeout: pushTimeout},
+	}, nil
+}
+
+// PublicKey returns the VAPID public key browsers subscribe with
+func (c *WebPushChannel) PublicKey() string {
+	return c.publicKey
+}
+
+// Name implements Channel
+func (c *WebPushChannel) Name() string {
+	return ChannelPush
+}
+
+// Ready implements Channel; users need at least one subscribed browser
+func (c *WebPushChannel) Ready(ctx context.Context, userID int64) (bool, error) {
+	return c.repo.HasPushSubscriptions(ctx, userID)
+}
+
+// Send implements Channel. It pushes to every browser the user subscribed and
+// succeeds if any of them got it; expired subscriptions are removed.
+func (c *WebPushChannel) Send(ctx context.Context, d *Delivery) error {
+	subs, err := c.repo.ListPushSubscriptions(ctx, d.Recipient.ID)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(&PushPayload{
+		Title:             subject(d),
+		Body:              d.Notification.Message,
+		NotificationID:    d.Notification.ID,
+		Type:              string(d.Notification.Type),
+		RelatedEntityType: d.Notification.RelatedEntityType,
+		RelatedEntityID:   d.Notification.RelatedEntityID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode push payload: %w", err)
+	}
+
+	sent := 0
+	var lastErr error
+	for _, sub := range subs {
+		err := c.push(ctx, sub, payload)
+		switch {
+		case err == nil:
+			sent++
+		case errors.Is(err, errSubscriptionGone):
+			if err := c.repo.DeletePushEndpoint(ctx, sub.Endpoint); err != nil {
+				lastErr = err
+			}
+		default:
+			lastErr = err
+		}
+	}
+
+	if sent > 0 {
+		return nil
+	}
+	if lastErr != nil {
+		return lastErr
+	}
+	return ErrNoDestination
+}
+
+// push sends an encrypted payload to one subscription
+func (c *WebPushChannel) push(ctx context.Context, sub *PushSubscription, payload []byte) error {
+	body, err := encryptPush(sub, payload)
+	if err != nil {
+		return err
+	}
+	authorization, err := c.vapidAuthorization(sub.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build push request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(pushTTL.Seconds())))
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send push: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusGone:
+		return errSubscriptionGone
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return fmt.Errorf("push service returned %s", resp.Status)
+	}
+	return nil
+}
+
+// vapidAuthorization builds the Authorization header for a push endpoint:
+// an ES256 JWT for the endpoint's origin, signed with the VAPID key (RFC 8292)
+func (c *WebPushChannel) vapidAuthorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid push endpoint: %w", err)
+	}
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`))
+	claims, err := json.Marshal(map[string]any{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(vapidLifetime).Unix(),
+		"sub": c.subject,
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	hash := sha256.Sum256([]byte(unsigned))
+	r, s, err := ecdsa.Sign(rand.Reader, c.key, hash[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+	// JWS wants the raw 32-byte r and s, not ASN.1
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	token := unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)
+	return "vapid t=" + token + ", k=" + c.publicKey, nil
+}
+
+// encryptPush encrypts a payload for a subscription with the aes128gcm content
+// encoding (RFC 8188), keyed as Web Push requires (RFC 8291)
+func encryptPush(sub *PushSubscription, payload []byte) ([]byte, error) {
+	uaPublicRaw, err := decodeBase64URL(sub.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription key: %w", err)
+	}
+	authSecret, err := decodeBase64URL(sub.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription auth secret: %w", err)
+	}
+
+	curve := ecdh.P256()
+	uaPublic, err := curve.NewPublicKey(uaPublicRaw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription key: %w", err)
+	}
+	asPrivate, err := curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+	shared, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	// Combine the shared secret with the subscription's auth secret
+	prkKey, err := hkdf.Extract(sha256.New, shared, authSecret)
+	if err != nil {
+		return nil, err
+	}
+	keyInfo := "WebPush: info\x00" + string(uaPublicRaw) + string(asPublic)
+	ikm, err := hkdf.Expand(sha256.New, prkKey, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	// Derive the content key and nonce from a random salt
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// A single record, ended by the last-record delimiter
+	record := make([]byte, 0, len(payload)+1)
+	record = append(record, payload...)
+	record = append(record, 0x02)
+	ciphertext := gcm.Seal(nil, nonce, record, nil)
+	if len(ciphertext) > pushRecordSize {
+		return nil, fmt.Errorf("push payload is too large (%d bytes)", len(payload))
+	}
+
+	// Header: salt, record size, then the key the browser needs to derive the same secret
+	body := make([]byte, 0, 16+4+1+len(asPublic)+len(ciphertext))
+	body = append(body, salt...)
+	body = binary.BigEndian.AppendUint32(body, pushRecordSize)
+	body = append(body, byte(len(asPublic)))
+	body = append(body, asPublic...)
+	body = append(body, ciphertext...)
+
+	return body, nil
+}
+
+// decodeBase64URL decodes base64url with or without padding, as browsers send keys
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
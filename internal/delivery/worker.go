@@ -0,0 +1,76 @@
+package delivery
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	batchSize = 100             // Deliveries claimed per run
+	lease     = 5 * time.Minute // How long a claimed delivery is left alone before another run retries it
+)
+
+// Worker periodically sends due deliveries from the outbox
+type Worker struct {
+	service  *Service
+	repo     *Repository
+	interval time.Duration
+}
+
+// NewWorker creates a new delivery worker
+func NewWorker(service *Service, repo *Repository, interval time.Duration) *Worker {
+	return &Worker{
+		service:  service,
+		repo:     repo,
+		interval: interval,
+	}
+}
+
+// Start sends due deliveries immediately and then on every interval
+// until the context is cancelled
+func (w *Worker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			w.RunOnce(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce claims due deliveries in batches and sends them until none are left
+func (w *Worker) RunOnce(ctx context.Context) {
+	for ctx.Err() == nil {
+		deliveries, err := w.repo.Claim(ctx, batchSize, lease)
+		if err != nil {
+			log.Printf("delivery worker: %v", err)
+			return
+		}
+
+		counts := make(map[Status]int)
+		for _, d := range deliveries {
+			status, err := w.service.deliver(ctx, d)
+			if err != nil {
+				log.Printf("delivery worker: %v", err)
+				continue
+			}
+			counts[status]++
+		}
+
+		if len(deliveries) > 0 {
+			log.Printf("delivery worker: %d sent, %d to retry, %d failed, %d skipped",
+				counts[StatusSent], counts[StatusPending], counts[StatusFailed], counts[StatusSkipped])
+		}
+		if len(deliveries) < batchSize {
+			return
+		}
+	}
+}
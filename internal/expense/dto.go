@@ -1,5 +1,7 @@
 package expense
 
+import "github.com/fkhayef/splitwise/internal/payment"
+
 // CreateExpenseRequest represents the request to create an expense
 type CreateExpenseRequest struct {
 	GroupID      int64               `json:"group_id" validate:"required"`
@@ -7,6 +9,8 @@ type CreateExpenseRequest struct {
 	Amount       float64             `json:"amount" validate:"required,gt=0"`
 	ImageURL     *string             `json:"image_url,omitempty"`
 	SplitType    string              `json:"split_type" validate:"required,oneof=EVEN PERCENTAGE EXACT"`
+	Category     *string             `json:"category,omitempty" validate:"omitempty,max=50"` // Defaults to "General"
+	Date         *string             `json:"date,omitempty"`                                 // YYYY-MM-DD, defaults to now
 	Participants []*SplitParticipant `json:"participants" validate:"required,min=1"`
 }
 
@@ -14,11 +18,12 @@ type CreateExpenseRequest struct {
 type UpdateExpenseRequest struct {
 	Description *string  `json:"description,omitempty" validate:"omitempty,min=1,max=255"`
 	ImageURL    *string  `json:"image_url,omitempty"`
+	Category    *string  `json:"category,omitempty" validate:"omitempty,min=1,max=50"`
 }
 
-// MarkSplitPaidRequest represents the request to mark a split as paid
+// MarkSplitPaidRequest represents the optional request body to mark a split as paid
 type MarkSplitPaidRequest struct {
-	// No body needed - uses authenticated user
+	payment.Request // How it was paid: method, reference, note and paid_at
 }
 
 // ConfirmSplitRequest represents the request to confirm a split payment
@@ -41,6 +46,7 @@ type ExpenseResponse struct {
 	Amount        float64          `json:"amount"`
 	ImageURL      *string          `json:"image_url,omitempty"`
 	SplitType     string           `json:"split_type"`
+	Category      string           `json:"category"`
 	CreatedAt     string           `json:"created_at"`
 	Splits        []*SplitResponse `json:"splits,omitempty"`
 }
@@ -52,10 +58,12 @@ type SplitResponse struct {
 	BorrowerID       int64       `json:"borrower_id"`
 	BorrowerUsername string      `json:"borrower_username,omitempty"`
 	AmountOwed       float64     `json:"amount_owed"`
+	AmountRemaining  float64     `json:"amount_remaining"` // Less any partial settlements
 	Status           SplitStatus `json:"status"`
 	DisputeReason    *string     `json:"dispute_reason,omitempty"`
 	SettlementID     *int64      `json:"settlement_id,omitempty"`
 	UpdatedAt        string      `json:"updated_at"`
+	payment.DetailsResponse
 }
 
 // ToResponse converts an Expense model to an ExpenseResponse DTO
@@ -69,6 +77,7 @@ func (e *Expense) ToResponse() *ExpenseResponse {
 		Amount:        e.Amount,
 		ImageURL:      e.ImageURL,
 		SplitType:     e.SplitType,
+		Category:      e.Category,
 		CreatedAt:     e.CreatedAt.Format("2006-01-02T15:04:05Z"),
 	}
 }
@@ -81,9 +90,50 @@ func (s *Split) ToResponse() *SplitResponse {
 		BorrowerID:       s.BorrowerID,
 		BorrowerUsername: s.BorrowerUsername,
 		AmountOwed:       s.AmountOwed,
+		AmountRemaining:  s.Remaining(),
 		Status:           s.Status,
 		DisputeReason:    s.DisputeReason,
 		SettlementID:     s.SettlementID,
 		UpdatedAt:        s.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		DetailsResponse:  s.Payment.ToResponse(),
+	}
+}
+
+// GroupSummaryResponse represents spending statistics for a group
+type GroupSummaryResponse struct {
+	GroupID         int64              `json:"group_id"`
+	From            *string            `json:"from,omitempty"` // YYYY-MM-DD, inclusive
+	To              *string            `json:"to,omitempty"`   // YYYY-MM-DD, inclusive
+	ExpenseCount    int                `json:"expense_count"`
+	TotalSpent      float64            `json:"total_spent"`
+	Members         []*MemberSpend     `json:"members"`
+	Categories      []*CategorySpend   `json:"categories"`
+	Months          []*MonthlySpend    `json:"months"`
+	LargestExpenses []*ExpenseResponse `json:"largest_expenses"`
+}
+
+// ToResponse converts a GroupSummary model to a GroupSummaryResponse DTO
+func (g *GroupSummary) ToResponse() *GroupSummaryResponse {
+	resp := &GroupSummaryResponse{
+		GroupID:         g.GroupID,
+		ExpenseCount:    g.ExpenseCount,
+		TotalSpent:      g.TotalSpent,
+		Members:         g.Members,
+		Categories:      g.Categories,
+		Months:          g.Months,
+		LargestExpenses: make([]*ExpenseResponse, len(g.LargestExpenses)),
+	}
+	if g.Range.From != nil {
+		from := g.Range.From.Format("2006-01-02")
+		resp.From = &from
+	}
+	if g.Range.To != nil {
+		// The range end is exclusive; report the last day included
+		to := g.Range.To.AddDate(0, 0, -1).Format("2006-01-02")
+		resp.To = &to
+	}
+	for i, e := range g.LargestExpenses {
+		resp.LargestExpenses[i] = e.ToResponse()
 	}
+	return resp
 }
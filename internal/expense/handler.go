@@ -3,11 +3,17 @@ package expense
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/fkhayef/splitwise/internal/group"
+	"github.com/fkhayef/splitwise/pkg/export"
 	"github.com/fkhayef/splitwise/pkg/middleware"
 	"github.com/fkhayef/splitwise/pkg/response"
 )
@@ -28,6 +34,7 @@ func (h *Handler) Routes() chi.Router {
 
 	r.Post("/", h.Create)
 	r.Get("/{id}", h.GetByID)
+	r.Put("/{id}", h.Update)
 	r.Delete("/{id}", h.Delete)
 
 	r.Get("/group/{groupId}", h.ListByGroup)
@@ -61,6 +68,14 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 
 	result, err := h.service.CreateExpense(r.Context(), payerID, &req)
 	if err != nil {
+		if errors.Is(err, group.ErrNotAuthorized) {
+			response.Forbidden(w, err.Error())
+			return
+		}
+		if errors.Is(err, group.ErrGroupArchived) {
+			response.Conflict(w, err.Error())
+			return
+		}
 		response.BadRequest(w, err.Error())
 		return
 	}
@@ -101,6 +116,164 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 	response.JSON(w, http.StatusOK, expenseResp)
 }
 
+// GroupSummary handles GET /groups/{id}/summary.
+// It is mounted on the groups router by main, since groups can't depend on expenses.
+func (h *Handler) GroupSummary(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid group ID")
+		return
+	}
+
+	dr, err := parseDateRange(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	summary, err := h.service.GetGroupSummary(r.Context(), groupID, dr, limit)
+	if err != nil {
+		if errors.Is(err, group.ErrGroupNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		response.InternalError(w, "Failed to get group summary")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, summary.ToResponse())
+}
+
+// ledgerCSVHeader lists the columns of the ledger CSV export, one row per split
+var ledgerCSVHeader = []string{
+	"expense_id", "expense_date", "description", "category", "amount", "split_type",
+	"payer_id", "payer_username",
+	"split_id", "borrower_id", "borrower_username", "amount_owed", "split_status", "settlement_id",
+}
+
+// ExportGroupLedger handles GET /groups/{id}/export?format=csv|json.
+// CSV has one row per split; JSON is an array of expenses with their splits.
+func (h *Handler) ExportGroupLedger(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid group ID")
+		return
+	}
+
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	out := export.NewWriter(w, format, fmt.Sprintf("group-%d-expenses", groupID), ledgerCSVHeader)
+
+	// JSON groups splits under their expense; rows arrive ordered by expense
+	var current *ExpenseResponse
+	flush := func() error {
+		if current == nil {
+			return nil
+		}
+		err := out.WriteJSON(current)
+		current = nil
+		return err
+	}
+
+	err = h.service.ExportGroupLedger(r.Context(), groupID, func(e *Expense, s *Split) error {
+		if format == export.FormatCSV {
+			return out.WriteCSV(ledgerCSVRow(e, s))
+		}
+
+		if current != nil && current.ID != e.ID {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if current == nil {
+			current = e.ToResponse()
+			current.Splits = []*SplitResponse{}
+		}
+		if s != nil {
+			current.Splits = append(current.Splits, s.ToResponse())
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err == nil {
+		err = out.Close()
+	}
+
+	if err != nil {
+		if out.Started() {
+			// Headers are already sent; the client sees a truncated file
+			log.Printf("expense: ledger export for group %d failed: %v", groupID, err)
+			return
+		}
+		if errors.Is(err, group.ErrGroupNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		response.InternalError(w, "Failed to export expenses")
+	}
+}
+
+// ledgerCSVRow formats an expense and one of its splits as a CSV row
+func ledgerCSVRow(e *Expense, s *Split) []string {
+	row := []string{
+		strconv.FormatInt(e.ID, 10),
+		e.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		e.Description,
+		e.Category,
+		strconv.FormatFloat(e.Amount, 'f', 2, 64),
+		e.SplitType,
+		strconv.FormatInt(e.PayerID, 10),
+		e.PayerUsername,
+		"", "", "", "", "", "",
+	}
+	if s != nil {
+		row[8] = strconv.FormatInt(s.ID, 10)
+		row[9] = strconv.FormatInt(s.BorrowerID, 10)
+		row[10] = s.BorrowerUsername
+		row[11] = strconv.FormatFloat(s.AmountOwed, 'f', 2, 64)
+		row[12] = string(s.Status)
+		if s.SettlementID != nil {
+			row[13] = strconv.FormatInt(*s.SettlementID, 10)
+		}
+	}
+	return row
+}
+
+// parseDateRange reads the optional inclusive from/to (YYYY-MM-DD) query parameters
+func parseDateRange(r *http.Request) (DateRange, error) {
+	var dr DateRange
+
+	if from := r.URL.Query().Get("from"); from != "" {
+		t, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return dr, errors.New("from must be a YYYY-MM-DD date")
+		}
+		dr.From = &t
+	}
+	if to := r.URL.Query().Get("to"); to != "" {
+		t, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return dr, errors.New("to must be a YYYY-MM-DD date")
+		}
+		// Include the whole "to" day
+		end := t.AddDate(0, 0, 1)
+		dr.To = &end
+	}
+	if dr.From != nil && dr.To != nil && !dr.From.Before(*dr.To) {
+		return dr, errors.New("from must not be after to")
+	}
+
+	return dr, nil
+}
+
 // ListByGroup handles GET /expenses/group/{groupId}
 func (h *Handler) ListByGroup(w http.ResponseWriter, r *http.Request) {
 	groupID, err := strconv.ParseInt(chi.URLParam(r, "groupId"), 10, 64)
@@ -141,6 +314,52 @@ func (h *Handler) ListByGroup(w http.ResponseWriter, r *http.Request) {
 	response.JSONWithMeta(w, http.StatusOK, expenseResponses, meta)
 }
 
+// Update handles PUT /expenses/{id}
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid expense ID")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	var req UpdateExpenseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	result, err := h.service.UpdateExpense(r.Context(), id, userID, &req)
+	if err != nil {
+		if errors.Is(err, ErrExpenseNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrCannotManageExpense) {
+			response.Forbidden(w, err.Error())
+			return
+		}
+		if errors.Is(err, group.ErrGroupArchived) {
+			response.Conflict(w, err.Error())
+			return
+		}
+		response.InternalError(w, "Failed to update expense")
+		return
+	}
+
+	expenseResp := result.Expense.ToResponse()
+	expenseResp.Splits = make([]*SplitResponse, len(result.Splits))
+	for i, s := range result.Splits {
+		expenseResp.Splits[i] = s.ToResponse()
+	}
+
+	response.JSON(w, http.StatusOK, expenseResp)
+}
+
 // Delete handles DELETE /expenses/{id}
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
@@ -159,7 +378,7 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 			response.NotFound(w, err.Error())
 			return
 		}
-		if errors.Is(err, ErrNotPayer) {
+		if errors.Is(err, ErrCannotManageExpense) {
 			response.Forbidden(w, err.Error())
 			return
 		}
@@ -167,6 +386,10 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 			response.Conflict(w, err.Error())
 			return
 		}
+		if errors.Is(err, group.ErrGroupArchived) {
+			response.Conflict(w, err.Error())
+			return
+		}
 		response.InternalError(w, "Failed to delete expense")
 		return
 	}
@@ -187,7 +410,19 @@ func (h *Handler) MarkSplitAsPaid(w http.ResponseWriter, r *http.Request) {
 		userID = 1
 	}
 
-	split, err := h.service.MarkSplitAsPaid(r.Context(), splitID, userID)
+	// The body is optional; without one only the paid-at time is recorded
+	var req MarkSplitPaidRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+	details, err := req.Details(time.Now())
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	split, err := h.service.MarkSplitAsPaid(r.Context(), splitID, userID, details)
 	if err != nil {
 		if errors.Is(err, ErrSplitNotFound) {
 			response.NotFound(w, err.Error())
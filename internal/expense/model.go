@@ -1,9 +1,11 @@
 package expense
 
 import (
+	"math"
 	"time"
 
 	"github.com/fkhayef/splitwise/internal/expense/split"
+	"github.com/fkhayef/splitwise/internal/payment"
 )
 
 // SplitStatus represents the status of a split
@@ -25,6 +27,7 @@ type Expense struct {
 	Amount      float64   `json:"amount"`
 	ImageURL    *string   `json:"image_url,omitempty"`
 	SplitType   string    `json:"split_type"` // EVEN, PERCENTAGE, EXACT
+	Category    string    `json:"category"`
 	CreatedAt   time.Time `json:"created_at"`
 
 	// Populated via JOIN
@@ -33,19 +36,41 @@ type Expense struct {
 
 // Split represents an individual debt from an expense
 type Split struct {
-	ID            int64       `json:"id"`
-	ExpenseID     int64       `json:"expense_id"`
-	BorrowerID    int64       `json:"borrower_id"`
-	AmountOwed    float64     `json:"amount_owed"`
-	Status        SplitStatus `json:"status"`
-	DisputeReason *string     `json:"dispute_reason,omitempty"`
-	SettlementID  *int64      `json:"settlement_id,omitempty"` // Optional: locked to settlement
-	UpdatedAt     time.Time   `json:"updated_at"`
+	ID            int64           `json:"id"`
+	ExpenseID     int64           `json:"expense_id"`
+	BorrowerID    int64           `json:"borrower_id"`
+	AmountOwed    float64         `json:"amount_owed"`
+	AmountSettled float64         `json:"amount_settled"` // Covered by partial settlements
+	Status        SplitStatus     `json:"status"`
+	DisputeReason *string         `json:"dispute_reason,omitempty"`
+	SettlementID  *int64          `json:"settlement_id,omitempty"` // Optional: locked to settlement
+	UpdatedAt     time.Time       `json:"updated_at"`
+	Payment       payment.Details `json:"payment"` // Set when the borrower marks it as paid
 
 	// Populated via JOIN
 	BorrowerUsername string `json:"borrower_username,omitempty"`
 }
 
+// Remaining is what is still owed on the split after partial settlements
+func (s *Split) Remaining() float64 {
+	return math.Round((s.AmountOwed-s.AmountSettled)*100) / 100
+}
+
+// SplitWithExpense is a split along with the expense details needed to show it on its own
+type SplitWithExpense struct {
+	Split
+	ExpenseDescription string `json:"expense_description"`
+	GroupID            int64  `json:"group_id"`
+}
+
+// GroupBalance is a user's open balance within one group
+type GroupBalance struct {
+	GroupID    int64   `json:"group_id"`
+	GroupName  string  `json:"group_name"`
+	OwedToUser float64 `json:"owed_to_user"`
+	UserOwes   float64 `json:"user_owes"`
+}
+
 // ExpenseWithSplits combines an expense with its calculated splits
 type ExpenseWithSplits struct {
 	Expense *Expense
@@ -67,3 +92,69 @@ func (p *SplitParticipant) ToSplitInput() split.SplitInput {
 		Amount:     p.Amount,
 	}
 }
+
+// DateRange limits a report to expenses created in [From, To).
+// A nil bound is open-ended.
+type DateRange struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// GroupSummary holds spending statistics for a group over a date range
+type GroupSummary struct {
+	GroupID         int64
+	Range           DateRange
+	ExpenseCount    int
+	TotalSpent      float64
+	Members         []*MemberSpend
+	Categories      []*CategorySpend
+	Months          []*MonthlySpend
+	LargestExpenses []*Expense
+}
+
+// MemberSpend is one member's share of a group's spending
+type MemberSpend struct {
+	UserID     int64   `json:"user_id"`
+	Username   string  `json:"username"`
+	Paid       float64 `json:"paid"`        // Total of expenses they paid for
+	Consumed   float64 `json:"consumed"`    // Their share of expenses, whoever paid
+	NetBalance float64 `json:"net_balance"` // Current unsettled balance; positive = they owe others
+}
+
+// StatementLine is an expense on a member's statement: one they paid for or have a split in
+type StatementLine struct {
+	Expense
+	Share       float64      // The member's own share of the expense
+	Lent        float64      // What others owe on it, if the member paid
+	SplitStatus *SplitStatus // The member's split, if they didn't pay
+	Outstanding float64      // Still unsettled; positive = the member owes, negative = they are owed
+}
+
+// MemberPayment is a split paid from one member to another
+type MemberPayment struct {
+	SplitID            int64
+	ExpenseID          int64
+	ExpenseDescription string
+	FromUserID         int64
+	FromUsername       string
+	ToUserID           int64
+	ToUsername         string
+	Amount             float64
+	Status             SplitStatus
+	SettlementID       *int64
+	PaidAt             time.Time
+}
+
+// CategorySpend is the total spent in one expense category
+type CategorySpend struct {
+	Category string  `json:"category"`
+	Count    int     `json:"count"`
+	Total    float64 `json:"total"`
+}
+
+// MonthlySpend is the total spent in one calendar month
+type MonthlySpend struct {
+	Month string  `json:"month"` // YYYY-MM
+	Count int     `json:"count"`
+	Total float64 `json:"total"`
+}
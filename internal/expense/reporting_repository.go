@@ -0,0 +1,325 @@
+package expense
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// ReportingRepository runs read-only aggregate queries over expenses and splits.
+// It is kept apart from Repository so reporting SQL doesn't mix with the
+// transactional queries used to create and settle expenses.
+type ReportingRepository struct {
+	db *sql.DB
+}
+
+// NewReportingRepository creates a new reporting repository
+func NewReportingRepository(db *sql.DB) *ReportingRepository {
+	return &ReportingRepository{db: db}
+}
+
+// dateRangeFilter restricts expenses to [$2, $3); either bound may be NULL
+const dateRangeFilter = `
+	AND ($2::timestamp IS NULL OR e.created_at >= $2)
+	AND ($3::timestamp IS NULL OR e.created_at < $3)
+`
+
+// GetGroupTotals returns the number of expenses and the total spent in a group
+func (r *ReportingRepository) GetGroupTotals(ctx context.Context, groupID int64, dr DateRange) (int, float64, error) {
+	query := `
+		SELECT COUNT(*), COALESCE(SUM(e.amount), 0)
+		FROM expenses e
+		WHERE e.group_id = $1
+	` + dateRangeFilter
+
+	var count int
+	var total float64
+	if err := r.db.QueryRowContext(ctx, query, groupID, dr.From, dr.To).Scan(&count, &total); err != nil {
+		return 0, 0, fmt.Errorf("failed to get group totals: %w", err)
+	}
+
+	return count, total, nil
+}
+
+// GetMemberSpend returns what each member paid and consumed in the date range,
+// along with their current net balance in the group (not limited to the range)
+func (r *ReportingRepository) GetMemberSpend(ctx context.Context, groupID int64, dr DateRange) ([]*MemberSpend, error) {
+	// A payer has no split for their own share, so it is the part of the
+	// expense not owed by anyone else
+	query := `
+		SELECT gm.user_id, u.username,
+		       COALESCE((
+		           SELECT SUM(e.amount)
+		           FROM expenses e
+		           WHERE e.group_id = $1 AND e.payer_id = gm.user_id
+		           ` + dateRangeFilter + `
+		       ), 0) AS paid,
+		       COALESCE((
+		           SELECT SUM(s.amount_owed)
+		           FROM splits s
+		           JOIN expenses e ON s.expense_id = e.id
+		           WHERE e.group_id = $1 AND s.borrower_id = gm.user_id AND s.borrower_id != e.payer_id
+		           ` + dateRangeFilter + `
+		       ), 0) + COALESCE((
+		           SELECT SUM(e.amount - COALESCE((
+		               SELECT SUM(s.amount_owed)
+		               FROM splits s
+		               WHERE s.expense_id = e.id AND s.borrower_id != e.payer_id
+		           ), 0))
+		           FROM expenses e
+		           WHERE e.group_id = $1 AND e.payer_id = gm.user_id
+		           ` + dateRangeFilter + `
+		       ), 0) AS consumed,
+		       COALESCE((
+		           SELECT SUM(CASE WHEN s.borrower_id = gm.user_id THEN s.amount_owed - s.amount_settled ELSE s.amount_settled - s.amount_owed END)
+		           FROM splits s
+		           JOIN expenses e ON s.expense_id = e.id
+		           WHERE e.group_id = $1
+		             AND (s.borrower_id = gm.user_id OR e.payer_id = gm.user_id)
+		             AND s.borrower_id != e.payer_id
+		             AND s.status IN ('PENDING', 'PAID', 'DISPUTED')
+		       ), 0) AS net_balance
+		FROM group_members gm
+		JOIN users u ON gm.user_id = u.id
+		WHERE gm.group_id = $1 AND gm.status != 'INVITED'
+		ORDER BY paid DESC, u.username
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, groupID, dr.From, dr.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member spend: %w", err)
+	}
+	defer rows.Close()
+
+	var members []*MemberSpend
+	for rows.Next() {
+		m := &MemberSpend{}
+		if err := rows.Scan(&m.UserID, &m.Username, &m.Paid, &m.Consumed, &m.NetBalance); err != nil {
+			return nil, fmt.Errorf("failed to scan member spend: %w", err)
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
+
+// GetCategorySpend returns the total spent per category, largest first
+func (r *ReportingRepository) GetCategorySpend(ctx context.Context, groupID int64, dr DateRange) ([]*CategorySpend, error) {
+	query := `
+		SELECT e.category, COUNT(*), SUM(e.amount) AS total
+		FROM expenses e
+		WHERE e.group_id = $1
+	` + dateRangeFilter + `
+		GROUP BY e.category
+		ORDER BY total DESC, e.category
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, groupID, dr.From, dr.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category spend: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []*CategorySpend
+	for rows.Next() {
+		c := &CategorySpend{}
+		if err := rows.Scan(&c.Category, &c.Count, &c.Total); err != nil {
+			return nil, fmt.Errorf("failed to scan category spend: %w", err)
+		}
+		categories = append(categories, c)
+	}
+
+	return categories, rows.Err()
+}
+
+// GetMonthlySpend returns the total spent per calendar month, oldest first
+func (r *ReportingRepository) GetMonthlySpend(ctx context.Context, groupID int64, dr DateRange) ([]*MonthlySpend, error) {
+	query := `
+		SELECT TO_CHAR(DATE_TRUNC('month', e.created_at), 'YYYY-MM') AS month, COUNT(*), SUM(e.amount)
+		FROM expenses e
+		WHERE e.group_id = $1
+	` + dateRangeFilter + `
+		GROUP BY month
+		ORDER BY month
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, groupID, dr.From, dr.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monthly spend: %w", err)
+	}
+	defer rows.Close()
+
+	var months []*MonthlySpend
+	for rows.Next() {
+		m := &MonthlySpend{}
+		if err := rows.Scan(&m.Month, &m.Count, &m.Total); err != nil {
+			return nil, fmt.Errorf("failed to scan monthly spend: %w", err)
+		}
+		months = append(months, m)
+	}
+
+	return months, rows.Err()
+}
+
+// GetLargestExpenses returns the most expensive expenses in the date range
+func (r *ReportingRepository) GetLargestExpenses(ctx context.Context, groupID int64, dr DateRange, limit int) ([]*Expense, error) {
+	query := `
+		SELECT e.id, e.group_id, e.payer_id, e.description, e.amount, e.image_url, e.split_type, e.category, e.created_at, u.username
+		FROM expenses e
+		JOIN users u ON e.payer_id = u.id
+		WHERE e.group_id = $1
+	` + dateRangeFilter + `
+		ORDER BY e.amount DESC, e.created_at DESC
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, groupID, dr.From, dr.To, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get largest expenses: %w", err)
+	}
+	defer rows.Close()
+
+	var expenses []*Expense
+	for rows.Next() {
+		expense := &Expense{}
+		if err := rows.Scan(
+			&expense.ID,
+			&expense.GroupID,
+			&expense.PayerID,
+			&expense.Description,
+			&expense.Amount,
+			&expense.ImageURL,
+			&expense.SplitType,
+			&expense.Category,
+			&expense.CreatedAt,
+			&expense.PayerUsername,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan expense: %w", err)
+		}
+		expenses = append(expenses, expense)
+	}
+
+	return expenses, rows.Err()
+}
+
+// GetMemberStatementLines returns every expense in a group the user paid for or
+// has a split in, oldest first, with their share and what is still unsettled
+func (r *ReportingRepository) GetMemberStatementLines(ctx context.Context, groupID, userID int64) ([]*StatementLine, error) {
+	query := `
+		SELECT e.id, e.group_id, e.payer_id, e.description, e.amount, e.image_url, e.split_type, e.category, e.created_at, u.username,
+		       CASE WHEN e.payer_id = $2 THEN e.amount - COALESCE(o.total, 0) ELSE ms.amount_owed END AS share,
+		       CASE WHEN e.payer_id = $2 THEN COALESCE(o.total, 0) ELSE 0 END AS lent,
+		       ms.status,
+		       CASE
+		           WHEN e.payer_id = $2 THEN -COALESCE(o.open, 0)
+		           WHEN ms.status IN ('PENDING', 'PAID', 'DISPUTED') THEN ms.amount_owed - ms.amount_settled
+		           ELSE 0
+		       END AS outstanding
+		FROM expenses e
+		JOIN users u ON e.payer_id = u.id
+		LEFT JOIN splits ms ON ms.expense_id = e.id AND ms.borrower_id = $2 AND ms.borrower_id != e.payer_id
+		LEFT JOIN LATERAL (
+		    SELECT SUM(s.amount_owed) AS total,
+		           SUM(s.amount_owed - s.amount_settled) FILTER (WHERE s.status IN ('PENDING', 'PAID', 'DISPUTED')) AS open
+		    FROM splits s
+		    WHERE s.expense_id = e.id AND s.borrower_id != e.payer_id
+		) o ON TRUE
+		WHERE e.group_id = $1 AND (e.payer_id = $2 OR ms.id IS NOT NULL)
+		ORDER BY e.created_at, e.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, groupID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statement lines: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []*StatementLine
+	for rows.Next() {
+		l := &StatementLine{}
+		if err := rows.Scan(
+			&l.ID,
+			&l.GroupID,
+			&l.PayerID,
+			&l.Description,
+			&l.Amount,
+			&l.ImageURL,
+			&l.SplitType,
+			&l.Category,
+			&l.CreatedAt,
+			&l.PayerUsername,
+			&l.Share,
+			&l.Lent,
+			&l.SplitStatus,
+			&l.Outstanding,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan statement line: %w", err)
+		}
+		lines = append(lines, l)
+	}
+
+	return lines, rows.Err()
+}
+
+// GetMemberPayments returns the splits in a group the user has paid or been
+// paid for (PAID or CONFIRMED), including parts of splits covered by partial
+// settlements that were paid, in the order they were paid
+func (r *ReportingRepository) GetMemberPayments(ctx context.Context, groupID, userID int64) ([]*MemberPayment, error) {
+	query := `
+		SELECT * FROM (
+			SELECT s.id, e.id AS expense_id, e.description, s.borrower_id, bu.username AS borrower_username,
+			       e.payer_id, pu.username AS payer_username,
+			       s.amount_owed - s.amount_settled AS amount, s.status::text AS status, s.settlement_id, s.updated_at AS paid_at
+			FROM splits s
+			JOIN expenses e ON s.expense_id = e.id
+			JOIN users bu ON s.borrower_id = bu.id
+			JOIN users pu ON e.payer_id = pu.id
+			WHERE e.group_id = $1
+			  AND $2 IN (s.borrower_id, e.payer_id)
+			  AND s.borrower_id != e.payer_id
+			  AND s.status IN ('PAID', 'CONFIRMED')
+			UNION ALL
+			SELECT s.id, e.id, e.description, s.borrower_id, bu.username, e.payer_id, pu.username,
+			       a.amount, st.status::text, st.id, a.created_at
+			FROM settlement_allocations a
+			JOIN settlements st ON a.settlement_id = st.id
+			JOIN splits s ON a.split_id = s.id
+			JOIN expenses e ON s.expense_id = e.id
+			JOIN users bu ON s.borrower_id = bu.id
+			JOIN users pu ON e.payer_id = pu.id
+			WHERE e.group_id = $1
+			  AND $2 IN (s.borrower_id, e.payer_id)
+			  AND st.status IN ('PAID', 'CONFIRMED')
+		) p
+		ORDER BY paid_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, groupID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member payments: %w", err)
+	}
+	defer rows.Close()
+
+	var payments []*MemberPayment
+	for rows.Next() {
+		p := &MemberPayment{}
+		if err := rows.Scan(
+			&p.SplitID,
+			&p.ExpenseID,
+			&p.ExpenseDescription,
+			&p.FromUserID,
+			&p.FromUsername,
+			&p.ToUserID,
+			&p.ToUsername,
+			&p.Amount,
+			&p.Status,
+			&p.SettlementID,
+			&p.PaidAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan member payment: %w", err)
+		}
+		payments = append(payments, p)
+	}
+
+	return payments, rows.Err()
+}
@@ -4,24 +4,36 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/fkhayef/splitwise/internal/database"
+	"github.com/fkhayef/splitwise/internal/payment"
 )
 
 // Repository handles expense and split data persistence
 type Repository struct {
-	db *sql.DB
+	db   database.DBTX
+	conn *sql.DB
 }
 
 // NewRepository creates a new expense repository
 func NewRepository(db *sql.DB) *Repository {
-	return &Repository{db: db}
+	return &Repository{db: db, conn: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx
+func (r *Repository) WithTx(tx *sql.Tx) *Repository {
+	return &Repository{db: tx, conn: r.conn}
 }
 
 // CreateExpense inserts a new expense into the database
 func (r *Repository) CreateExpense(ctx context.Context, payerID int64, req *CreateExpenseRequest) (*Expense, error) {
 	query := `
-		INSERT INTO expenses (group_id, payer_id, description, amount, image_url, split_type)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, group_id, payer_id, description, amount, image_url, split_type, created_at
+		INSERT INTO expenses (group_id, payer_id, description, amount, image_url, split_type, category, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7, 'General'), COALESCE($8::timestamp, NOW()))
+		RETURNING id, group_id, payer_id, description, amount, image_url, split_type, category, created_at
 	`
 
 	expense := &Expense{}
@@ -32,6 +44,8 @@ func (r *Repository) CreateExpense(ctx context.Context, payerID int64, req *Crea
 		req.Amount,
 		req.ImageURL,
 		req.SplitType,
+		req.Category,
+		req.Date,
 	).Scan(
 		&expense.ID,
 		&expense.GroupID,
@@ -40,6 +54,7 @@ func (r *Repository) CreateExpense(ctx context.Context, payerID int64, req *Crea
 		&expense.Amount,
 		&expense.ImageURL,
 		&expense.SplitType,
+		&expense.Category,
 		&expense.CreatedAt,
 	)
 	if err != nil {
@@ -54,7 +69,8 @@ func (r *Repository) CreateSplit(ctx context.Context, expenseID, borrowerID int6
 	query := `
 		INSERT INTO splits (expense_id, borrower_id, amount_owed, status)
 		VALUES ($1, $2, $3, $4)
-		RETURNING id, expense_id, borrower_id, amount_owed, status, dispute_reason, settlement_id, updated_at
+		RETURNING id, expense_id, borrower_id, amount_owed, amount_settled, status, dispute_reason, settlement_id, updated_at,
+		          payment_method, payment_reference, payment_note, paid_at
 	`
 
 	split := &Split{}
@@ -63,10 +79,15 @@ func (r *Repository) CreateSplit(ctx context.Context, expenseID, borrowerID int6
 		&split.ExpenseID,
 		&split.BorrowerID,
 		&split.AmountOwed,
+		&split.AmountSettled,
 		&split.Status,
 		&split.DisputeReason,
 		&split.SettlementID,
 		&split.UpdatedAt,
+		&split.Payment.Method,
+		&split.Payment.Reference,
+		&split.Payment.Note,
+		&split.Payment.PaidAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create split: %w", err)
@@ -78,7 +99,7 @@ func (r *Repository) CreateSplit(ctx context.Context, expenseID, borrowerID int6
 // GetExpenseByID retrieves an expense by its ID
 func (r *Repository) GetExpenseByID(ctx context.Context, id int64) (*Expense, error) {
 	query := `
-		SELECT e.id, e.group_id, e.payer_id, e.description, e.amount, e.image_url, e.split_type, e.created_at, u.username
+		SELECT e.id, e.group_id, e.payer_id, e.description, e.amount, e.image_url, e.split_type, e.category, e.created_at, u.username
 		FROM expenses e
 		JOIN users u ON e.payer_id = u.id
 		WHERE e.id = $1
@@ -93,6 +114,7 @@ func (r *Repository) GetExpenseByID(ctx context.Context, id int64) (*Expense, er
 		&expense.Amount,
 		&expense.ImageURL,
 		&expense.SplitType,
+		&expense.Category,
 		&expense.CreatedAt,
 		&expense.PayerUsername,
 	)
@@ -109,7 +131,8 @@ func (r *Repository) GetExpenseByID(ctx context.Context, id int64) (*Expense, er
 // GetSplitsByExpenseID retrieves all splits for an expense
 func (r *Repository) GetSplitsByExpenseID(ctx context.Context, expenseID int64) ([]*Split, error) {
 	query := `
-		SELECT s.id, s.expense_id, s.borrower_id, s.amount_owed, s.status, s.dispute_reason, s.settlement_id, s.updated_at, u.username
+		SELECT s.id, s.expense_id, s.borrower_id, s.amount_owed, s.amount_settled, s.status, s.dispute_reason, s.settlement_id, s.updated_at, u.username,
+		       s.payment_method, s.payment_reference, s.payment_note, s.paid_at
 		FROM splits s
 		JOIN users u ON s.borrower_id = u.id
 		WHERE s.expense_id = $1
@@ -130,11 +153,16 @@ func (r *Repository) GetSplitsByExpenseID(ctx context.Context, expenseID int64)
 			&split.ExpenseID,
 			&split.BorrowerID,
 			&split.AmountOwed,
+			&split.AmountSettled,
 			&split.Status,
 			&split.DisputeReason,
 			&split.SettlementID,
 			&split.UpdatedAt,
 			&split.BorrowerUsername,
+			&split.Payment.Method,
+			&split.Payment.Reference,
+			&split.Payment.Note,
+			&split.Payment.PaidAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan split: %w", err)
 		}
@@ -155,7 +183,7 @@ func (r *Repository) ListExpensesByGroupID(ctx context.Context, groupID int64, l
 
 	// Get expenses
 	query := `
-		SELECT e.id, e.group_id, e.payer_id, e.description, e.amount, e.image_url, e.split_type, e.created_at, u.username
+		SELECT e.id, e.group_id, e.payer_id, e.description, e.amount, e.image_url, e.split_type, e.category, e.created_at, u.username
 		FROM expenses e
 		JOIN users u ON e.payer_id = u.id
 		WHERE e.group_id = $1
@@ -180,6 +208,7 @@ func (r *Repository) ListExpensesByGroupID(ctx context.Context, groupID int64, l
 			&expense.Amount,
 			&expense.ImageURL,
 			&expense.SplitType,
+			&expense.Category,
 			&expense.CreatedAt,
 			&expense.PayerUsername,
 		); err != nil {
@@ -191,10 +220,53 @@ func (r *Repository) ListExpensesByGroupID(ctx context.Context, groupID int64, l
 	return expenses, total, nil
 }
 
+// ListPayerExpensesByAmount retrieves expenses a user paid, across all their
+// groups, for the given amount (to the cent) between from and to inclusive
+func (r *Repository) ListPayerExpensesByAmount(ctx context.Context, payerID int64, amount float64, from, to time.Time) ([]*Expense, error) {
+	query := `
+		SELECT e.id, e.group_id, e.payer_id, e.description, e.amount, e.image_url, e.split_type, e.category, e.created_at, u.username
+		FROM expenses e
+		JOIN users u ON e.payer_id = u.id
+		WHERE e.payer_id = $1
+		  AND ABS(e.amount - $2) < 0.005
+		  AND e.created_at >= $3 AND e.created_at < $4::timestamp + INTERVAL '1 day'
+		ORDER BY e.created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, payerID, amount, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expenses by amount: %w", err)
+	}
+	defer rows.Close()
+
+	var expenses []*Expense
+	for rows.Next() {
+		expense := &Expense{}
+		if err := rows.Scan(
+			&expense.ID,
+			&expense.GroupID,
+			&expense.PayerID,
+			&expense.Description,
+			&expense.Amount,
+			&expense.ImageURL,
+			&expense.SplitType,
+			&expense.Category,
+			&expense.CreatedAt,
+			&expense.PayerUsername,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan expense: %w", err)
+		}
+		expenses = append(expenses, expense)
+	}
+
+	return expenses, rows.Err()
+}
+
 // GetSplitByID retrieves a split by its ID
 func (r *Repository) GetSplitByID(ctx context.Context, id int64) (*Split, error) {
 	query := `
-		SELECT s.id, s.expense_id, s.borrower_id, s.amount_owed, s.status, s.dispute_reason, s.settlement_id, s.updated_at, u.username
+		SELECT s.id, s.expense_id, s.borrower_id, s.amount_owed, s.amount_settled, s.status, s.dispute_reason, s.settlement_id, s.updated_at, u.username,
+		       s.payment_method, s.payment_reference, s.payment_note, s.paid_at
 		FROM splits s
 		JOIN users u ON s.borrower_id = u.id
 		WHERE s.id = $1
@@ -206,11 +278,16 @@ func (r *Repository) GetSplitByID(ctx context.Context, id int64) (*Split, error)
 		&split.ExpenseID,
 		&split.BorrowerID,
 		&split.AmountOwed,
+		&split.AmountSettled,
 		&split.Status,
 		&split.DisputeReason,
 		&split.SettlementID,
 		&split.UpdatedAt,
 		&split.BorrowerUsername,
+		&split.Payment.Method,
+		&split.Payment.Reference,
+		&split.Payment.Note,
+		&split.Payment.PaidAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -222,13 +299,50 @@ func (r *Repository) GetSplitByID(ctx context.Context, id int64) (*Split, error)
 	return split, nil
 }
 
+// MarkSplitPaid sets a split to PAID and records how it was paid
+func (r *Repository) MarkSplitPaid(ctx context.Context, id int64, details *payment.Details) (*Split, error) {
+	query := `
+		UPDATE splits
+		SET status = $2, payment_method = $3, payment_reference = $4, payment_note = $5, paid_at = $6, updated_at = NOW()
+		WHERE id = $1
+		RETURNING id, expense_id, borrower_id, amount_owed, amount_settled, status, dispute_reason, settlement_id, updated_at,
+		          payment_method, payment_reference, payment_note, paid_at
+	`
+
+	split := &Split{}
+	err := r.db.QueryRowContext(ctx, query, id, SplitStatusPaid, details.Method, details.Reference, details.Note, details.PaidAt).Scan(
+		&split.ID,
+		&split.ExpenseID,
+		&split.BorrowerID,
+		&split.AmountOwed,
+		&split.AmountSettled,
+		&split.Status,
+		&split.DisputeReason,
+		&split.SettlementID,
+		&split.UpdatedAt,
+		&split.Payment.Method,
+		&split.Payment.Reference,
+		&split.Payment.Note,
+		&split.Payment.PaidAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to mark split as paid: %w", err)
+	}
+
+	return split, nil
+}
+
 // UpdateSplitStatus updates the status of a split
 func (r *Repository) UpdateSplitStatus(ctx context.Context, id int64, status SplitStatus, disputeReason *string) (*Split, error) {
 	query := `
 		UPDATE splits
 		SET status = $2, dispute_reason = $3, updated_at = NOW()
 		WHERE id = $1
-		RETURNING id, expense_id, borrower_id, amount_owed, status, dispute_reason, settlement_id, updated_at
+		RETURNING id, expense_id, borrower_id, amount_owed, amount_settled, status, dispute_reason, settlement_id, updated_at,
+		          payment_method, payment_reference, payment_note, paid_at
 	`
 
 	split := &Split{}
@@ -237,10 +351,15 @@ func (r *Repository) UpdateSplitStatus(ctx context.Context, id int64, status Spl
 		&split.ExpenseID,
 		&split.BorrowerID,
 		&split.AmountOwed,
+		&split.AmountSettled,
 		&split.Status,
 		&split.DisputeReason,
 		&split.SettlementID,
 		&split.UpdatedAt,
+		&split.Payment.Method,
+		&split.Payment.Reference,
+		&split.Payment.Note,
+		&split.Payment.PaidAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -252,17 +371,26 @@ func (r *Repository) UpdateSplitStatus(ctx context.Context, id int64, status Spl
 	return split, nil
 }
 
-// GetPendingSplitsBetweenUsers gets all pending/paid splits where user1 owes user2
+// GetPendingSplitsBetweenUsers gets all pending/paid splits where user1 owes user2, oldest expense first.
+// Splits partly covered by a partial settlement that is still pending or paid are left out:
+// rejecting that settlement gives the covered part back, so no other settlement may claim them.
 func (r *Repository) GetPendingSplitsBetweenUsers(ctx context.Context, borrowerID, payerID int64) ([]*Split, error) {
 	query := `
-		SELECT s.id, s.expense_id, s.borrower_id, s.amount_owed, s.status, s.dispute_reason, s.settlement_id, s.updated_at
+		SELECT s.id, s.expense_id, s.borrower_id, s.amount_owed, s.amount_settled, s.status, s.dispute_reason, s.settlement_id, s.updated_at,
+		       s.payment_method, s.payment_reference, s.payment_note, s.paid_at
 		FROM splits s
 		JOIN expenses e ON s.expense_id = e.id
 		WHERE s.borrower_id = $1 
 		  AND e.payer_id = $2
 		  AND s.status IN ('PENDING', 'PAID')
 		  AND s.settlement_id IS NULL
-		ORDER BY s.id
+		  AND NOT EXISTS (
+		      SELECT 1
+		      FROM settlement_allocations a
+		      JOIN settlements st ON a.settlement_id = st.id
+		      WHERE a.split_id = s.id AND st.status IN ('PENDING', 'PAID')
+		  )
+		ORDER BY e.created_at, s.id
 	`
 
 	rows, err := r.db.QueryContext(ctx, query, borrowerID, payerID)
@@ -279,10 +407,15 @@ func (r *Repository) GetPendingSplitsBetweenUsers(ctx context.Context, borrowerI
 			&split.ExpenseID,
 			&split.BorrowerID,
 			&split.AmountOwed,
+			&split.AmountSettled,
 			&split.Status,
 			&split.DisputeReason,
 			&split.SettlementID,
 			&split.UpdatedAt,
+			&split.Payment.Method,
+			&split.Payment.Reference,
+			&split.Payment.Note,
+			&split.Payment.PaidAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan split: %w", err)
 		}
@@ -292,6 +425,44 @@ func (r *Repository) GetPendingSplitsBetweenUsers(ctx context.Context, borrowerI
 	return splits, nil
 }
 
+// GetExpensesByIDs retrieves the given expenses keyed by ID; missing ones are left out
+func (r *Repository) GetExpensesByIDs(ctx context.Context, ids []int64) (map[int64]*Expense, error) {
+	query := `
+		SELECT e.id, e.group_id, e.payer_id, e.description, e.amount, e.image_url, e.split_type, e.category, e.created_at, u.username
+		FROM expenses e
+		JOIN users u ON e.payer_id = u.id
+		WHERE e.id = ANY($1)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expenses: %w", err)
+	}
+	defer rows.Close()
+
+	expenses := make(map[int64]*Expense, len(ids))
+	for rows.Next() {
+		expense := &Expense{}
+		if err := rows.Scan(
+			&expense.ID,
+			&expense.GroupID,
+			&expense.PayerID,
+			&expense.Description,
+			&expense.Amount,
+			&expense.ImageURL,
+			&expense.SplitType,
+			&expense.Category,
+			&expense.CreatedAt,
+			&expense.PayerUsername,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan expense: %w", err)
+		}
+		expenses[expense.ID] = expense
+	}
+
+	return expenses, rows.Err()
+}
+
 // LockSplitsToSettlement locks splits to a settlement
 func (r *Repository) LockSplitsToSettlement(ctx context.Context, splitIDs []int64, settlementID int64) error {
 	for _, splitID := range splitIDs {
@@ -314,6 +485,97 @@ func (r *Repository) UnlockSplitsFromSettlement(ctx context.Context, settlementI
 	return nil
 }
 
+// AllocateToSplit records that a partial settlement covers part of a split
+// without locking it, leaving the rest open for later settlements
+func (r *Repository) AllocateToSplit(ctx context.Context, settlementID, splitID int64, amount float64) error {
+	query := `
+		INSERT INTO settlement_allocations (settlement_id, split_id, amount)
+		VALUES ($1, $2, $3)
+	`
+	if _, err := r.db.ExecContext(ctx, query, settlementID, splitID, amount); err != nil {
+		return fmt.Errorf("failed to allocate settlement to split %d: %w", splitID, err)
+	}
+
+	query = `UPDATE splits SET amount_settled = amount_settled + $2, updated_at = NOW() WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, splitID, amount); err != nil {
+		return fmt.Errorf("failed to update settled amount of split %d: %w", splitID, err)
+	}
+	return nil
+}
+
+// ReleaseAllocations gives back the parts of splits a rejected settlement had covered
+func (r *Repository) ReleaseAllocations(ctx context.Context, settlementID int64) error {
+	query := `
+		UPDATE splits s
+		SET amount_settled = GREATEST(s.amount_settled - a.amount, 0), updated_at = NOW()
+		FROM settlement_allocations a
+		WHERE a.split_id = s.id AND a.settlement_id = $1
+	`
+	if _, err := r.db.ExecContext(ctx, query, settlementID); err != nil {
+		return fmt.Errorf("failed to release settlement allocations: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM settlement_allocations WHERE settlement_id = $1`, settlementID); err != nil {
+		return fmt.Errorf("failed to delete settlement allocations: %w", err)
+	}
+	return nil
+}
+
+// HasAllocationsSettledElsewhere reports whether any split a settlement partly covered has
+// since been locked to another settlement or confirmed, so giving its part back would
+// change a split that is no longer open
+func (r *Repository) HasAllocationsSettledElsewhere(ctx context.Context, settlementID int64) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM settlement_allocations a
+			JOIN splits s ON a.split_id = s.id
+			WHERE a.settlement_id = $1
+			  AND (s.settlement_id IS NOT NULL OR s.status = 'CONFIRMED')
+		)
+	`
+
+	var taken bool
+	if err := r.db.QueryRowContext(ctx, query, settlementID).Scan(&taken); err != nil {
+		return false, fmt.Errorf("failed to check settlement allocations: %w", err)
+	}
+	return taken, nil
+}
+
+// ReverseSplitsBySettlement reopens everything a reversed settlement covered: splits locked
+// to it go back to PENDING and are unlocked, and splits it partly covered get that part back.
+// Each reopened split is first recorded against the reversal with the state it was in.
+func (r *Repository) ReverseSplitsBySettlement(ctx context.Context, settlementID, reversalID int64) error {
+	query := `
+		INSERT INTO settlement_reversal_splits (reversal_id, split_id, previous_status, was_locked, amount)
+		SELECT $2, id, status, TRUE, amount_owed - amount_settled
+		FROM splits
+		WHERE settlement_id = $1
+	`
+	if _, err := r.db.ExecContext(ctx, query, settlementID, reversalID); err != nil {
+		return fmt.Errorf("failed to record reversed splits: %w", err)
+	}
+
+	query = `
+		INSERT INTO settlement_reversal_splits (reversal_id, split_id, previous_status, was_locked, amount)
+		SELECT $2, s.id, s.status, FALSE, a.amount
+		FROM settlement_allocations a
+		JOIN splits s ON a.split_id = s.id
+		WHERE a.settlement_id = $1
+		ON CONFLICT (reversal_id, split_id) DO NOTHING
+	`
+	if _, err := r.db.ExecContext(ctx, query, settlementID, reversalID); err != nil {
+		return fmt.Errorf("failed to record reversed allocations: %w", err)
+	}
+
+	query = `UPDATE splits SET status = $2, settlement_id = NULL, updated_at = NOW() WHERE settlement_id = $1`
+	if _, err := r.db.ExecContext(ctx, query, settlementID, SplitStatusPending); err != nil {
+		return fmt.Errorf("failed to reopen splits: %w", err)
+	}
+
+	return r.ReleaseAllocations(ctx, settlementID)
+}
+
 // ConfirmSplitsBySettlement marks all splits in a settlement as confirmed
 func (r *Repository) ConfirmSplitsBySettlement(ctx context.Context, settlementID int64) error {
 	query := `UPDATE splits SET status = $2, updated_at = NOW() WHERE settlement_id = $1`
@@ -324,6 +586,235 @@ func (r *Repository) ConfirmSplitsBySettlement(ctx context.Context, settlementID
 	return nil
 }
 
+// UpdateExpense modifies an existing expense's description, image or category
+func (r *Repository) UpdateExpense(ctx context.Context, id int64, req *UpdateExpenseRequest) (*Expense, error) {
+	query := `
+		UPDATE expenses
+		SET description = COALESCE($2, description),
+		    image_url = COALESCE($3, image_url),
+		    category = COALESCE($4, category)
+		WHERE id = $1
+		RETURNING id, group_id, payer_id, description, amount, image_url, split_type, category, created_at
+	`
+
+	expense := &Expense{}
+	err := r.db.QueryRowContext(ctx, query, id, req.Description, req.ImageURL, req.Category).Scan(
+		&expense.ID,
+		&expense.GroupID,
+		&expense.PayerID,
+		&expense.Description,
+		&expense.Amount,
+		&expense.ImageURL,
+		&expense.SplitType,
+		&expense.Category,
+		&expense.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to update expense: %w", err)
+	}
+
+	return expense, nil
+}
+
+// GetGroupBalancesForUser returns what the user is owed and owes in each group
+// with open balances, counting the same splits as the settlement net balances
+func (r *Repository) GetGroupBalancesForUser(ctx context.Context, userID int64) ([]*GroupBalance, error) {
+	query := `
+		SELECT g.id, g.name,
+		       COALESCE(SUM(CASE WHEN e.payer_id = $1 THEN s.amount_owed - s.amount_settled ELSE 0 END), 0) AS owed_to_user,
+		       COALESCE(SUM(CASE WHEN s.borrower_id = $1 THEN s.amount_owed - s.amount_settled ELSE 0 END), 0) AS user_owes
+		FROM splits s
+		JOIN expenses e ON s.expense_id = e.id
+		JOIN groups g ON e.group_id = g.id
+		WHERE (e.payer_id = $1 OR s.borrower_id = $1)
+		  AND s.borrower_id != e.payer_id
+		  AND s.status IN ('PENDING', 'PAID')
+		  AND s.settlement_id IS NULL
+		GROUP BY g.id, g.name
+		ORDER BY g.name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group balances: %w", err)
+	}
+	defer rows.Close()
+
+	var balances []*GroupBalance
+	for rows.Next() {
+		b := &GroupBalance{}
+		if err := rows.Scan(&b.GroupID, &b.GroupName, &b.OwedToUser, &b.UserOwes); err != nil {
+			return nil, fmt.Errorf("failed to scan group balance: %w", err)
+		}
+		balances = append(balances, b)
+	}
+
+	return balances, rows.Err()
+}
+
+// ListSplitsForPayer retrieves splits with the given status on expenses the user paid,
+// oldest first. Splits locked to a settlement are handled through the settlement.
+func (r *Repository) ListSplitsForPayer(ctx context.Context, payerID int64, status SplitStatus) ([]*SplitWithExpense, error) {
+	query := `
+		SELECT s.id, s.expense_id, s.borrower_id, s.amount_owed, s.amount_settled, s.status, s.dispute_reason, s.settlement_id, s.updated_at, u.username,
+		       s.payment_method, s.payment_reference, s.payment_note, s.paid_at,
+		       e.description, e.group_id
+		FROM splits s
+		JOIN expenses e ON s.expense_id = e.id
+		JOIN users u ON s.borrower_id = u.id
+		WHERE e.payer_id = $1
+		  AND s.borrower_id != e.payer_id
+		  AND s.status = $2
+		  AND s.settlement_id IS NULL
+		ORDER BY s.updated_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, payerID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list splits for payer: %w", err)
+	}
+	defer rows.Close()
+
+	var splits []*SplitWithExpense
+	for rows.Next() {
+		split := &SplitWithExpense{}
+		if err := rows.Scan(
+			&split.ID,
+			&split.ExpenseID,
+			&split.BorrowerID,
+			&split.AmountOwed,
+			&split.AmountSettled,
+			&split.Status,
+			&split.DisputeReason,
+			&split.SettlementID,
+			&split.UpdatedAt,
+			&split.BorrowerUsername,
+			&split.Payment.Method,
+			&split.Payment.Reference,
+			&split.Payment.Note,
+			&split.Payment.PaidAt,
+			&split.ExpenseDescription,
+			&split.GroupID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan split: %w", err)
+		}
+		splits = append(splits, split)
+	}
+
+	return splits, rows.Err()
+}
+
+// StreamGroupLedger calls fn for every expense in a group paired with each of its
+// splits, oldest expense first. Expenses without splits are passed once with a nil split.
+// Rows are read one at a time so the whole ledger is never held in memory.
+func (r *Repository) StreamGroupLedger(ctx context.Context, groupID int64, fn func(*Expense, *Split) error) error {
+	query := `
+		SELECT e.id, e.group_id, e.payer_id, e.description, e.amount, e.image_url, e.split_type, e.category, e.created_at, p.username,
+		       s.id, s.borrower_id, s.amount_owed, s.status, s.dispute_reason, s.settlement_id, s.updated_at, b.username
+		FROM expenses e
+		JOIN users p ON e.payer_id = p.id
+		LEFT JOIN splits s ON s.expense_id = e.id
+		LEFT JOIN users b ON s.borrower_id = b.id
+		WHERE e.group_id = $1
+		ORDER BY e.created_at, e.id, s.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, groupID)
+	if err != nil {
+		return fmt.Errorf("failed to export ledger: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		expense := &Expense{}
+		var (
+			splitID          sql.NullInt64
+			borrowerID       sql.NullInt64
+			amountOwed       sql.NullFloat64
+			status           sql.NullString
+			disputeReason    *string
+			settlementID     *int64
+			updatedAt        sql.NullTime
+			borrowerUsername sql.NullString
+		)
+		if err := rows.Scan(
+			&expense.ID,
+			&expense.GroupID,
+			&expense.PayerID,
+			&expense.Description,
+			&expense.Amount,
+			&expense.ImageURL,
+			&expense.SplitType,
+			&expense.Category,
+			&expense.CreatedAt,
+			&expense.PayerUsername,
+			&splitID,
+			&borrowerID,
+			&amountOwed,
+			&status,
+			&disputeReason,
+			&settlementID,
+			&updatedAt,
+			&borrowerUsername,
+		); err != nil {
+			return fmt.Errorf("failed to scan ledger row: %w", err)
+		}
+
+		var split *Split
+		if splitID.Valid {
+			split = &Split{
+				ID:               splitID.Int64,
+				ExpenseID:        expense.ID,
+				BorrowerID:       borrowerID.Int64,
+				AmountOwed:       amountOwed.Float64,
+				Status:           SplitStatus(status.String),
+				DisputeReason:    disputeReason,
+				SettlementID:     settlementID,
+				UpdatedAt:        updatedAt.Time,
+				BorrowerUsername: borrowerUsername.String,
+			}
+		}
+
+		if err := fn(expense, split); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// GetGroupIDsBySettlement returns the groups whose splits a settlement locks or partly covers
+func (r *Repository) GetGroupIDsBySettlement(ctx context.Context, settlementID int64) ([]int64, error) {
+	query := `
+		SELECT DISTINCT e.group_id
+		FROM splits s
+		JOIN expenses e ON s.expense_id = e.id
+		WHERE s.settlement_id = $1
+		   OR s.id IN (SELECT split_id FROM settlement_allocations WHERE settlement_id = $1)
+		ORDER BY e.group_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, settlementID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settlement groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groupIDs []int64
+	for rows.Next() {
+		var groupID int64
+		if err := rows.Scan(&groupID); err != nil {
+			return nil, fmt.Errorf("failed to scan group id: %w", err)
+		}
+		groupIDs = append(groupIDs, groupID)
+	}
+
+	return groupIDs, nil
+}
+
 // DeleteExpense deletes an expense and its splits
 func (r *Repository) DeleteExpense(ctx context.Context, id int64) error {
 	// Delete splits first (foreign key constraint)
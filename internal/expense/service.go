@@ -3,8 +3,13 @@ package expense
 import (
 	"context"
 	"errors"
+	"time"
 
+	"github.com/fkhayef/splitwise/internal/activity"
+	"github.com/fkhayef/splitwise/internal/budget"
 	"github.com/fkhayef/splitwise/internal/expense/split"
+	"github.com/fkhayef/splitwise/internal/group"
+	"github.com/fkhayef/splitwise/internal/payment"
 )
 
 // Common errors
@@ -16,38 +21,58 @@ var (
 	ErrNotPayer             = errors.New("only the payer can confirm payment")
 	ErrInvalidStatusChange  = errors.New("invalid status change")
 	ErrCannotDeleteExpense  = errors.New("cannot delete expense with paid/confirmed splits")
+	ErrCannotManageExpense  = errors.New("only the payer or a group admin can change this expense")
+	ErrInvalidExpenseDate   = errors.New("date must be a YYYY-MM-DD date")
+	ErrPayerNotMember       = errors.New("payer is not a member of the group")
 )
 
 // Service handles expense business logic
 type Service struct {
 	repo         *Repository
+	reports      *ReportingRepository
 	splitFactory *split.Factory // Factory pattern for creating split strategies
+	activity     *activity.Service
+	groups       *group.Service
+	budgets      *budget.Service
 }
 
 // NewService creates a new expense service with dependencies injected
-func NewService(repo *Repository, splitFactory *split.Factory) *Service {
+func NewService(repo *Repository, reports *ReportingRepository, splitFactory *split.Factory, activityService *activity.Service, groupService *group.Service, budgetService *budget.Service) *Service {
 	return &Service{
 		repo:         repo,
+		reports:      reports,
 		splitFactory: splitFactory,
+		activity:     activityService,
+		groups:       groupService,
+		budgets:      budgetService,
 	}
 }
 
 // CreateExpense creates a new expense and calculates splits using the appropriate strategy
 func (s *Service) CreateExpense(ctx context.Context, payerID int64, req *CreateExpenseRequest) (*ExpenseWithSplits, error) {
-	// Use FACTORY PATTERN to get the appropriate split strategy
-	strategy, err := s.splitFactory.CreateFromString(req.SplitType)
-	if err != nil {
+	return s.CreateExpenseFor(ctx, payerID, payerID, req)
+}
+
+// CreateExpenseFor creates an expense that actorID records on behalf of payerID,
+// e.g. when importing another app's history. Permissions are checked against
+// the actor, who is also credited in the activity feed; the payer only has to
+// be a joined member of the group.
+func (s *Service) CreateExpenseFor(ctx context.Context, actorID, payerID int64, req *CreateExpenseRequest) (*ExpenseWithSplits, error) {
+	// Only joined members can add expenses, and only admins if the group says so
+	if err := s.groups.CanAddExpense(ctx, req.GroupID, actorID); err != nil {
 		return nil, err
 	}
-
-	// Convert participants to split inputs
-	inputs := make([]split.SplitInput, len(req.Participants))
-	for i, p := range req.Participants {
-		inputs[i] = p.ToSplitInput()
+	if payerID != actorID {
+		joined, err := s.groups.IsJoinedMember(ctx, req.GroupID, payerID)
+		if err != nil {
+			return nil, err
+		}
+		if !joined {
+			return nil, ErrPayerNotMember
+		}
 	}
 
-	// Use STRATEGY PATTERN - calculate splits using the selected strategy
-	splitOutputs, err := strategy.Calculate(req.Amount, payerID, inputs)
+	splitOutputs, err := s.calculateSplits(payerID, req)
 	if err != nil {
 		return nil, err
 	}
@@ -69,12 +94,49 @@ func (s *Service) CreateExpense(ctx context.Context, payerID int64, req *CreateE
 		splits[i] = split
 	}
 
+	s.activity.Record(ctx, expense.GroupID, actorID, activity.TypeExpenseAdded, activity.EntityExpense, expense.ID, activity.Details{
+		Description: expense.Description,
+		Amount:      expense.Amount,
+	})
+	s.budgets.CheckThresholds(ctx, expense.GroupID, expense.CreatedAt)
+
 	return &ExpenseWithSplits{
 		Expense: expense,
 		Splits:  splits,
 	}, nil
 }
 
+// ValidateExpense checks an expense request the way CreateExpense would,
+// without creating anything or checking permissions
+func (s *Service) ValidateExpense(payerID int64, req *CreateExpenseRequest) error {
+	_, err := s.calculateSplits(payerID, req)
+	return err
+}
+
+// calculateSplits validates the request's date and split and works out what each participant owes
+func (s *Service) calculateSplits(payerID int64, req *CreateExpenseRequest) ([]split.SplitOutput, error) {
+	if req.Date != nil {
+		if _, err := time.Parse("2006-01-02", *req.Date); err != nil {
+			return nil, ErrInvalidExpenseDate
+		}
+	}
+
+	// Use FACTORY PATTERN to get the appropriate split strategy
+	strategy, err := s.splitFactory.CreateFromString(req.SplitType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert participants to split inputs
+	inputs := make([]split.SplitInput, len(req.Participants))
+	for i, p := range req.Participants {
+		inputs[i] = p.ToSplitInput()
+	}
+
+	// Use STRATEGY PATTERN - calculate splits using the selected strategy
+	return strategy.Calculate(req.Amount, payerID, inputs)
+}
+
 // GetExpenseByID retrieves an expense with its splits
 func (s *Service) GetExpenseByID(ctx context.Context, id int64) (*ExpenseWithSplits, error) {
 	expense, err := s.repo.GetExpenseByID(ctx, id)
@@ -109,8 +171,8 @@ func (s *Service) ListExpensesByGroupID(ctx context.Context, groupID int64, page
 	return s.repo.ListExpensesByGroupID(ctx, groupID, perPage, offset)
 }
 
-// MarkSplitAsPaid allows the borrower to mark their split as paid
-func (s *Service) MarkSplitAsPaid(ctx context.Context, splitID, borrowerID int64) (*Split, error) {
+// MarkSplitAsPaid allows the borrower to mark their split as paid, recording how it was paid
+func (s *Service) MarkSplitAsPaid(ctx context.Context, splitID, borrowerID int64, details *payment.Details) (*Split, error) {
 	split, err := s.repo.GetSplitByID(ctx, splitID)
 	if err != nil {
 		return nil, err
@@ -134,7 +196,24 @@ func (s *Service) MarkSplitAsPaid(ctx context.Context, splitID, borrowerID int64
 		return nil, ErrInvalidStatusChange
 	}
 
-	return s.repo.UpdateSplitStatus(ctx, splitID, SplitStatusPaid, nil)
+	updated, err := s.repo.MarkSplitPaid(ctx, splitID, details)
+	if err != nil {
+		return nil, err
+	}
+
+	expense, err := s.repo.GetExpenseByID(ctx, split.ExpenseID)
+	if err != nil {
+		return nil, err
+	}
+	if expense != nil {
+		s.activity.Record(ctx, expense.GroupID, borrowerID, activity.TypeSplitPaid, activity.EntitySplit, splitID, activity.Details{
+			Description: expense.Description,
+			Amount:      split.AmountOwed,
+			ExpenseID:   expense.ID,
+		})
+	}
+
+	return updated, nil
 }
 
 // ConfirmSplitPayment allows the payer to confirm they received the payment
@@ -202,21 +281,128 @@ func (s *Service) DeleteExpense(ctx context.Context, id, userID int64) error {
 		return ErrExpenseNotFound
 	}
 
-	// Only the payer can delete
-	if expense.PayerID != userID {
-		return ErrNotPayer
+	// Members manage their own expenses; admins can manage any
+	if err := s.checkCanManage(ctx, expense, userID); err != nil {
+		return err
 	}
 
-	// Check if any splits are paid or confirmed
+	if err := s.groups.EnsureWritable(ctx, expense.GroupID); err != nil {
+		return err
+	}
+
+	// Check if any splits are paid or confirmed, or partly settled
 	splits, err := s.repo.GetSplitsByExpenseID(ctx, id)
 	if err != nil {
 		return err
 	}
 	for _, split := range splits {
-		if split.Status == SplitStatusPaid || split.Status == SplitStatusConfirmed {
+		if split.Status == SplitStatusPaid || split.Status == SplitStatusConfirmed || split.AmountSettled > 0 {
 			return ErrCannotDeleteExpense
 		}
 	}
 
-	return s.repo.DeleteExpense(ctx, id)
+	if err := s.repo.DeleteExpense(ctx, id); err != nil {
+		return err
+	}
+
+	s.activity.Record(ctx, expense.GroupID, userID, activity.TypeExpenseDeleted, activity.EntityExpense, id, activity.Details{
+		Description: expense.Description,
+		Amount:      expense.Amount,
+	})
+
+	return nil
+}
+
+// UpdateExpense lets the payer or a group admin edit an expense's description or image
+func (s *Service) UpdateExpense(ctx context.Context, id, userID int64, req *UpdateExpenseRequest) (*ExpenseWithSplits, error) {
+	expense, err := s.repo.GetExpenseByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if expense == nil {
+		return nil, ErrExpenseNotFound
+	}
+
+	// Members manage their own expenses; admins can manage any
+	if err := s.checkCanManage(ctx, expense, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.groups.EnsureWritable(ctx, expense.GroupID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.UpdateExpense(ctx, id, req); err != nil {
+		return nil, err
+	}
+
+	result, err := s.GetExpenseByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.activity.Record(ctx, expense.GroupID, userID, activity.TypeExpenseUpdated, activity.EntityExpense, id, activity.Details{
+		Description: result.Expense.Description,
+		Amount:      result.Expense.Amount,
+	})
+	s.budgets.CheckThresholds(ctx, expense.GroupID, result.Expense.CreatedAt)
+
+	return result, nil
+}
+
+// GetGroupSummary computes spending statistics for a group over a date range
+func (s *Service) GetGroupSummary(ctx context.Context, groupID int64, dr DateRange, largestLimit int) (*GroupSummary, error) {
+	if _, err := s.groups.GetByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+	if largestLimit < 1 || largestLimit > 50 {
+		largestLimit = 5
+	}
+
+	summary := &GroupSummary{GroupID: groupID, Range: dr}
+
+	var err error
+	summary.ExpenseCount, summary.TotalSpent, err = s.reports.GetGroupTotals(ctx, groupID, dr)
+	if err != nil {
+		return nil, err
+	}
+	if summary.Members, err = s.reports.GetMemberSpend(ctx, groupID, dr); err != nil {
+		return nil, err
+	}
+	if summary.Categories, err = s.reports.GetCategorySpend(ctx, groupID, dr); err != nil {
+		return nil, err
+	}
+	if summary.Months, err = s.reports.GetMonthlySpend(ctx, groupID, dr); err != nil {
+		return nil, err
+	}
+	if summary.LargestExpenses, err = s.reports.GetLargestExpenses(ctx, groupID, dr, largestLimit); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// ExportGroupLedger streams every expense and split in a group to fn.
+// It fails with group.ErrGroupNotFound before calling fn if the group doesn't exist.
+func (s *Service) ExportGroupLedger(ctx context.Context, groupID int64, fn func(*Expense, *Split) error) error {
+	if _, err := s.groups.GetByID(ctx, groupID); err != nil {
+		return err
+	}
+	return s.repo.StreamGroupLedger(ctx, groupID, fn)
+}
+
+// checkCanManage returns ErrCannotManageExpense unless the user paid the expense or is a group admin
+func (s *Service) checkCanManage(ctx context.Context, expense *Expense, userID int64) error {
+	if expense.PayerID == userID {
+		return nil
+	}
+
+	isAdmin, err := s.groups.IsAdmin(ctx, expense.GroupID, userID)
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return ErrCannotManageExpense
+	}
+	return nil
 }
@@ -1,16 +1,31 @@
 package group
 
+import "time"
+
 // CreateGroupRequest represents the request to create a new group
 type CreateGroupRequest struct {
 	Name        string  `json:"name" validate:"required,min=1,max=100"`
 	Description *string `json:"description,omitempty"`
 	IsTemporary bool    `json:"is_temporary"`
+	EndDate     *string `json:"end_date,omitempty"` // YYYY-MM-DD, temporary groups only
+
+	OnlyAdminsAddExpenses *bool `json:"only_admins_add_expenses,omitempty"`
+	OnlyAdminsInvite      *bool `json:"only_admins_invite,omitempty"`
 }
 
-// UpdateGroupRequest represents the request to update a group
+// UpdateGroupRequest represents the request to update a group (admins only)
 type UpdateGroupRequest struct {
 	Name        *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
 	Description *string `json:"description,omitempty"`
+	EndDate     *string `json:"end_date,omitempty"` // YYYY-MM-DD, temporary groups only
+
+	OnlyAdminsAddExpenses *bool `json:"only_admins_add_expenses,omitempty"`
+	OnlyAdminsInvite      *bool `json:"only_admins_invite,omitempty"`
+}
+
+// TransferOwnershipRequest represents the request to hand a group to another member
+type TransferOwnershipRequest struct {
+	UserID int64 `json:"user_id" validate:"required"`
 }
 
 // AddMemberRequest represents the request to add a member to a group
@@ -31,6 +46,11 @@ type GroupResponse struct {
 	Name        string            `json:"name"`
 	Description *string           `json:"description,omitempty"`
 	IsTemporary bool              `json:"is_temporary"`
+	EndDate     *string           `json:"end_date,omitempty"`
+	IsArchived  bool              `json:"is_archived"`
+	ArchivedAt  *string           `json:"archived_at,omitempty"`
+	OwnerID     *int64            `json:"owner_id,omitempty"`
+	Settings    GroupSettings     `json:"settings"`
 	CreatedAt   string            `json:"created_at"`
 	Members     []*MemberResponse `json:"members,omitempty"`
 }
@@ -44,22 +64,35 @@ type MemberResponse struct {
 	Status   MemberStatus `json:"status"`
 	Role     MemberRole   `json:"role"`
 	JoinedAt string       `json:"joined_at"`
+	LeftAt   *string      `json:"left_at,omitempty"`
 }
 
 // ToResponse converts a Group model to a GroupResponse DTO
 func (g *Group) ToResponse() *GroupResponse {
-	return &GroupResponse{
+	resp := &GroupResponse{
 		ID:          g.ID,
 		Name:        g.Name,
 		Description: g.Description,
 		IsTemporary: g.IsTemporary,
+		IsArchived:  g.IsArchived(),
+		OwnerID:     g.OwnerID,
+		Settings:    g.Settings,
 		CreatedAt:   g.CreatedAt.Format("2006-01-02T15:04:05Z"),
 	}
+	if g.EndDate != nil {
+		endDate := g.EndDate.Format("2006-01-02")
+		resp.EndDate = &endDate
+	}
+	if g.ArchivedAt != nil {
+		archivedAt := g.ArchivedAt.Format("2006-01-02T15:04:05Z")
+		resp.ArchivedAt = &archivedAt
+	}
+	return resp
 }
 
 // ToResponse converts a GroupMember model to a MemberResponse DTO
 func (m *GroupMember) ToResponse() *MemberResponse {
-	return &MemberResponse{
+	resp := &MemberResponse{
 		ID:       m.ID,
 		UserID:   m.UserID,
 		Username: m.Username,
@@ -68,4 +101,92 @@ func (m *GroupMember) ToResponse() *MemberResponse {
 		Role:     m.Role,
 		JoinedAt: m.JoinedAt.Format("2006-01-02T15:04:05Z"),
 	}
+	if m.LeftAt != nil {
+		leftAt := m.LeftAt.Format("2006-01-02T15:04:05Z")
+		resp.LeftAt = &leftAt
+	}
+	return resp
+}
+
+// InviteByEmailRequest represents the request to invite someone by email
+type InviteByEmailRequest struct {
+	Email string     `json:"email" validate:"required,email"`
+	Role  MemberRole `json:"role"`
+}
+
+// AcceptInviteTokenRequest represents the request to accept an email invitation.
+// Username is only used when the invitee does not have an account yet.
+type AcceptInviteTokenRequest struct {
+	Username *string `json:"username,omitempty" validate:"omitempty,min=3,max=50"`
+}
+
+// InvitationResponse represents the response for an email invitation
+type InvitationResponse struct {
+	ID          int64            `json:"id"`
+	GroupID     int64            `json:"group_id"`
+	GroupName   string           `json:"group_name,omitempty"`
+	Email       string           `json:"email"`
+	Role        MemberRole       `json:"role"`
+	InvitedBy   int64            `json:"invited_by"`
+	Status      InvitationStatus `json:"status"`
+	ExpiresAt   string           `json:"expires_at"`
+	RespondedAt *string          `json:"responded_at,omitempty"`
+	CreatedAt   string           `json:"created_at"`
+}
+
+// ToResponse converts an Invitation model to an InvitationResponse DTO
+func (i *Invitation) ToResponse() *InvitationResponse {
+	resp := &InvitationResponse{
+		ID:        i.ID,
+		GroupID:   i.GroupID,
+		GroupName: i.GroupName,
+		Email:     i.Email,
+		Role:      i.Role,
+		InvitedBy: i.InvitedBy,
+		Status:    i.Status,
+		ExpiresAt: i.ExpiresAt.Format("2006-01-02T15:04:05Z"),
+		CreatedAt: i.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+	if i.RespondedAt != nil {
+		respondedAt := i.RespondedAt.Format("2006-01-02T15:04:05Z")
+		resp.RespondedAt = &respondedAt
+	}
+	return resp
+}
+
+// JoinCodeRequest represents the request to generate or update a join code.
+// ExpiresInHours and MaxUses are optional; 0 removes the limit.
+type JoinCodeRequest struct {
+	ExpiresInHours *int  `json:"expires_in_hours,omitempty" validate:"omitempty,min=0"`
+	MaxUses        *int  `json:"max_uses,omitempty" validate:"omitempty,min=0"`
+	Enabled        *bool `json:"enabled,omitempty"` // Only used when updating
+}
+
+// JoinCodeResponse represents a group's join code
+type JoinCodeResponse struct {
+	GroupID   int64   `json:"group_id"`
+	Code      *string `json:"code,omitempty"`
+	Link      *string `json:"link,omitempty"`
+	Enabled   bool    `json:"enabled"`
+	Usable    bool    `json:"usable"` // Enabled, not expired and under the use cap
+	ExpiresAt *string `json:"expires_at,omitempty"`
+	MaxUses   *int    `json:"max_uses,omitempty"`
+	Uses      int     `json:"uses"`
+}
+
+// ToResponse converts a JoinCode model to a JoinCodeResponse DTO
+func (j *JoinCode) ToResponse() *JoinCodeResponse {
+	resp := &JoinCodeResponse{
+		GroupID: j.GroupID,
+		Code:    j.Code,
+		Enabled: j.Enabled,
+		Usable:  j.IsUsable(time.Now()),
+		MaxUses: j.MaxUses,
+		Uses:    j.Uses,
+	}
+	if j.ExpiresAt != nil {
+		expiresAt := j.ExpiresAt.Format("2006-01-02T15:04:05Z")
+		resp.ExpiresAt = &expiresAt
+	}
+	return resp
 }
@@ -8,6 +8,8 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/fkhayef/splitwise/internal/activity"
+	"github.com/fkhayef/splitwise/pkg/i18n"
 	"github.com/fkhayef/splitwise/pkg/middleware"
 	"github.com/fkhayef/splitwise/pkg/response"
 )
@@ -37,7 +39,32 @@ func (h *Handler) Routes() chi.Router {
 	r.Get("/{id}/members", h.GetMembers)
 	r.Put("/{id}/members/{userId}", h.UpdateMember)
 	r.Delete("/{id}/members/{userId}", h.RemoveMember)
+	r.Post("/{id}/leave", h.Leave)
+	r.Post("/{id}/transfer-ownership", h.TransferOwnership)
 	r.Post("/{id}/accept", h.AcceptInvitation)
+	r.Post("/{id}/decline", h.DeclineInvitation)
+
+	// Email invitations
+	r.Post("/{id}/invitations", h.InviteByEmail)
+	r.Get("/{id}/invitations", h.ListInvitations)
+	r.Delete("/{id}/invitations/{invitationId}", h.RevokeInvitation)
+	r.Get("/invitations/{token}", h.GetInvitationByToken)
+	r.Post("/invitations/{token}/accept", h.AcceptInvitationByToken)
+	r.Post("/invitations/{token}/decline", h.DeclineInvitationByToken)
+
+	// Join codes
+	r.Get("/{id}/join-code", h.GetJoinCode)
+	r.Post("/{id}/join-code", h.GenerateJoinCode)
+	r.Put("/{id}/join-code", h.UpdateJoinCode)
+	r.Delete("/{id}/join-code", h.DisableJoinCode)
+	r.Post("/join/{code}", h.JoinByCode)
+
+	// Archiving
+	r.Post("/{id}/archive", h.Archive)
+	r.Post("/{id}/unarchive", h.Unarchive)
+
+	// Activity feed
+	r.Get("/{id}/activity", h.ListActivity)
 
 	return r
 }
@@ -57,6 +84,10 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 
 	group, err := h.service.Create(r.Context(), creatorID, &req)
 	if err != nil {
+		if errors.Is(err, ErrInvalidEndDate) {
+			response.BadRequest(w, err.Error())
+			return
+		}
 		response.InternalError(w, "Failed to create group")
 		return
 	}
@@ -146,12 +177,29 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	group, err := h.service.Update(r.Context(), id, &req)
+	actorID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		actorID = 1
+	}
+
+	group, err := h.service.Update(r.Context(), id, actorID, &req)
 	if err != nil {
+		if errors.Is(err, ErrNotAuthorized) {
+			response.Forbidden(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrInvalidEndDate) {
+			response.BadRequest(w, err.Error())
+			return
+		}
 		if errors.Is(err, ErrGroupNotFound) {
 			response.NotFound(w, err.Error())
 			return
 		}
+		if errors.Is(err, ErrGroupArchived) {
+			response.Conflict(w, err.Error())
+			return
+		}
 		response.InternalError(w, "Failed to update group")
 		return
 	}
@@ -168,7 +216,20 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.Delete(r.Context(), id); err != nil {
+	actorID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		actorID = 1
+	}
+
+	if err := h.service.Delete(r.Context(), id, actorID); err != nil {
+		if errors.Is(err, ErrGroupNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrNotAuthorized) {
+			response.Forbidden(w, err.Error())
+			return
+		}
 		response.InternalError(w, "Failed to delete group")
 		return
 	}
@@ -191,8 +252,17 @@ func (h *Handler) AddMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	member, err := h.service.AddMember(r.Context(), groupID, &req)
+	actorID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		actorID = 1
+	}
+
+	member, err := h.service.AddMember(r.Context(), groupID, actorID, &req)
 	if err != nil {
+		if errors.Is(err, ErrNotAuthorized) {
+			response.Forbidden(w, err.Error())
+			return
+		}
 		if errors.Is(err, ErrGroupNotFound) {
 			response.NotFound(w, err.Error())
 			return
@@ -201,6 +271,10 @@ func (h *Handler) AddMember(w http.ResponseWriter, r *http.Request) {
 			response.Conflict(w, err.Error())
 			return
 		}
+		if errors.Is(err, ErrGroupArchived) {
+			response.Conflict(w, err.Error())
+			return
+		}
 		response.InternalError(w, "Failed to add member")
 		return
 	}
@@ -255,12 +329,33 @@ func (h *Handler) UpdateMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	member, err := h.service.UpdateMember(r.Context(), groupID, userID, &req)
+	actorID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		actorID = 1
+	}
+
+	member, err := h.service.UpdateMember(r.Context(), groupID, userID, actorID, &req)
 	if err != nil {
 		if errors.Is(err, ErrMemberNotFound) {
 			response.NotFound(w, err.Error())
 			return
 		}
+		if errors.Is(err, ErrNotAuthorized) {
+			response.Forbidden(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrStatusNotEditable) {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrLastAdmin) || errors.Is(err, ErrOwnerMustTransfer) {
+			response.Conflict(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrGroupArchived) {
+			response.Conflict(w, err.Error())
+			return
+		}
 		response.InternalError(w, "Failed to update member")
 		return
 	}
@@ -282,7 +377,35 @@ func (h *Handler) RemoveMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.RemoveMember(r.Context(), groupID, userID); err != nil {
+	actorID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		actorID = 1
+	}
+
+	// force=true lets an admin remove a member who still has unsettled splits
+	force := r.URL.Query().Get("force") == "true"
+
+	if err := h.service.RemoveMember(r.Context(), groupID, userID, actorID, force); err != nil {
+		if errors.Is(err, ErrGroupNotFound) || errors.Is(err, ErrMemberNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrNotAuthorized) {
+			response.Forbidden(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrMemberHasUnsettledSplits) {
+			response.Conflict(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrLastAdmin) || errors.Is(err, ErrOwnerMustTransfer) {
+			response.Conflict(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrGroupArchived) {
+			response.Conflict(w, err.Error())
+			return
+		}
 		response.InternalError(w, "Failed to remove member")
 		return
 	}
@@ -290,6 +413,88 @@ func (h *Handler) RemoveMember(w http.ResponseWriter, r *http.Request) {
 	response.JSON(w, http.StatusOK, map[string]string{"message": "Member removed successfully"})
 }
 
+// Leave handles POST /groups/{id}/leave
+func (h *Handler) Leave(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid group ID")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	member, err := h.service.LeaveGroup(r.Context(), groupID, userID)
+	if err != nil {
+		if errors.Is(err, ErrMemberNotFound) {
+			response.NotFound(w, "You are not a member of this group")
+			return
+		}
+		if errors.Is(err, ErrMemberHasUnsettledSplits) {
+			response.Conflict(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrLastAdmin) || errors.Is(err, ErrOwnerMustTransfer) {
+			response.Conflict(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrGroupArchived) {
+			response.Conflict(w, err.Error())
+			return
+		}
+		response.InternalError(w, "Failed to leave group")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, member.ToResponse())
+}
+
+// TransferOwnership handles POST /groups/{id}/transfer-ownership
+func (h *Handler) TransferOwnership(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid group ID")
+		return
+	}
+
+	actorID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		actorID = 1
+	}
+
+	var req TransferOwnershipRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	group, err := h.service.TransferOwnership(r.Context(), groupID, actorID, req.UserID)
+	if err != nil {
+		if errors.Is(err, ErrGroupNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrMemberNotFound) {
+			response.BadRequest(w, "The new owner must be a joined member of the group")
+			return
+		}
+		if errors.Is(err, ErrNotAuthorized) {
+			response.Forbidden(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrGroupArchived) {
+			response.Conflict(w, err.Error())
+			return
+		}
+		response.InternalError(w, "Failed to transfer ownership")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, group.ToResponse())
+}
+
 // AcceptInvitation handles POST /groups/{id}/accept
 func (h *Handler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
 	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
@@ -309,9 +514,478 @@ func (h *Handler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
 			response.NotFound(w, "You are not invited to this group")
 			return
 		}
+		if errors.Is(err, ErrGroupArchived) {
+			response.Conflict(w, err.Error())
+			return
+		}
+		response.InternalError(w, "Failed to accept invitation")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, member.ToResponse())
+}
+
+// DeclineInvitation handles POST /groups/{id}/decline
+func (h *Handler) DeclineInvitation(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid group ID")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	if err := h.service.DeclineInvitation(r.Context(), groupID, userID); err != nil {
+		if errors.Is(err, ErrMemberNotFound) {
+			response.NotFound(w, "You are not invited to this group")
+			return
+		}
+		if errors.Is(err, ErrInvitationNotPending) {
+			response.Conflict(w, err.Error())
+			return
+		}
+		response.InternalError(w, "Failed to decline invitation")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{"message": "Invitation declined"})
+}
+
+// InviteByEmail handles POST /groups/{id}/invitations
+func (h *Handler) InviteByEmail(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid group ID")
+		return
+	}
+
+	inviterID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		inviterID = 1
+	}
+
+	var req InviteByEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	invitation, err := h.service.InviteByEmail(r.Context(), groupID, inviterID, &req)
+	if err != nil {
+		if errors.Is(err, ErrGroupNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrNotAuthorized) {
+			response.Forbidden(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrInvalidEmail) {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrMemberAlreadyExists) || errors.Is(err, ErrInvitationAlreadyPending) {
+			response.Conflict(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrGroupArchived) {
+			response.Conflict(w, err.Error())
+			return
+		}
+		response.InternalError(w, "Failed to send invitation")
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, invitation.ToResponse())
+}
+
+// ListInvitations handles GET /groups/{id}/invitations
+func (h *Handler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid group ID")
+		return
+	}
+
+	invitations, err := h.service.ListInvitations(r.Context(), groupID)
+	if err != nil {
+		if errors.Is(err, ErrGroupNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		response.InternalError(w, "Failed to list invitations")
+		return
+	}
+
+	invitationResponses := make([]*InvitationResponse, len(invitations))
+	for i, inv := range invitations {
+		invitationResponses[i] = inv.ToResponse()
+	}
+
+	response.JSON(w, http.StatusOK, invitationResponses)
+}
+
+// RevokeInvitation handles DELETE /groups/{id}/invitations/{invitationId}
+func (h *Handler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid group ID")
+		return
+	}
+
+	invitationID, err := strconv.ParseInt(chi.URLParam(r, "invitationId"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid invitation ID")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	invitation, err := h.service.RevokeInvitation(r.Context(), groupID, invitationID, userID)
+	if err != nil {
+		if errors.Is(err, ErrInvitationNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrNotAuthorized) {
+			response.Forbidden(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrInvitationNotPending) {
+			response.Conflict(w, err.Error())
+			return
+		}
+		response.InternalError(w, "Failed to revoke invitation")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, invitation.ToResponse())
+}
+
+// GetInvitationByToken handles GET /groups/invitations/{token}
+func (h *Handler) GetInvitationByToken(w http.ResponseWriter, r *http.Request) {
+	invitation, err := h.service.GetInvitationByToken(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		if errors.Is(err, ErrInvalidInviteToken) || errors.Is(err, ErrInvitationExpired) {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrInvitationNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		response.InternalError(w, "Failed to get invitation")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, invitation.ToResponse())
+}
+
+// AcceptInvitationByToken handles POST /groups/invitations/{token}/accept
+func (h *Handler) AcceptInvitationByToken(w http.ResponseWriter, r *http.Request) {
+	var req AcceptInviteTokenRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, "Invalid request body")
+			return
+		}
+	}
+
+	member, err := h.service.AcceptInvitationByToken(r.Context(), chi.URLParam(r, "token"), &req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidInviteToken) || errors.Is(err, ErrInvitationExpired) {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrInvitationNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrInvitationNotPending) {
+			response.Conflict(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrGroupArchived) {
+			response.Conflict(w, err.Error())
+			return
+		}
 		response.InternalError(w, "Failed to accept invitation")
 		return
 	}
 
 	response.JSON(w, http.StatusOK, member.ToResponse())
 }
+
+// DeclineInvitationByToken handles POST /groups/invitations/{token}/decline
+func (h *Handler) DeclineInvitationByToken(w http.ResponseWriter, r *http.Request) {
+	invitation, err := h.service.DeclineInvitationByToken(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		if errors.Is(err, ErrInvalidInviteToken) || errors.Is(err, ErrInvitationExpired) {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrInvitationNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrInvitationNotPending) {
+			response.Conflict(w, err.Error())
+			return
+		}
+		response.InternalError(w, "Failed to decline invitation")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, invitation.ToResponse())
+}
+
+// GetJoinCode handles GET /groups/{id}/join-code
+func (h *Handler) GetJoinCode(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid group ID")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	jc, err := h.service.GetJoinCode(r.Context(), groupID, userID)
+	if err != nil {
+		h.writeJoinCodeError(w, err, "Failed to get join code")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, h.joinCodeResponse(jc))
+}
+
+// GenerateJoinCode handles POST /groups/{id}/join-code
+func (h *Handler) GenerateJoinCode(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid group ID")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	var req JoinCodeRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, "Invalid request body")
+			return
+		}
+	}
+
+	jc, err := h.service.GenerateJoinCode(r.Context(), groupID, userID, &req)
+	if err != nil {
+		h.writeJoinCodeError(w, err, "Failed to generate join code")
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, h.joinCodeResponse(jc))
+}
+
+// UpdateJoinCode handles PUT /groups/{id}/join-code
+func (h *Handler) UpdateJoinCode(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid group ID")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	var req JoinCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	jc, err := h.service.UpdateJoinCode(r.Context(), groupID, userID, &req)
+	if err != nil {
+		h.writeJoinCodeError(w, err, "Failed to update join code")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, h.joinCodeResponse(jc))
+}
+
+// DisableJoinCode handles DELETE /groups/{id}/join-code
+func (h *Handler) DisableJoinCode(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid group ID")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	jc, err := h.service.DisableJoinCode(r.Context(), groupID, userID)
+	if err != nil {
+		h.writeJoinCodeError(w, err, "Failed to disable join code")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, h.joinCodeResponse(jc))
+}
+
+// JoinByCode handles POST /groups/join/{code}
+func (h *Handler) JoinByCode(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	member, err := h.service.JoinByCode(r.Context(), chi.URLParam(r, "code"), userID)
+	if err != nil {
+		if errors.Is(err, ErrInvalidJoinCode) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrMemberAlreadyExists) {
+			response.Conflict(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrGroupArchived) {
+			response.Conflict(w, err.Error())
+			return
+		}
+		response.InternalError(w, "Failed to join group")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, member.ToResponse())
+}
+
+// joinCodeResponse builds a join code response including the shareable link
+func (h *Handler) joinCodeResponse(jc *JoinCode) *JoinCodeResponse {
+	resp := jc.ToResponse()
+	if jc.Code != nil {
+		link := h.service.JoinLink(*jc.Code)
+		resp.Link = &link
+	}
+	return resp
+}
+
+// writeJoinCodeError maps join code management errors to responses
+func (h *Handler) writeJoinCodeError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrGroupNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, ErrNotAuthorized):
+		response.Forbidden(w, err.Error())
+	case errors.Is(err, ErrInvalidJoinCode):
+		response.BadRequest(w, "This group has no join code yet")
+	case errors.Is(err, ErrGroupArchived):
+		response.Conflict(w, err.Error())
+	default:
+		response.InternalError(w, fallback)
+	}
+}
+
+// Archive handles POST /groups/{id}/archive
+func (h *Handler) Archive(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, true)
+}
+
+// Unarchive handles POST /groups/{id}/unarchive
+func (h *Handler) Unarchive(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, false)
+}
+
+// setArchived archives or unarchives the group in the URL
+func (h *Handler) setArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid group ID")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	var group *Group
+	if archived {
+		group, err = h.service.Archive(r.Context(), groupID, userID)
+	} else {
+		group, err = h.service.Unarchive(r.Context(), groupID, userID)
+	}
+	if err != nil {
+		if errors.Is(err, ErrGroupNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrNotAuthorized) {
+			response.Forbidden(w, err.Error())
+			return
+		}
+		response.InternalError(w, "Failed to update group archive state")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, group.ToResponse())
+}
+
+// ListActivity handles GET /groups/{id}/activity
+func (h *Handler) ListActivity(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid group ID")
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	activities, total, err := h.service.ListActivity(r.Context(), groupID, page, perPage)
+	if err != nil {
+		if errors.Is(err, ErrGroupNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		response.InternalError(w, "Failed to get group activity")
+		return
+	}
+
+	activityResponses := make([]*activity.ActivityResponse, len(activities))
+	for i, a := range activities {
+		activityResponses[i] = a.ToResponse(i18n.Language(r.Context()))
+	}
+
+	totalPages := (total + perPage - 1) / perPage
+	meta := &response.Meta{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	response.JSONWithMeta(w, http.StatusOK, activityResponses, meta)
+}
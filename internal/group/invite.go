@@ -0,0 +1,133 @@
+package group
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fkhayef/splitwise/internal/mailer"
+	"github.com/fkhayef/splitwise/pkg/i18n"
+)
+
+// Invitation token errors
+var (
+	ErrInvalidInviteToken = errors.New("invalid invitation token")
+	ErrInvitationExpired  = errors.New("invitation has expired")
+)
+
+// Inviter issues signed invitation tokens and emails them to invitees.
+// A token is "<payload>.<signature>" where the payload encodes the
+// invitation ID and expiry, so it can be verified without a lookup.
+type Inviter struct {
+	mailer  mailer.Mailer
+	secret  []byte
+	ttl     time.Duration
+	baseURL string
+}
+
+// NewInviter creates a new inviter
+func NewInviter(m mailer.Mailer, secret string, ttl time.Duration, baseURL string) *Inviter {
+	return &Inviter{
+		mailer:  m,
+		secret:  []byte(secret),
+		ttl:     ttl,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// ExpiresAt returns the expiry for an invitation created now
+func (i *Inviter) ExpiresAt(now time.Time) time.Time {
+	return now.Add(i.ttl)
+}
+
+// Sign creates a token for an invitation
+func (i *Inviter) Sign(invitationID int64, expiresAt time.Time) string {
+	payload := fmt.Sprintf("%d.%d", invitationID, expiresAt.Unix())
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(i.mac(encoded))
+}
+
+// Verify checks a token's signature and expiry and returns the invitation ID
+func (i *Inviter) Verify(token string, now time.Time) (int64, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, ErrInvalidInviteToken
+	}
+
+	gotMAC, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(gotMAC, i.mac(encoded)) {
+		return 0, ErrInvalidInviteToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, ErrInvalidInviteToken
+	}
+	idStr, expStr, ok := strings.Cut(string(payload), ".")
+	if !ok {
+		return 0, ErrInvalidInviteToken
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidInviteToken
+	}
+	exp, err := strconv.ParseInt(expStr, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidInviteToken
+	}
+
+	if now.After(time.Unix(exp, 0)) {
+		return 0, ErrInvitationExpired
+	}
+
+	return id, nil
+}
+
+// SendInvitation emails the invitation link to the invitee in lang
+func (i *Inviter) SendInvitation(ctx context.Context, inv *Invitation, groupName, inviterName, lang string) error {
+	token := i.Sign(inv.ID, inv.ExpiresAt)
+	link := fmt.Sprintf("%s/invitations?token=%s", i.baseURL, url.QueryEscape(token))
+
+	return i.mailer.Send(ctx, &mailer.Message{
+		To:      inv.Email,
+		Subject: i18n.T(lang, "invite.subject", inviterName, groupName),
+		Body:    i18n.T(lang, "invite.body", inviterName, groupName, link, i18n.FormatDateTime(lang, inv.ExpiresAt.UTC())),
+	})
+}
+
+// mac computes the HMAC-SHA256 of a token payload
+func (i *Inviter) mac(payload string) []byte {
+	h := hmac.New(sha256.New, i.secret)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+// JoinLink returns the shareable link for a group join code
+func (i *Inviter) JoinLink(code string) string {
+	return fmt.Sprintf("%s/join/%s", i.baseURL, url.PathEscape(code))
+}
+
+// joinCodeAlphabet leaves out characters that are easy to confuse (0/O, 1/I/L)
+const joinCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// generateJoinCode creates a random, human-friendly join code
+func generateJoinCode() (string, error) {
+	const length = 8
+
+	buf := make([]byte, length)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate join code: %w", err)
+	}
+	for i := range buf {
+		buf[i] = joinCodeAlphabet[int(buf[i])%len(joinCodeAlphabet)]
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,116 @@
+package group
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/fkhayef/splitwise/internal/notification"
+)
+
+// Lifecycle runs the periodic housekeeping for temporary groups:
+// it reminds members to settle up as the end date approaches and
+// archives groups once they have ended and every balance is zero.
+type Lifecycle struct {
+	service       *Service
+	repo          *Repository
+	notifications *notification.Service
+	interval      time.Duration
+	reminderDays  int
+}
+
+// NewLifecycle creates a new lifecycle runner
+func NewLifecycle(service *Service, repo *Repository, notifications *notification.Service, interval time.Duration, reminderDays int) *Lifecycle {
+	return &Lifecycle{
+		service:       service,
+		repo:          repo,
+		notifications: notifications,
+		interval:      interval,
+		reminderDays:  reminderDays,
+	}
+}
+
+// Start runs the lifecycle checks immediately and then on every interval
+// until the context is cancelled
+func (l *Lifecycle) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(l.interval)
+		defer ticker.Stop()
+
+		for {
+			l.RunOnce(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce sends due settle-up reminders and archives finished groups
+func (l *Lifecycle) RunOnce(ctx context.Context) {
+	if err := l.sendReminders(ctx); err != nil {
+		log.Printf("group lifecycle: failed to send reminders: %v", err)
+	}
+	if err := l.archiveFinished(ctx); err != nil {
+		log.Printf("group lifecycle: failed to archive groups: %v", err)
+	}
+}
+
+// sendReminders notifies members with open balances in groups that end soon
+func (l *Lifecycle) sendReminders(ctx context.Context) error {
+	groups, err := l.repo.ListGroupsEndingSoon(ctx, l.reminderDays)
+	if err != nil {
+		return err
+	}
+
+	for _, g := range groups {
+		members, err := l.repo.GetMembers(ctx, g.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range members {
+			if m.Status != MemberStatusJoined {
+				continue
+			}
+
+			balance, err := l.repo.GetUnsettledBalance(ctx, g.ID, m.UserID)
+			if err != nil {
+				return err
+			}
+			if balance.SplitCount == 0 {
+				continue
+			}
+
+			if _, err := l.notifications.NotifySettleReminder(ctx, m.UserID, g.Name, *g.EndDate, g.ID); err != nil {
+				log.Printf("group lifecycle: failed to notify user %d in group %d: %v", m.UserID, g.ID, err)
+			}
+		}
+
+		if err := l.repo.MarkSettleReminderSent(ctx, g.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// archiveFinished archives temporary groups that have ended and are fully settled
+func (l *Lifecycle) archiveFinished(ctx context.Context) error {
+	groups, err := l.repo.ListGroupsReadyToArchive(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, g := range groups {
+		// Actor 0 marks the archive as automatic in the activity feed
+		if _, err := l.service.setArchived(ctx, g.ID, 0, true); err != nil {
+			log.Printf("group lifecycle: failed to archive group %d: %v", g.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -8,6 +8,7 @@ type MemberStatus string
 const (
 	MemberStatusInvited MemberStatus = "INVITED"
 	MemberStatusJoined  MemberStatus = "JOINED"
+	MemberStatusLeft    MemberStatus = "LEFT" // Former member, kept for history
 )
 
 // MemberRole represents the role of a group member
@@ -20,11 +21,31 @@ const (
 
 // Group represents a group in the system
 type Group struct {
-	ID          int64     `json:"id"`
-	Name        string    `json:"name"`
-	Description *string   `json:"description,omitempty"`
-	IsTemporary bool      `json:"is_temporary"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          int64         `json:"id"`
+	Name        string        `json:"name"`
+	Description *string       `json:"description,omitempty"`
+	IsTemporary bool          `json:"is_temporary"`
+	EndDate     *time.Time    `json:"end_date,omitempty"`    // Temporary groups only
+	ArchivedAt  *time.Time    `json:"archived_at,omitempty"` // Archived groups are read-only
+	OwnerID     *int64        `json:"owner_id,omitempty"`
+	Settings    GroupSettings `json:"settings"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// GroupSettings holds the admin-controlled permissions of a group
+type GroupSettings struct {
+	OnlyAdminsAddExpenses bool `json:"only_admins_add_expenses"`
+	OnlyAdminsInvite      bool `json:"only_admins_invite"` // Applies to adding members and email invitations
+}
+
+// IsOwner reports whether the user owns the group
+func (g *Group) IsOwner(userID int64) bool {
+	return g.OwnerID != nil && *g.OwnerID == userID
+}
+
+// IsArchived reports whether the group has been archived
+func (g *Group) IsArchived() bool {
+	return g.ArchivedAt != nil
 }
 
 // GroupMember represents a user's membership in a group
@@ -35,8 +56,71 @@ type GroupMember struct {
 	Status   MemberStatus `json:"status"`
 	Role     MemberRole   `json:"role"`
 	JoinedAt time.Time    `json:"joined_at"`
+	LeftAt   *time.Time   `json:"left_at,omitempty"`
 
 	// Populated from JOIN
 	Username string `json:"username,omitempty"`
 	Email    string `json:"email,omitempty"`
 }
+
+// InvitationStatus represents the status of an email invitation
+type InvitationStatus string
+
+const (
+	InvitationStatusPending  InvitationStatus = "PENDING"
+	InvitationStatusAccepted InvitationStatus = "ACCEPTED"
+	InvitationStatusDeclined InvitationStatus = "DECLINED"
+	InvitationStatusRevoked  InvitationStatus = "REVOKED"
+)
+
+// Invitation represents an email invitation to join a group.
+// The invitee does not need an account until they accept.
+type Invitation struct {
+	ID          int64            `json:"id"`
+	GroupID     int64            `json:"group_id"`
+	Email       string           `json:"email"`
+	Role        MemberRole       `json:"role"`
+	InvitedBy   int64            `json:"invited_by"`
+	Status      InvitationStatus `json:"status"`
+	ExpiresAt   time.Time        `json:"expires_at"`
+	RespondedAt *time.Time       `json:"responded_at,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+
+	// Populated via JOIN
+	GroupName string `json:"group_name,omitempty"`
+}
+
+// IsExpired reports whether the invitation can no longer be used
+func (i *Invitation) IsExpired(now time.Time) bool {
+	return now.After(i.ExpiresAt)
+}
+
+// JoinCode represents a group's shareable join code and its limits
+type JoinCode struct {
+	GroupID   int64      `json:"group_id"`
+	Code      *string    `json:"code,omitempty"`
+	Enabled   bool       `json:"enabled"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	MaxUses   *int       `json:"max_uses,omitempty"`
+	Uses      int        `json:"uses"`
+}
+
+// IsUsable reports whether the code can currently be used to join
+func (j *JoinCode) IsUsable(now time.Time) bool {
+	if j.Code == nil || !j.Enabled {
+		return false
+	}
+	if j.ExpiresAt != nil && now.After(*j.ExpiresAt) {
+		return false
+	}
+	if j.MaxUses != nil && j.Uses >= *j.MaxUses {
+		return false
+	}
+	return true
+}
+
+// UnsettledBalance summarizes a member's open splits within a group
+type UnsettledBalance struct {
+	SplitCount int     `json:"split_count"`
+	NetAmount  float64 `json:"net_amount"` // Positive = member owes others, Negative = others owe member
+}
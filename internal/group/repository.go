@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 )
 
 // Repository handles group data persistence
@@ -17,19 +18,32 @@ func NewRepository(db *sql.DB) *Repository {
 }
 
 // Create inserts a new group into the database
-func (r *Repository) Create(ctx context.Context, req *CreateGroupRequest) (*Group, error) {
+func (r *Repository) Create(ctx context.Context, ownerID int64, req *CreateGroupRequest) (*Group, error) {
 	query := `
-		INSERT INTO groups (name, description, is_temporary)
-		VALUES ($1, $2, $3)
-		RETURNING id, name, description, is_temporary, created_at
+		INSERT INTO groups (name, description, is_temporary, end_date, owner_id, only_admins_add_expenses, only_admins_invite)
+		VALUES ($1, $2, $3, $4, $5, COALESCE($6, FALSE), COALESCE($7, FALSE))
+		RETURNING id, name, description, is_temporary, end_date, archived_at, owner_id, only_admins_add_expenses, only_admins_invite, created_at
 	`
 
 	group := &Group{}
-	err := r.db.QueryRowContext(ctx, query, req.Name, req.Description, req.IsTemporary).Scan(
+	err := r.db.QueryRowContext(ctx, query,
+		req.Name,
+		req.Description,
+		req.IsTemporary,
+		req.EndDate,
+		ownerID,
+		req.OnlyAdminsAddExpenses,
+		req.OnlyAdminsInvite,
+	).Scan(
 		&group.ID,
 		&group.Name,
 		&group.Description,
 		&group.IsTemporary,
+		&group.EndDate,
+		&group.ArchivedAt,
+		&group.OwnerID,
+		&group.Settings.OnlyAdminsAddExpenses,
+		&group.Settings.OnlyAdminsInvite,
 		&group.CreatedAt,
 	)
 	if err != nil {
@@ -42,7 +56,7 @@ func (r *Repository) Create(ctx context.Context, req *CreateGroupRequest) (*Grou
 // GetByID retrieves a group by its ID
 func (r *Repository) GetByID(ctx context.Context, id int64) (*Group, error) {
 	query := `
-		SELECT id, name, description, is_temporary, created_at
+		SELECT id, name, description, is_temporary, end_date, archived_at, owner_id, only_admins_add_expenses, only_admins_invite, created_at
 		FROM groups
 		WHERE id = $1
 	`
@@ -53,6 +67,11 @@ func (r *Repository) GetByID(ctx context.Context, id int64) (*Group, error) {
 		&group.Name,
 		&group.Description,
 		&group.IsTemporary,
+		&group.EndDate,
+		&group.ArchivedAt,
+		&group.OwnerID,
+		&group.Settings.OnlyAdminsAddExpenses,
+		&group.Settings.OnlyAdminsInvite,
 		&group.CreatedAt,
 	)
 	if err != nil {
@@ -81,7 +100,7 @@ func (r *Repository) ListByUserID(ctx context.Context, userID int64, limit, offs
 
 	// Get groups
 	query := `
-		SELECT g.id, g.name, g.description, g.is_temporary, g.created_at
+		SELECT g.id, g.name, g.description, g.is_temporary, g.end_date, g.archived_at, g.owner_id, g.only_admins_add_expenses, g.only_admins_invite, g.created_at
 		FROM groups g
 		JOIN group_members gm ON g.id = gm.group_id
 		WHERE gm.user_id = $1
@@ -103,6 +122,11 @@ func (r *Repository) ListByUserID(ctx context.Context, userID int64, limit, offs
 			&group.Name,
 			&group.Description,
 			&group.IsTemporary,
+			&group.EndDate,
+			&group.ArchivedAt,
+			&group.OwnerID,
+			&group.Settings.OnlyAdminsAddExpenses,
+			&group.Settings.OnlyAdminsInvite,
 			&group.CreatedAt,
 		); err != nil {
 			return nil, 0, fmt.Errorf("failed to scan group: %w", err)
@@ -118,17 +142,32 @@ func (r *Repository) Update(ctx context.Context, id int64, req *UpdateGroupReque
 	query := `
 		UPDATE groups
 		SET name = COALESCE($2, name),
-		    description = COALESCE($3, description)
+		    description = COALESCE($3, description),
+		    end_date = COALESCE($4, end_date),
+		    only_admins_add_expenses = COALESCE($5, only_admins_add_expenses),
+		    only_admins_invite = COALESCE($6, only_admins_invite)
 		WHERE id = $1
-		RETURNING id, name, description, is_temporary, created_at
+		RETURNING id, name, description, is_temporary, end_date, archived_at, owner_id, only_admins_add_expenses, only_admins_invite, created_at
 	`
 
 	group := &Group{}
-	err := r.db.QueryRowContext(ctx, query, id, req.Name, req.Description).Scan(
+	err := r.db.QueryRowContext(ctx, query,
+		id,
+		req.Name,
+		req.Description,
+		req.EndDate,
+		req.OnlyAdminsAddExpenses,
+		req.OnlyAdminsInvite,
+	).Scan(
 		&group.ID,
 		&group.Name,
 		&group.Description,
 		&group.IsTemporary,
+		&group.EndDate,
+		&group.ArchivedAt,
+		&group.OwnerID,
+		&group.Settings.OnlyAdminsAddExpenses,
+		&group.Settings.OnlyAdminsInvite,
 		&group.CreatedAt,
 	)
 	if err != nil {
@@ -172,7 +211,7 @@ func (r *Repository) AddMember(ctx context.Context, groupID int64, req *AddMembe
 	query := `
 		INSERT INTO group_members (group_id, user_id, status, role)
 		VALUES ($1, $2, $3, $4)
-		RETURNING id, group_id, user_id, status, role, joined_at
+		RETURNING id, group_id, user_id, status, role, joined_at, left_at
 	`
 
 	member := &GroupMember{}
@@ -183,6 +222,7 @@ func (r *Repository) AddMember(ctx context.Context, groupID int64, req *AddMembe
 		&member.Status,
 		&member.Role,
 		&member.JoinedAt,
+		&member.LeftAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to add member: %w", err)
@@ -194,7 +234,7 @@ func (r *Repository) AddMember(ctx context.Context, groupID int64, req *AddMembe
 // GetMembers retrieves all members of a group
 func (r *Repository) GetMembers(ctx context.Context, groupID int64) ([]*GroupMember, error) {
 	query := `
-		SELECT gm.id, gm.group_id, gm.user_id, gm.status, gm.role, gm.joined_at, u.username, u.email
+		SELECT gm.id, gm.group_id, gm.user_id, gm.status, gm.role, gm.joined_at, gm.left_at, u.username, u.email
 		FROM group_members gm
 		JOIN users u ON gm.user_id = u.id
 		WHERE gm.group_id = $1
@@ -217,6 +257,7 @@ func (r *Repository) GetMembers(ctx context.Context, groupID int64) ([]*GroupMem
 			&member.Status,
 			&member.Role,
 			&member.JoinedAt,
+			&member.LeftAt,
 			&member.Username,
 			&member.Email,
 		); err != nil {
@@ -231,7 +272,7 @@ func (r *Repository) GetMembers(ctx context.Context, groupID int64) ([]*GroupMem
 // GetMember retrieves a specific member from a group
 func (r *Repository) GetMember(ctx context.Context, groupID, userID int64) (*GroupMember, error) {
 	query := `
-		SELECT gm.id, gm.group_id, gm.user_id, gm.status, gm.role, gm.joined_at, u.username, u.email
+		SELECT gm.id, gm.group_id, gm.user_id, gm.status, gm.role, gm.joined_at, gm.left_at, u.username, u.email
 		FROM group_members gm
 		JOIN users u ON gm.user_id = u.id
 		WHERE gm.group_id = $1 AND gm.user_id = $2
@@ -245,6 +286,7 @@ func (r *Repository) GetMember(ctx context.Context, groupID, userID int64) (*Gro
 		&member.Status,
 		&member.Role,
 		&member.JoinedAt,
+		&member.LeftAt,
 		&member.Username,
 		&member.Email,
 	)
@@ -258,14 +300,19 @@ func (r *Repository) GetMember(ctx context.Context, groupID, userID int64) (*Gro
 	return member, nil
 }
 
-// UpdateMember updates a member's status or role
+// UpdateMember updates a member's status or role.
+// left_at is stamped when a member moves to LEFT and cleared if they return.
 func (r *Repository) UpdateMember(ctx context.Context, groupID, userID int64, req *UpdateMemberRequest) (*GroupMember, error) {
 	query := `
 		UPDATE group_members
 		SET status = COALESCE($3, status),
-		    role = COALESCE($4, role)
+		    role = COALESCE($4, role),
+		    left_at = CASE
+		        WHEN COALESCE($3, status) = 'LEFT' THEN COALESCE(left_at, NOW())
+		        ELSE NULL
+		    END
 		WHERE group_id = $1 AND user_id = $2
-		RETURNING id, group_id, user_id, status, role, joined_at
+		RETURNING id, group_id, user_id, status, role, joined_at, left_at
 	`
 
 	member := &GroupMember{}
@@ -276,6 +323,7 @@ func (r *Repository) UpdateMember(ctx context.Context, groupID, userID int64, re
 		&member.Status,
 		&member.Role,
 		&member.JoinedAt,
+		&member.LeftAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -287,6 +335,30 @@ func (r *Repository) UpdateMember(ctx context.Context, groupID, userID int64, re
 	return member, nil
 }
 
+// SetOwner transfers ownership of a group
+func (r *Repository) SetOwner(ctx context.Context, groupID, ownerID int64) error {
+	query := `UPDATE groups SET owner_id = $2 WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, groupID, ownerID); err != nil {
+		return fmt.Errorf("failed to set group owner: %w", err)
+	}
+	return nil
+}
+
+// CountAdmins returns the number of joined admins in a group
+func (r *Repository) CountAdmins(ctx context.Context, groupID int64) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM group_members
+		WHERE group_id = $1 AND role = 'ADMIN' AND status = 'JOINED'
+	`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, groupID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count admins: %w", err)
+	}
+	return count, nil
+}
+
 // RemoveMember removes a user from a group
 func (r *Repository) RemoveMember(ctx context.Context, groupID, userID int64) error {
 	query := `DELETE FROM group_members WHERE group_id = $1 AND user_id = $2`
@@ -307,3 +379,436 @@ func (r *Repository) RemoveMember(ctx context.Context, groupID, userID int64) er
 
 	return nil
 }
+
+// GetUnsettledBalance summarizes a member's splits in a group that are not yet confirmed,
+// both as borrower and as the payer of the expense
+func (r *Repository) GetUnsettledBalance(ctx context.Context, groupID, userID int64) (*UnsettledBalance, error) {
+	query := `
+		SELECT COUNT(*),
+		       COALESCE(SUM(CASE WHEN s.borrower_id = $2 THEN s.amount_owed - s.amount_settled ELSE s.amount_settled - s.amount_owed END), 0)
+		FROM splits s
+		JOIN expenses e ON s.expense_id = e.id
+		WHERE e.group_id = $1
+		  AND (s.borrower_id = $2 OR e.payer_id = $2)
+		  AND s.borrower_id != e.payer_id
+		  AND s.status IN ('PENDING', 'PAID', 'DISPUTED')
+	`
+
+	balance := &UnsettledBalance{}
+	if err := r.db.QueryRowContext(ctx, query, groupID, userID).Scan(&balance.SplitCount, &balance.NetAmount); err != nil {
+		return nil, fmt.Errorf("failed to get unsettled balance: %w", err)
+	}
+
+	return balance, nil
+}
+
+// SetArchived archives or unarchives a group
+func (r *Repository) SetArchived(ctx context.Context, id int64, archived bool) (*Group, error) {
+	query := `
+		UPDATE groups
+		SET archived_at = CASE WHEN $2 THEN COALESCE(archived_at, NOW()) ELSE NULL END
+		WHERE id = $1
+		RETURNING id, name, description, is_temporary, end_date, archived_at, owner_id, only_admins_add_expenses, only_admins_invite, created_at
+	`
+
+	group := &Group{}
+	err := r.db.QueryRowContext(ctx, query, id, archived).Scan(
+		&group.ID,
+		&group.Name,
+		&group.Description,
+		&group.IsTemporary,
+		&group.EndDate,
+		&group.ArchivedAt,
+		&group.OwnerID,
+		&group.Settings.OnlyAdminsAddExpenses,
+		&group.Settings.OnlyAdminsInvite,
+		&group.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to update group archive state: %w", err)
+	}
+
+	return group, nil
+}
+
+// ListGroupsEndingSoon retrieves active temporary groups that end within the given
+// number of days and have not been sent a settle-up reminder yet
+func (r *Repository) ListGroupsEndingSoon(ctx context.Context, withinDays int) ([]*Group, error) {
+	query := `
+		SELECT id, name, description, is_temporary, end_date, archived_at, owner_id, only_admins_add_expenses, only_admins_invite, created_at
+		FROM groups
+		WHERE is_temporary
+		  AND archived_at IS NULL
+		  AND settle_reminder_sent_at IS NULL
+		  AND end_date IS NOT NULL
+		  AND end_date <= CURRENT_DATE + $1::int
+		ORDER BY end_date
+	`
+
+	return r.listGroups(ctx, query, withinDays)
+}
+
+// ListGroupsReadyToArchive retrieves temporary groups past their end date
+// where every split has been confirmed
+func (r *Repository) ListGroupsReadyToArchive(ctx context.Context) ([]*Group, error) {
+	query := `
+		SELECT g.id, g.name, g.description, g.is_temporary, g.end_date, g.archived_at, g.owner_id, g.only_admins_add_expenses, g.only_admins_invite, g.created_at
+		FROM groups g
+		WHERE g.is_temporary
+		  AND g.archived_at IS NULL
+		  AND g.end_date IS NOT NULL
+		  AND g.end_date < CURRENT_DATE
+		  AND NOT EXISTS (
+			SELECT 1
+			FROM splits s
+			JOIN expenses e ON s.expense_id = e.id
+			WHERE e.group_id = g.id
+			  AND s.borrower_id != e.payer_id
+			  AND s.status IN ('PENDING', 'PAID', 'DISPUTED')
+		  )
+		ORDER BY g.end_date
+	`
+
+	return r.listGroups(ctx, query)
+}
+
+// MarkSettleReminderSent records that members were reminded to settle up
+func (r *Repository) MarkSettleReminderSent(ctx context.Context, id int64) error {
+	query := `UPDATE groups SET settle_reminder_sent_at = NOW() WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to mark settle reminder sent: %w", err)
+	}
+	return nil
+}
+
+// listGroups runs a query returning full group rows
+func (r *Repository) listGroups(ctx context.Context, query string, args ...interface{}) ([]*Group, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []*Group
+	for rows.Next() {
+		group := &Group{}
+		if err := rows.Scan(
+			&group.ID,
+			&group.Name,
+			&group.Description,
+			&group.IsTemporary,
+			&group.EndDate,
+			&group.ArchivedAt,
+			&group.OwnerID,
+			&group.Settings.OnlyAdminsAddExpenses,
+			&group.Settings.OnlyAdminsInvite,
+			&group.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+// CreateInvitation inserts a new email invitation
+func (r *Repository) CreateInvitation(ctx context.Context, groupID, invitedBy int64, email string, role MemberRole, expiresAt time.Time) (*Invitation, error) {
+	query := `
+		INSERT INTO group_invitations (group_id, email, role, invited_by, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, group_id, email, role, invited_by, status, expires_at, responded_at, created_at
+	`
+
+	inv := &Invitation{}
+	err := r.db.QueryRowContext(ctx, query, groupID, email, role, invitedBy, InvitationStatusPending, expiresAt).Scan(
+		&inv.ID,
+		&inv.GroupID,
+		&inv.Email,
+		&inv.Role,
+		&inv.InvitedBy,
+		&inv.Status,
+		&inv.ExpiresAt,
+		&inv.RespondedAt,
+		&inv.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	return inv, nil
+}
+
+// GetInvitationByID retrieves an invitation by its ID
+func (r *Repository) GetInvitationByID(ctx context.Context, id int64) (*Invitation, error) {
+	query := `
+		SELECT i.id, i.group_id, i.email, i.role, i.invited_by, i.status, i.expires_at, i.responded_at, i.created_at, g.name
+		FROM group_invitations i
+		JOIN groups g ON i.group_id = g.id
+		WHERE i.id = $1
+	`
+
+	inv := &Invitation{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&inv.ID,
+		&inv.GroupID,
+		&inv.Email,
+		&inv.Role,
+		&inv.InvitedBy,
+		&inv.Status,
+		&inv.ExpiresAt,
+		&inv.RespondedAt,
+		&inv.CreatedAt,
+		&inv.GroupName,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	return inv, nil
+}
+
+// GetPendingInvitation retrieves the open invitation for an email in a group
+func (r *Repository) GetPendingInvitation(ctx context.Context, groupID int64, email string) (*Invitation, error) {
+	query := `
+		SELECT id, group_id, email, role, invited_by, status, expires_at, responded_at, created_at
+		FROM group_invitations
+		WHERE group_id = $1 AND LOWER(email) = LOWER($2) AND status = $3
+	`
+
+	inv := &Invitation{}
+	err := r.db.QueryRowContext(ctx, query, groupID, email, InvitationStatusPending).Scan(
+		&inv.ID,
+		&inv.GroupID,
+		&inv.Email,
+		&inv.Role,
+		&inv.InvitedBy,
+		&inv.Status,
+		&inv.ExpiresAt,
+		&inv.RespondedAt,
+		&inv.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get pending invitation: %w", err)
+	}
+
+	return inv, nil
+}
+
+// ListInvitations retrieves all email invitations for a group
+func (r *Repository) ListInvitations(ctx context.Context, groupID int64) ([]*Invitation, error) {
+	query := `
+		SELECT id, group_id, email, role, invited_by, status, expires_at, responded_at, created_at
+		FROM group_invitations
+		WHERE group_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+	defer rows.Close()
+
+	var invitations []*Invitation
+	for rows.Next() {
+		inv := &Invitation{}
+		if err := rows.Scan(
+			&inv.ID,
+			&inv.GroupID,
+			&inv.Email,
+			&inv.Role,
+			&inv.InvitedBy,
+			&inv.Status,
+			&inv.ExpiresAt,
+			&inv.RespondedAt,
+			&inv.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan invitation: %w", err)
+		}
+		invitations = append(invitations, inv)
+	}
+
+	return invitations, nil
+}
+
+// UpdateInvitationStatus records the outcome of an invitation
+func (r *Repository) UpdateInvitationStatus(ctx context.Context, id int64, status InvitationStatus) (*Invitation, error) {
+	query := `
+		UPDATE group_invitations
+		SET status = $2, responded_at = NOW()
+		WHERE id = $1
+		RETURNING id, group_id, email, role, invited_by, status, expires_at, responded_at, created_at
+	`
+
+	inv := &Invitation{}
+	err := r.db.QueryRowContext(ctx, query, id, status).Scan(
+		&inv.ID,
+		&inv.GroupID,
+		&inv.Email,
+		&inv.Role,
+		&inv.InvitedBy,
+		&inv.Status,
+		&inv.ExpiresAt,
+		&inv.RespondedAt,
+		&inv.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to update invitation status: %w", err)
+	}
+
+	return inv, nil
+}
+
+// GetJoinCode retrieves a group's join code settings
+func (r *Repository) GetJoinCode(ctx context.Context, groupID int64) (*JoinCode, error) {
+	query := `
+		SELECT id, join_code, join_code_enabled, join_code_expires_at, join_code_max_uses, join_code_uses
+		FROM groups
+		WHERE id = $1
+	`
+
+	jc := &JoinCode{}
+	err := r.db.QueryRowContext(ctx, query, groupID).Scan(
+		&jc.GroupID,
+		&jc.Code,
+		&jc.Enabled,
+		&jc.ExpiresAt,
+		&jc.MaxUses,
+		&jc.Uses,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get join code: %w", err)
+	}
+
+	return jc, nil
+}
+
+// GetJoinCodeByCode retrieves join code settings by the code itself
+func (r *Repository) GetJoinCodeByCode(ctx context.Context, code string) (*JoinCode, error) {
+	query := `
+		SELECT id, join_code, join_code_enabled, join_code_expires_at, join_code_max_uses, join_code_uses
+		FROM groups
+		WHERE join_code = $1
+	`
+
+	jc := &JoinCode{}
+	err := r.db.QueryRowContext(ctx, query, code).Scan(
+		&jc.GroupID,
+		&jc.Code,
+		&jc.Enabled,
+		&jc.ExpiresAt,
+		&jc.MaxUses,
+		&jc.Uses,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get join code: %w", err)
+	}
+
+	return jc, nil
+}
+
+// SetJoinCode replaces a group's join code, enabling it and resetting its use count
+func (r *Repository) SetJoinCode(ctx context.Context, groupID int64, code string, expiresAt *time.Time, maxUses *int) (*JoinCode, error) {
+	query := `
+		UPDATE groups
+		SET join_code = $2,
+		    join_code_enabled = TRUE,
+		    join_code_expires_at = $3,
+		    join_code_max_uses = $4,
+		    join_code_uses = 0
+		WHERE id = $1
+		RETURNING id, join_code, join_code_enabled, join_code_expires_at, join_code_max_uses, join_code_uses
+	`
+
+	jc := &JoinCode{}
+	err := r.db.QueryRowContext(ctx, query, groupID, code, expiresAt, maxUses).Scan(
+		&jc.GroupID,
+		&jc.Code,
+		&jc.Enabled,
+		&jc.ExpiresAt,
+		&jc.MaxUses,
+		&jc.Uses,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to set join code: %w", err)
+	}
+
+	return jc, nil
+}
+
+// UpdateJoinCode changes a join code's limits and enabled flag without rotating it
+func (r *Repository) UpdateJoinCode(ctx context.Context, groupID int64, enabled bool, expiresAt *time.Time, maxUses *int) (*JoinCode, error) {
+	query := `
+		UPDATE groups
+		SET join_code_enabled = $2,
+		    join_code_expires_at = $3,
+		    join_code_max_uses = $4
+		WHERE id = $1
+		RETURNING id, join_code, join_code_enabled, join_code_expires_at, join_code_max_uses, join_code_uses
+	`
+
+	jc := &JoinCode{}
+	err := r.db.QueryRowContext(ctx, query, groupID, enabled, expiresAt, maxUses).Scan(
+		&jc.GroupID,
+		&jc.Code,
+		&jc.Enabled,
+		&jc.ExpiresAt,
+		&jc.MaxUses,
+		&jc.Uses,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to update join code: %w", err)
+	}
+
+	return jc, nil
+}
+
+// ConsumeJoinCode atomically counts one use of a join code.
+// It returns false when the code is disabled, expired or used up.
+func (r *Repository) ConsumeJoinCode(ctx context.Context, code string) (bool, error) {
+	query := `
+		UPDATE groups
+		SET join_code_uses = join_code_uses + 1
+		WHERE join_code = $1
+		  AND join_code_enabled
+		  AND (join_code_expires_at IS NULL OR join_code_expires_at > NOW())
+		  AND (join_code_max_uses IS NULL OR join_code_uses < join_code_max_uses)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, code)
+	if err != nil {
+		return false, fmt.Errorf("failed to consume join code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
@@ -3,6 +3,13 @@ package group
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fkhayef/splitwise/internal/activity"
+	"github.com/fkhayef/splitwise/internal/user"
+	"github.com/fkhayef/splitwise/pkg/i18n"
 )
 
 // Common errors
@@ -11,22 +18,46 @@ var (
 	ErrMemberNotFound      = errors.New("member not found")
 	ErrMemberAlreadyExists = errors.New("user is already a member of this group")
 	ErrNotAuthorized       = errors.New("not authorized to perform this action")
+
+	ErrInvalidEmail             = errors.New("a valid email address is required")
+	ErrInvitationNotFound       = errors.New("invitation not found")
+	ErrInvitationNotPending     = errors.New("invitation is no longer pending")
+	ErrInvitationAlreadyPending = errors.New("an invitation is already pending for this email")
+	ErrInvalidJoinCode          = errors.New("join code is invalid, expired or no longer accepting members")
+	ErrMemberHasUnsettledSplits = errors.New("member has unsettled splits in this group")
+	ErrGroupArchived            = errors.New("group is archived and read-only")
+	ErrInvalidEndDate           = errors.New("end_date must be a YYYY-MM-DD date on a temporary group")
+	ErrLastAdmin                = errors.New("a group must keep at least one admin")
+	ErrOwnerMustTransfer        = errors.New("the group owner must transfer ownership first")
+	ErrStatusNotEditable        = errors.New("member status changes through accepting, leaving or removal")
 )
 
 // Service handles group business logic
 type Service struct {
-	repo *Repository
+	repo     *Repository
+	userRepo *user.Repository
+	activity *activity.Service
+	inviter  *Inviter
 }
 
 // NewService creates a new group service
-func NewService(repo *Repository) *Service {
-	return &Service{repo: repo}
+func NewService(repo *Repository, userRepo *user.Repository, activityService *activity.Service, inviter *Inviter) *Service {
+	return &Service{
+		repo:     repo,
+		userRepo: userRepo,
+		activity: activityService,
+		inviter:  inviter,
+	}
 }
 
 // Create creates a new group and adds the creator as admin
 func (s *Service) Create(ctx context.Context, creatorID int64, req *CreateGroupRequest) (*Group, error) {
+	if err := validateEndDate(req.EndDate, req.IsTemporary); err != nil {
+		return nil, err
+	}
+
 	// Create the group
-	group, err := s.repo.Create(ctx, req)
+	group, err := s.repo.Create(ctx, creatorID, req)
 	if err != nil {
 		return nil, err
 	}
@@ -49,6 +80,8 @@ func (s *Service) Create(ctx context.Context, creatorID int64, req *CreateGroupR
 		return nil, err
 	}
 
+	s.activity.Record(ctx, group.ID, creatorID, activity.TypeMemberJoined, activity.EntityGroup, group.ID, activity.Details{})
+
 	return group, nil
 }
 
@@ -92,33 +125,57 @@ func (s *Service) ListByUserID(ctx context.Context, userID int64, page, perPage
 	return s.repo.ListByUserID(ctx, userID, perPage, offset)
 }
 
-// Update modifies an existing group
-func (s *Service) Update(ctx context.Context, id int64, req *UpdateGroupRequest) (*Group, error) {
-	existing, err := s.repo.GetByID(ctx, id)
+// Update modifies an existing group's details and settings (admins only)
+func (s *Service) Update(ctx context.Context, id, actorID int64, req *UpdateGroupRequest) (*Group, error) {
+	if err := s.requireAdmin(ctx, id, actorID); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	if existing == nil {
-		return nil, ErrGroupNotFound
+	if existing.IsArchived() {
+		return nil, ErrGroupArchived
+	}
+	if err := validateEndDate(req.EndDate, existing.IsTemporary); err != nil {
+		return nil, err
 	}
 
 	return s.repo.Update(ctx, id, req)
 }
 
-// Delete removes a group
-func (s *Service) Delete(ctx context.Context, id int64) error {
+// Delete removes a group. Only the owner can delete it; if the owner's
+// account is gone, any admin can.
+func (s *Service) Delete(ctx context.Context, id, actorID int64) error {
+	group, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if group.OwnerID != nil {
+		if !group.IsOwner(actorID) {
+			return ErrNotAuthorized
+		}
+	} else if err := s.requireAdmin(ctx, id, actorID); err != nil {
+		return err
+	}
+
 	return s.repo.Delete(ctx, id)
 }
 
-// AddMember adds a user to a group
-func (s *Service) AddMember(ctx context.Context, groupID int64, req *AddMemberRequest) (*GroupMember, error) {
-	// Check if group exists
-	group, err := s.repo.GetByID(ctx, groupID)
+// AddMember invites a user to a group on behalf of an existing member
+func (s *Service) AddMember(ctx context.Context, groupID, actorID int64, req *AddMemberRequest) (*GroupMember, error) {
+	group, err := s.GetByID(ctx, groupID)
 	if err != nil {
 		return nil, err
 	}
-	if group == nil {
-		return nil, ErrGroupNotFound
+	if group.IsArchived() {
+		return nil, ErrGroupArchived
+	}
+
+	if _, err := s.requireInviter(ctx, group, actorID, req.Role); err != nil {
+		return nil, err
 	}
 
 	// Check if user is already a member
@@ -127,7 +184,19 @@ func (s *Service) AddMember(ctx context.Context, groupID int64, req *AddMemberRe
 		return nil, err
 	}
 	if existing != nil {
-		return nil, ErrMemberAlreadyExists
+		if existing.Status != MemberStatusLeft {
+			return nil, ErrMemberAlreadyExists
+		}
+
+		// Former members keep their row; invite them again
+		role := req.Role
+		if role == "" {
+			role = MemberRoleMember
+		}
+		return s.repo.UpdateMember(ctx, groupID, req.UserID, &UpdateMemberRequest{
+			Status: statusPtr(MemberStatusInvited),
+			Role:   &role,
+		})
 	}
 
 	return s.repo.AddMember(ctx, groupID, req)
@@ -147,25 +216,251 @@ func (s *Service) GetMembers(ctx context.Context, groupID int64) ([]*GroupMember
 	return s.repo.GetMembers(ctx, groupID)
 }
 
-// UpdateMember updates a member's status or role
-func (s *Service) UpdateMember(ctx context.Context, groupID, userID int64, req *UpdateMemberRequest) (*GroupMember, error) {
-	member, err := s.repo.UpdateMember(ctx, groupID, userID, req)
+// UpdateMember changes a member's role (admins only).
+// Status is driven by invitations, leaving and removal, so it can't be set here.
+func (s *Service) UpdateMember(ctx context.Context, groupID, userID, actorID int64, req *UpdateMemberRequest) (*GroupMember, error) {
+	if req.Status != nil {
+		return nil, ErrStatusNotEditable
+	}
+
+	if err := s.EnsureWritable(ctx, groupID); err != nil {
+		return nil, err
+	}
+	if err := s.requireAdmin(ctx, groupID, actorID); err != nil {
+		return nil, err
+	}
+
+	member, err := s.repo.GetMember(ctx, groupID, userID)
 	if err != nil {
 		return nil, err
 	}
-	if member == nil {
+	if member == nil || member.Status == MemberStatusLeft {
 		return nil, ErrMemberNotFound
 	}
-	return member, nil
+	if req.Role == nil || *req.Role == member.Role {
+		return member, nil
+	}
+
+	if *req.Role != MemberRoleAdmin {
+		if err := s.checkAdminCanStepDown(ctx, groupID, member); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := s.repo.UpdateMember(ctx, groupID, userID, &UpdateMemberRequest{Role: req.Role})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrMemberNotFound
+	}
+
+	if member.Status == MemberStatusJoined {
+		s.activity.Record(ctx, groupID, actorID, activity.TypeMemberRoleChanged, activity.EntityGroup, groupID, activity.Details{
+			Username: member.Username,
+			Role:     string(*req.Role),
+		})
+	}
+
+	return s.repo.GetMember(ctx, groupID, userID)
 }
 
-// RemoveMember removes a user from a group
-func (s *Service) RemoveMember(ctx context.Context, groupID, userID int64) error {
-	return s.repo.RemoveMember(ctx, groupID, userID)
+// TransferOwnership hands the group to another joined member, who becomes an admin.
+// The previous owner stays on as an admin.
+func (s *Service) TransferOwnership(ctx context.Context, groupID, actorID, newOwnerID int64) (*Group, error) {
+	group, err := s.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group.IsArchived() {
+		return nil, ErrGroupArchived
+	}
+
+	if group.OwnerID != nil {
+		if !group.IsOwner(actorID) {
+			return nil, ErrNotAuthorized
+		}
+	} else if err := s.requireAdmin(ctx, groupID, actorID); err != nil {
+		return nil, err
+	}
+
+	if group.IsOwner(newOwnerID) {
+		return group, nil
+	}
+
+	newOwner, err := s.repo.GetMember(ctx, groupID, newOwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if newOwner == nil || newOwner.Status != MemberStatusJoined {
+		return nil, ErrMemberNotFound
+	}
+
+	if newOwner.Role != MemberRoleAdmin {
+		role := MemberRoleAdmin
+		if _, err := s.repo.UpdateMember(ctx, groupID, newOwnerID, &UpdateMemberRequest{Role: &role}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.SetOwner(ctx, groupID, newOwnerID); err != nil {
+		return nil, err
+	}
+
+	s.activity.Record(ctx, groupID, actorID, activity.TypeOwnershipTransferred, activity.EntityGroup, groupID, activity.Details{
+		Username: newOwner.Username,
+	})
+
+	return s.GetByID(ctx, groupID)
+}
+
+// RemoveMember removes a user from a group (admins only).
+// Pending invitations are deleted outright. Joined members are marked LEFT so their
+// expenses and splits stay in the group's history; this is blocked while they have
+// unsettled splits unless force is set.
+func (s *Service) RemoveMember(ctx context.Context, groupID, userID, actorID int64, force bool) error {
+	if err := s.EnsureWritable(ctx, groupID); err != nil {
+		return err
+	}
+
+	if userID == actorID {
+		_, err := s.LeaveGroup(ctx, groupID, userID)
+		return err
+	}
+
+	if err := s.requireAdmin(ctx, groupID, actorID); err != nil {
+		return err
+	}
+
+	member, err := s.repo.GetMember(ctx, groupID, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil || member.Status == MemberStatusLeft {
+		return ErrMemberNotFound
+	}
+
+	if member.Status == MemberStatusInvited {
+		return s.repo.RemoveMember(ctx, groupID, userID)
+	}
+
+	if err := s.checkAdminCanStepDown(ctx, groupID, member); err != nil {
+		return err
+	}
+
+	if !force {
+		if err := s.checkSettled(ctx, groupID, userID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.repo.UpdateMember(ctx, groupID, userID, &UpdateMemberRequest{
+		Status: statusPtr(MemberStatusLeft),
+	}); err != nil {
+		return err
+	}
+
+	s.activity.Record(ctx, groupID, actorID, activity.TypeMemberRemoved, activity.EntityGroup, groupID, activity.Details{
+		Username: member.Username,
+	})
+
+	return nil
+}
+
+// LeaveGroup lets a member leave a group once all their splits in it are settled
+func (s *Service) LeaveGroup(ctx context.Context, groupID, userID int64) (*GroupMember, error) {
+	if err := s.EnsureWritable(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	member, err := s.repo.GetMember(ctx, groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil || member.Status == MemberStatusLeft {
+		return nil, ErrMemberNotFound
+	}
+
+	if err := s.checkAdminCanStepDown(ctx, groupID, member); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkSettled(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.UpdateMember(ctx, groupID, userID, &UpdateMemberRequest{
+		Status: statusPtr(MemberStatusLeft),
+	}); err != nil {
+		return nil, err
+	}
+
+	if member.Status == MemberStatusJoined {
+		s.activity.Record(ctx, groupID, userID, activity.TypeMemberLeft, activity.EntityGroup, groupID, activity.Details{})
+	}
+
+	return s.repo.GetMember(ctx, groupID, userID)
+}
+
+// checkAdminCanStepDown stops the owner, or the last admin of a group that still
+// has other members, from losing admin rights or leaving
+func (s *Service) checkAdminCanStepDown(ctx context.Context, groupID int64, member *GroupMember) error {
+	if member.Status != MemberStatusJoined || member.Role != MemberRoleAdmin {
+		return nil
+	}
+
+	group, err := s.GetByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	members, err := s.repo.GetMembers(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	others := 0
+	for _, m := range members {
+		if m.UserID != member.UserID && m.Status == MemberStatusJoined {
+			others++
+		}
+	}
+	// The last member can always leave
+	if others == 0 {
+		return nil
+	}
+
+	if group.IsOwner(member.UserID) {
+		return ErrOwnerMustTransfer
+	}
+
+	admins, err := s.repo.CountAdmins(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if admins <= 1 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+// checkSettled returns ErrMemberHasUnsettledSplits if the user still has open splits in the group
+func (s *Service) checkSettled(ctx context.Context, groupID, userID int64) error {
+	balance, err := s.repo.GetUnsettledBalance(ctx, groupID, userID)
+	if err != nil {
+		return err
+	}
+	if balance.SplitCount > 0 {
+		return fmt.Errorf("%w: %d open splits, net %.2f", ErrMemberHasUnsettledSplits, balance.SplitCount, balance.NetAmount)
+	}
+	return nil
 }
 
 // AcceptInvitation allows a user to accept their group invitation
 func (s *Service) AcceptInvitation(ctx context.Context, groupID, userID int64) (*GroupMember, error) {
+	if err := s.EnsureWritable(ctx, groupID); err != nil {
+		return nil, err
+	}
+
 	// Check if user is a member with INVITED status
 	member, err := s.repo.GetMember(ctx, groupID, userID)
 	if err != nil {
@@ -178,9 +473,557 @@ func (s *Service) AcceptInvitation(ctx context.Context, groupID, userID int64) (
 		return member, nil // Already joined
 	}
 
-	return s.repo.UpdateMember(ctx, groupID, userID, &UpdateMemberRequest{
+	member, err = s.repo.UpdateMember(ctx, groupID, userID, &UpdateMemberRequest{
 		Status: statusPtr(MemberStatusJoined),
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.activity.Record(ctx, groupID, userID, activity.TypeMemberJoined, activity.EntityGroup, groupID, activity.Details{})
+
+	return member, nil
+}
+
+// ListActivity retrieves the activity feed for a group
+func (s *Service) ListActivity(ctx context.Context, groupID int64, page, perPage int) ([]*activity.Activity, int, error) {
+	// Check if group exists
+	group, err := s.repo.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if group == nil {
+		return nil, 0, ErrGroupNotFound
+	}
+
+	return s.activity.ListByGroupID(ctx, groupID, page, perPage)
+}
+
+// DeclineInvitation lets an invited user turn down a membership invitation
+func (s *Service) DeclineInvitation(ctx context.Context, groupID, userID int64) error {
+	member, err := s.repo.GetMember(ctx, groupID, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return ErrMemberNotFound
+	}
+	if member.Status != MemberStatusInvited {
+		return ErrInvitationNotPending
+	}
+
+	return s.repo.RemoveMember(ctx, groupID, userID)
+}
+
+// InviteByEmail invites someone to a group by email address.
+// The invitee receives a signed link and does not need an account yet.
+func (s *Service) InviteByEmail(ctx context.Context, groupID, inviterID int64, req *InviteByEmailRequest) (*Invitation, error) {
+	group, err := s.repo.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, ErrGroupNotFound
+	}
+	if group.IsArchived() {
+		return nil, ErrGroupArchived
+	}
+
+	inviter, err := s.requireInviter(ctx, group, inviterID, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	role := req.Role
+	if role == "" {
+		role = MemberRoleMember
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if !strings.Contains(email, "@") {
+		return nil, ErrInvalidEmail
+	}
+
+	// Check if the email already belongs to a member
+	existingUser, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if existingUser != nil {
+		member, err := s.repo.GetMember(ctx, groupID, existingUser.ID)
+		if err != nil {
+			return nil, err
+		}
+		if member != nil && member.Status != MemberStatusLeft {
+			return nil, ErrMemberAlreadyExists
+		}
+	}
+
+	// Only one open invitation per email; expired ones are cleared out
+	now := time.Now()
+	pending, err := s.repo.GetPendingInvitation(ctx, groupID, email)
+	if err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		if !pending.IsExpired(now) {
+			return nil, ErrInvitationAlreadyPending
+		}
+		if _, err := s.repo.UpdateInvitationStatus(ctx, pending.ID, InvitationStatusRevoked); err != nil {
+			return nil, err
+		}
+	}
+
+	inv, err := s.repo.CreateInvitation(ctx, groupID, inviterID, email, role, s.inviter.ExpiresAt(now))
+	if err != nil {
+		return nil, err
+	}
+	inv.GroupName = group.Name
+
+	// Write in the invitee's language if they have an account, otherwise in the inviter's
+	lang := i18n.Language(ctx)
+	if existingUser != nil {
+		if userLang, err := i18n.Normalize(existingUser.Language); err == nil {
+			lang = userLang
+		}
+	}
+	if err := s.inviter.SendInvitation(ctx, inv, group.Name, inviter.Username, lang); err != nil {
+		// Revoke so the invitation can be retried
+		s.repo.UpdateInvitationStatus(ctx, inv.ID, InvitationStatusRevoked)
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+// ListInvitations retrieves all email invitations for a group
+func (s *Service) ListInvitations(ctx context.Context, groupID int64) ([]*Invitation, error) {
+	group, err := s.repo.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, ErrGroupNotFound
+	}
+
+	return s.repo.ListInvitations(ctx, groupID)
+}
+
+// GetInvitationByToken resolves an invitation link to its invitation
+func (s *Service) GetInvitationByToken(ctx context.Context, token string) (*Invitation, error) {
+	id, err := s.inviter.Verify(token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	inv, err := s.repo.GetInvitationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if inv == nil {
+		return nil, ErrInvitationNotFound
+	}
+	return inv, nil
+}
+
+// AcceptInvitationByToken accepts an email invitation.
+// The invitee's account is looked up by the invited email and created if missing.
+func (s *Service) AcceptInvitationByToken(ctx context.Context, token string, req *AcceptInviteTokenRequest) (*GroupMember, error) {
+	inv, err := s.GetInvitationByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if inv.Status != InvitationStatusPending {
+		return nil, ErrInvitationNotPending
+	}
+	if inv.IsExpired(time.Now()) {
+		return nil, ErrInvitationExpired
+	}
+	if err := s.EnsureWritable(ctx, inv.GroupID); err != nil {
+		return nil, err
+	}
+
+	// Link to an existing account, or create one for the invited email
+	invitee, err := s.userRepo.GetByEmail(ctx, inv.Email)
+	if err != nil {
+		return nil, err
+	}
+	if invitee == nil {
+		username := usernameFromEmail(inv.Email)
+		if req.Username != nil && *req.Username != "" {
+			username = *req.Username
+		}
+		invitee, err = s.userRepo.Create(ctx, &user.CreateUserRequest{
+			Username: username,
+			Email:    inv.Email,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	member, err := s.repo.GetMember(ctx, inv.GroupID, invitee.ID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		if _, err := s.repo.AddMember(ctx, inv.GroupID, &AddMemberRequest{
+			UserID: invitee.ID,
+			Role:   inv.Role,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if member == nil || member.Status != MemberStatusJoined {
+		// A member who had left comes back with the role they were invited with
+		if _, err := s.repo.UpdateMember(ctx, inv.GroupID, invitee.ID, &UpdateMemberRequest{
+			Status: statusPtr(MemberStatusJoined),
+			Role:   &inv.Role,
+		}); err != nil {
+			return nil, err
+		}
+		s.activity.Record(ctx, inv.GroupID, invitee.ID, activity.TypeMemberJoined, activity.EntityGroup, inv.GroupID, activity.Details{})
+	}
+
+	if _, err := s.repo.UpdateInvitationStatus(ctx, inv.ID, InvitationStatusAccepted); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetMember(ctx, inv.GroupID, invitee.ID)
+}
+
+// DeclineInvitationByToken declines an email invitation
+func (s *Service) DeclineInvitationByToken(ctx context.Context, token string) (*Invitation, error) {
+	inv, err := s.GetInvitationByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if inv.Status != InvitationStatusPending {
+		return nil, ErrInvitationNotPending
+	}
+
+	return s.repo.UpdateInvitationStatus(ctx, inv.ID, InvitationStatusDeclined)
+}
+
+// RevokeInvitation cancels a pending email invitation.
+// Only the member who sent it or a group admin can revoke.
+func (s *Service) RevokeInvitation(ctx context.Context, groupID, invitationID, actorID int64) (*Invitation, error) {
+	inv, err := s.repo.GetInvitationByID(ctx, invitationID)
+	if err != nil {
+		return nil, err
+	}
+	if inv == nil || inv.GroupID != groupID {
+		return nil, ErrInvitationNotFound
+	}
+
+	if inv.InvitedBy != actorID {
+		actor, err := s.repo.GetMember(ctx, groupID, actorID)
+		if err != nil {
+			return nil, err
+		}
+		if actor == nil || actor.Role != MemberRoleAdmin {
+			return nil, ErrNotAuthorized
+		}
+	}
+
+	if inv.Status != InvitationStatusPending {
+		return nil, ErrInvitationNotPending
+	}
+
+	return s.repo.UpdateInvitationStatus(ctx, invitationID, InvitationStatusRevoked)
+}
+
+// GetJoinCode returns a group's join code settings (admins only)
+func (s *Service) GetJoinCode(ctx context.Context, groupID, actorID int64) (*JoinCode, error) {
+	if err := s.requireAdmin(ctx, groupID, actorID); err != nil {
+		return nil, err
+	}
+
+	jc, err := s.repo.GetJoinCode(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if jc == nil {
+		return nil, ErrGroupNotFound
+	}
+	return jc, nil
+}
+
+// GenerateJoinCode creates a new join code for a group, replacing any existing one
+func (s *Service) GenerateJoinCode(ctx context.Context, groupID, actorID int64, req *JoinCodeRequest) (*JoinCode, error) {
+	if err := s.requireAdmin(ctx, groupID, actorID); err != nil {
+		return nil, err
+	}
+	if err := s.EnsureWritable(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	code, err := generateJoinCode()
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt, maxUses := joinCodeLimits(req, nil, nil)
+	jc, err := s.repo.SetJoinCode(ctx, groupID, code, expiresAt, maxUses)
+	if err != nil {
+		return nil, err
+	}
+	if jc == nil {
+		return nil, ErrGroupNotFound
+	}
+	return jc, nil
+}
+
+// UpdateJoinCode changes a join code's limits or enabled flag without rotating it
+func (s *Service) UpdateJoinCode(ctx context.Context, groupID, actorID int64, req *JoinCodeRequest) (*JoinCode, error) {
+	current, err := s.GetJoinCode(ctx, groupID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if current.Code == nil {
+		return nil, ErrInvalidJoinCode
+	}
+
+	enabled := current.Enabled
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	expiresAt, maxUses := joinCodeLimits(req, current.ExpiresAt, current.MaxUses)
+
+	return s.repo.UpdateJoinCode(ctx, groupID, enabled, expiresAt, maxUses)
+}
+
+// DisableJoinCode stops a group's join code from being used
+func (s *Service) DisableJoinCode(ctx context.Context, groupID, actorID int64) (*JoinCode, error) {
+	disabled := false
+	return s.UpdateJoinCode(ctx, groupID, actorID, &JoinCodeRequest{Enabled: &disabled})
+}
+
+// JoinByCode adds the caller to the group owning the code as a JOINED member
+func (s *Service) JoinByCode(ctx context.Context, code string, userID int64) (*GroupMember, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	jc, err := s.repo.GetJoinCodeByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if jc == nil || !jc.IsUsable(time.Now()) {
+		return nil, ErrInvalidJoinCode
+	}
+	if err := s.EnsureWritable(ctx, jc.GroupID); err != nil {
+		return nil, err
+	}
+
+	member, err := s.repo.GetMember(ctx, jc.GroupID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member != nil && member.Status == MemberStatusJoined {
+		return nil, ErrMemberAlreadyExists
+	}
+
+	// Counting the use is atomic so max_uses can't be exceeded by concurrent joins
+	ok, err := s.repo.ConsumeJoinCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidJoinCode
+	}
+
+	if member == nil {
+		if _, err := s.repo.AddMember(ctx, jc.GroupID, &AddMemberRequest{UserID: userID}); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := s.repo.UpdateMember(ctx, jc.GroupID, userID, &UpdateMemberRequest{
+		Status: statusPtr(MemberStatusJoined),
+	}); err != nil {
+		return nil, err
+	}
+
+	s.activity.Record(ctx, jc.GroupID, userID, activity.TypeMemberJoined, activity.EntityGroup, jc.GroupID, activity.Details{})
+
+	return s.repo.GetMember(ctx, jc.GroupID, userID)
+}
+
+// JoinLink returns the shareable link for a join code
+func (s *Service) JoinLink(code string) string {
+	return s.inviter.JoinLink(code)
+}
+
+// Archive makes a group read-only while keeping it visible in history (admins only)
+func (s *Service) Archive(ctx context.Context, groupID, actorID int64) (*Group, error) {
+	return s.setArchived(ctx, groupID, actorID, true)
+}
+
+// Unarchive makes an archived group writable again (admins only)
+func (s *Service) Unarchive(ctx context.Context, groupID, actorID int64) (*Group, error) {
+	return s.setArchived(ctx, groupID, actorID, false)
+}
+
+// setArchived changes a group's archive state and records it in the feed.
+// An actorID of 0 is used for automatic archiving and skips the admin check.
+func (s *Service) setArchived(ctx context.Context, groupID, actorID int64, archived bool) (*Group, error) {
+	if actorID != 0 {
+		if err := s.requireAdmin(ctx, groupID, actorID); err != nil {
+			return nil, err
+		}
+	}
+
+	group, err := s.repo.SetArchived(ctx, groupID, archived)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, ErrGroupNotFound
+	}
+
+	activityType := activity.TypeGroupArchived
+	if !archived {
+		activityType = activity.TypeGroupUnarchived
+	}
+	s.activity.Record(ctx, groupID, actorID, activityType, activity.EntityGroup, groupID, activity.Details{})
+
+	return group, nil
+}
+
+// EnsureWritable returns ErrGroupArchived if the group is archived.
+// Other features call this before changing data that belongs to a group.
+func (s *Service) EnsureWritable(ctx context.Context, groupID int64) error {
+	group, err := s.GetByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if group.IsArchived() {
+		return ErrGroupArchived
+	}
+	return nil
+}
+
+// IsAdmin reports whether the user is a joined admin of the group
+func (s *Service) IsAdmin(ctx context.Context, groupID, userID int64) (bool, error) {
+	err := s.requireAdmin(ctx, groupID, userID)
+	if errors.Is(err, ErrNotAuthorized) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// IsJoinedMember reports whether the user is a joined member of the group
+func (s *Service) IsJoinedMember(ctx context.Context, groupID, userID int64) (bool, error) {
+	member, err := s.repo.GetMember(ctx, groupID, userID)
+	if err != nil {
+		return false, err
+	}
+	return member != nil && member.Status == MemberStatusJoined, nil
+}
+
+// CanAddExpense checks that the user may add expenses to the group: it must not be
+// archived, the user must be a joined member, and an admin if the group requires it
+func (s *Service) CanAddExpense(ctx context.Context, groupID, userID int64) error {
+	group, err := s.GetByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if group.IsArchived() {
+		return ErrGroupArchived
+	}
+
+	member, err := s.repo.GetMember(ctx, groupID, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil || member.Status != MemberStatusJoined {
+		return ErrNotAuthorized
+	}
+	if group.Settings.OnlyAdminsAddExpenses && member.Role != MemberRoleAdmin {
+		return ErrNotAuthorized
+	}
+	return nil
+}
+
+// requireInviter checks that the user may bring new people into the group:
+// joined members can unless the group restricts it to admins, and only
+// admins can invite other admins. It returns the inviting member.
+func (s *Service) requireInviter(ctx context.Context, group *Group, userID int64, role MemberRole) (*GroupMember, error) {
+	member, err := s.repo.GetMember(ctx, group.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil || member.Status != MemberStatusJoined {
+		return nil, ErrNotAuthorized
+	}
+
+	if member.Role != MemberRoleAdmin && (group.Settings.OnlyAdminsInvite || role == MemberRoleAdmin) {
+		return nil, ErrNotAuthorized
+	}
+	return member, nil
+}
+
+// requireAdmin checks that the user is an admin of the group
+func (s *Service) requireAdmin(ctx context.Context, groupID, userID int64) error {
+	group, err := s.repo.GetByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if group == nil {
+		return ErrGroupNotFound
+	}
+
+	member, err := s.repo.GetMember(ctx, groupID, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil || member.Status != MemberStatusJoined || member.Role != MemberRoleAdmin {
+		return ErrNotAuthorized
+	}
+	return nil
+}
+
+// joinCodeLimits applies the optional limits in a request on top of the current ones.
+// A value of 0 removes the limit.
+func joinCodeLimits(req *JoinCodeRequest, expiresAt *time.Time, maxUses *int) (*time.Time, *int) {
+	if req.ExpiresInHours != nil {
+		expiresAt = nil
+		if *req.ExpiresInHours > 0 {
+			t := time.Now().Add(time.Duration(*req.ExpiresInHours) * time.Hour)
+			expiresAt = &t
+		}
+	}
+	if req.MaxUses != nil {
+		maxUses = nil
+		if *req.MaxUses > 0 {
+			n := *req.MaxUses
+			maxUses = &n
+		}
+	}
+	return expiresAt, maxUses
+}
+
+// validateEndDate checks that an optional end date is a valid date on a temporary group
+func validateEndDate(endDate *string, isTemporary bool) error {
+	if endDate == nil {
+		return nil
+	}
+	if !isTemporary {
+		return ErrInvalidEndDate
+	}
+	if _, err := time.Parse("2006-01-02", *endDate); err != nil {
+		return ErrInvalidEndDate
+	}
+	return nil
+}
+
+// usernameFromEmail derives a default username from an email's local part
+func usernameFromEmail(email string) string {
+	local, _, _ := strings.Cut(email, "@")
+	if len(local) < 3 {
+		local += "_user"
+	}
+	if len(local) > 50 {
+		local = local[:50]
+	}
+	return local
 }
 
 // Helper function to get a pointer to a MemberStatus
@@ -0,0 +1,200 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// ErrInvalidBankCSV is returned when a CSV statement lacks the columns we need
+var ErrInvalidBankCSV = errors.New("CSV statement needs a date column, a description column and either an amount column or debit/credit columns")
+
+// bankCSVColumns lists the header names banks commonly use for each field
+var bankCSVColumns = map[string][]string{
+	"date":        {"date", "posted date", "posting date", "transaction date", "booking date", "value date"},
+	"description": {"description", "payee", "name", "merchant", "details", "narrative", "transaction description", "memo"},
+	"amount":      {"amount", "transaction amount"},
+	"debit":       {"debit", "debit amount", "withdrawal", "withdrawals", "money out", "paid out"},
+	"credit":      {"credit", "credit amount", "deposit", "deposits", "money in", "paid in"},
+	"category":    {"category"},
+	"id":          {"id", "transaction id", "reference", "fitid"},
+	"currency":    {"currency"},
+}
+
+// bankCSVDateLayouts are tried in order; the first that parses every date
+// in the file wins, which settles day/month ambiguity for most statements
+var bankCSVDateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"01/02/2006",
+	"02/01/2006",
+	"1/2/2006",
+	"2/1/2006",
+	"2006/01/02",
+	"02.01.2006",
+	"01-02-2006",
+	"02-01-2006",
+	"2 Jan 2006",
+	"Jan 2, 2006",
+	"01/02/06",
+	"02/01/06",
+}
+
+// ParseBankCSV reads a generic CSV bank statement with a header row.
+// Columns are found by name (see bankCSVColumns); amounts are either a single
+// signed column or separate debit and credit columns.
+func ParseBankCSV(data []byte) (*Statement, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrInvalidBankCSV
+		}
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	cols := bankCSVColumnIndexes(header)
+	if cols["date"] < 0 || cols["description"] < 0 || (cols["amount"] < 0 && cols["debit"] < 0 && cols["credit"] < 0) {
+		return nil, ErrInvalidBankCSV
+	}
+
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	field := func(record []string, name string) string {
+		i := cols[name]
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var dates []string
+	for _, record := range records {
+		dates = append(dates, field(record, "date"))
+	}
+	layout, err := chooseDateLayout(dates)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &Statement{Format: StatementFormatCSV}
+	for i, record := range records {
+		line := i + 2 // 1-based, after the header
+
+		posted, _ := time.Parse(layout, field(record, "date"))
+		amount, err := bankCSVAmount(field(record, "amount"), field(record, "debit"), field(record, "credit"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if currency := field(record, "currency"); currency != "" && stmt.CurrencyCode == "" {
+			stmt.CurrencyCode = strings.ToUpper(currency)
+		}
+
+		stmt.Transactions = append(stmt.Transactions, &StatementTransaction{
+			ExternalID:  field(record, "id"),
+			PostedAt:    posted,
+			Description: field(record, "description"),
+			Amount:      amount,
+			Category:    field(record, "category"),
+		})
+	}
+
+	return stmt, nil
+}
+
+// bankCSVColumnIndexes maps each known field to its column, or -1 if absent
+func bankCSVColumnIndexes(header []string) map[string]int {
+	cols := make(map[string]int, len(bankCSVColumns))
+	for name, aliases := range bankCSVColumns {
+		cols[name] = -1
+		for _, alias := range aliases {
+			for i, h := range header {
+				if strings.EqualFold(strings.TrimSpace(h), alias) {
+					cols[name] = i
+					break
+				}
+			}
+			if cols[name] >= 0 {
+				break
+			}
+		}
+	}
+	return cols
+}
+
+// chooseDateLayout returns the first layout that parses every date
+func chooseDateLayout(dates []string) (string, error) {
+	for _, layout := range bankCSVDateLayouts {
+		ok := true
+		for _, d := range dates {
+			if _, err := time.Parse(layout, d); err != nil {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return layout, nil
+		}
+	}
+	return "", errors.New("could not recognise the date format of the statement")
+}
+
+// bankCSVAmount returns the signed amount of a row: a single amount column as-is,
+// or a debit (money out, made negative) or credit (money in) column
+func bankCSVAmount(amount, debit, credit string) (float64, error) {
+	if amount != "" {
+		return parseStatementAmount(amount)
+	}
+	if debit != "" {
+		v, err := parseStatementAmount(debit)
+		return -math.Abs(v), err
+	}
+	if credit != "" {
+		v, err := parseStatementAmount(credit)
+		return math.Abs(v), err
+	}
+	return 0, errors.New("row has no amount")
+}
+
+// parseStatementAmount parses amounts such as "$1,234.50", "-12.00" or "(12.00)"
+func parseStatementAmount(s string) (float64, error) {
+	negative := strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")")
+	cleaned := strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || r == '.' || r == ',' || r == '-' {
+			return r
+		}
+		return -1
+	}, s)
+
+	v, err := parseAmount(cleaned)
+	if err != nil {
+		return 0, err
+	}
+	if negative {
+		v = -math.Abs(v)
+	}
+	return v, nil
+}
@@ -0,0 +1,149 @@
+package importer
+
+import "github.com/fkhayef/splitwise/internal/expense"
+
+// RowStatus is the outcome of importing a single row
+type RowStatus string
+
+const (
+	RowStatusCreated          RowStatus = "CREATED"
+	RowStatusWouldCreate      RowStatus = "WOULD_CREATE" // Dry run: the row is valid
+	RowStatusSkipped          RowStatus = "SKIPPED"
+	RowStatusSkippedDuplicate RowStatus = "SKIPPED_DUPLICATE" // Imported into the group before
+	RowStatusFailed           RowStatus = "FAILED"
+)
+
+// MemberMapping shows which group member a name in the file was matched to
+type MemberMapping struct {
+	Name     string `json:"name"`
+	UserID   int64  `json:"user_id,omitempty"` // 0 if the name couldn't be matched
+	Username string `json:"username,omitempty"`
+}
+
+// RowShare is one member's share of an imported expense
+type RowShare struct {
+	UserID int64   `json:"user_id"`
+	Amount float64 `json:"amount"`
+}
+
+// RowResult reports what happened to one row of the file
+type RowResult struct {
+	Line        int         `json:"line"`
+	Date        string      `json:"date,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Amount      float64     `json:"amount,omitempty"`
+	PayerID     int64       `json:"payer_id,omitempty"`
+	Shares      []*RowShare `json:"shares,omitempty"`
+	Status      RowStatus   `json:"status"`
+	ExpenseID   int64       `json:"expense_id,omitempty"` // The expense created, or imported before
+	Reason      string      `json:"reason,omitempty"`     // Why the row was skipped or failed
+}
+
+// ImportReport summarises an import
+type ImportReport struct {
+	GroupID  int64            `json:"group_id"`
+	DryRun   bool             `json:"dry_run"`
+	Members  []*MemberMapping `json:"members"`
+	Rows     []*RowResult     `json:"rows"`
+	Created  int              `json:"created"`
+	Valid    int              `json:"valid"` // Rows that were or would be created
+	Skipped  int              `json:"skipped"`
+	Failed   int              `json:"failed"`
+	Warnings []string         `json:"warnings,omitempty"`
+}
+
+// add appends a row result and updates the counts
+func (r *ImportReport) add(row *RowResult) {
+	r.Rows = append(r.Rows, row)
+	switch row.Status {
+	case RowStatusCreated:
+		r.Created++
+		r.Valid++
+	case RowStatusWouldCreate:
+		r.Valid++
+	case RowStatusSkipped, RowStatusSkippedDuplicate:
+		r.Skipped++
+	case RowStatusFailed:
+		r.Failed++
+	}
+}
+
+// ConfirmBatchRequest represents the request to turn a statement's transactions into expenses.
+// The same split rule is applied to every expense.
+type ConfirmBatchRequest struct {
+	GroupID        int64                       `json:"group_id" validate:"required"`
+	TransactionIDs []int64                     `json:"transaction_ids,omitempty"` // Defaults to every PROPOSED transaction
+	SplitType      string                      `json:"split_type,omitempty"`      // EVEN (default) or PERCENTAGE
+	Participants   []*expense.SplitParticipant `json:"participants,omitempty"`    // Defaults to every group member, evenly
+	Category       *string                     `json:"category,omitempty"`        // For transactions without a category
+}
+
+// BatchResponse represents a bank statement import in API responses
+type BatchResponse struct {
+	ID           int64                      `json:"id"`
+	Format       StatementFormat            `json:"format"`
+	Filename     *string                    `json:"filename,omitempty"`
+	CurrencyCode *string                    `json:"currency_code,omitempty"`
+	Status       BatchStatus                `json:"status"`
+	GroupID      *int64                     `json:"group_id,omitempty"`
+	Counts       map[TransactionStatus]int  `json:"counts,omitempty"`
+	Transactions []*BankTransactionResponse `json:"transactions,omitempty"`
+	CreatedAt    string                     `json:"created_at"`
+	CompletedAt  *string                    `json:"completed_at,omitempty"`
+}
+
+// BankTransactionResponse represents a statement transaction in API responses
+type BankTransactionResponse struct {
+	ID             int64             `json:"id"`
+	ExternalID     string            `json:"external_id"`
+	Date           string            `json:"date"`
+	Description    string            `json:"description"`
+	Amount         float64           `json:"amount"`
+	Category       *string           `json:"category,omitempty"`
+	Status         TransactionStatus `json:"status"`
+	Reason         *string           `json:"reason,omitempty"`
+	MatchExpenseID *int64            `json:"match_expense_id,omitempty"`
+	MatchScore     *float64          `json:"match_score,omitempty"`
+	ExpenseID      *int64            `json:"expense_id,omitempty"`
+}
+
+// ToResponse converts a Batch model to a BatchResponse DTO
+func (b *Batch) ToResponse() *BatchResponse {
+	resp := &BatchResponse{
+		ID:           b.ID,
+		Format:       b.Format,
+		Filename:     b.Filename,
+		CurrencyCode: b.CurrencyCode,
+		Status:       b.Status,
+		GroupID:      b.GroupID,
+		Counts:       map[TransactionStatus]int{},
+		CreatedAt:    b.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+	if b.CompletedAt != nil {
+		completed := b.CompletedAt.Format("2006-01-02T15:04:05Z")
+		resp.CompletedAt = &completed
+	}
+
+	for _, t := range b.Transactions {
+		resp.Counts[t.Status]++
+		resp.Transactions = append(resp.Transactions, t.ToResponse())
+	}
+	return resp
+}
+
+// ToResponse converts a BankTransaction model to a BankTransactionResponse DTO
+func (t *BankTransaction) ToResponse() *BankTransactionResponse {
+	return &BankTransactionResponse{
+		ID:             t.ID,
+		ExternalID:     t.ExternalID,
+		Date:           t.PostedAt.Format("2006-01-02"),
+		Description:    t.Description,
+		Amount:         t.Amount,
+		Category:       t.Category,
+		Status:         t.Status,
+		Reason:         t.Reason,
+		MatchExpenseID: t.MatchExpenseID,
+		MatchScore:     t.MatchScore,
+		ExpenseID:      t.ExpenseID,
+	}
+}
@@ -0,0 +1,301 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/fkhayef/splitwise/internal/group"
+	"github.com/fkhayef/splitwise/pkg/middleware"
+	"github.com/fkhayef/splitwise/pkg/response"
+)
+
+// maxUploadSize caps the size of an uploaded import file
+const maxUploadSize = 10 << 20
+
+// Handler handles HTTP requests for imports
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new importer handler with service dependency injected
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes returns the router for bank statement import endpoints
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Post("/bank", h.ImportStatement)
+	r.Get("/bank", h.ListBatches)
+	r.Get("/bank/{batchId}", h.GetBatch)
+	r.Post("/bank/{batchId}/confirm", h.ConfirmBatch)
+	r.Delete("/bank/{batchId}", h.DiscardBatch)
+
+	return r
+}
+
+// ImportSplitwise handles POST /groups/{id}/import/splitwise?dry_run=true.
+// The CSV is sent either as the "file" field of a multipart form, with an
+// optional "members" field holding a JSON object of name -> user ID, or as
+// the raw request body. It is mounted on the groups router by main.
+func (h *Handler) ImportSplitwise(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid group ID")
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+
+	file, _, mapping, err := readUpload(w, r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	defer file.Close()
+
+	report, err := h.service.ImportSplitwise(r.Context(), groupID, userID, file, mapping, dryRun)
+	if err != nil {
+		switch {
+		case errors.Is(err, group.ErrGroupNotFound):
+			response.NotFound(w, err.Error())
+		case errors.Is(err, group.ErrNotAuthorized):
+			response.Forbidden(w, err.Error())
+		case errors.Is(err, group.ErrGroupArchived):
+			response.Conflict(w, err.Error())
+		case errors.Is(err, ErrInvalidSplitwiseCSV), errors.Is(err, ErrUnknownMappedUser):
+			response.BadRequest(w, err.Error())
+		default:
+			response.InternalError(w, "Failed to import expenses")
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if report.Created > 0 {
+		status = http.StatusCreated
+	}
+	response.JSON(w, status, report)
+}
+
+// ImportStatement handles POST /imports/bank?format=ofx|qfx|csv.
+// The statement is sent as the "file" field of a multipart form or as the raw
+// request body; the format is detected from the content when not given.
+func (h *Handler) ImportStatement(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	format, err := ParseStatementFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	file, filename, _, err := readUpload(w, r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		response.BadRequest(w, "Failed to read statement")
+		return
+	}
+	if format == "" {
+		format, _ = ParseStatementFormat(strings.TrimPrefix(strings.ToLower(path.Ext(filename)), "."))
+	}
+
+	batch, err := h.service.ImportStatement(r.Context(), userID, data, format, filename)
+	if err != nil {
+		if errors.Is(err, ErrInvalidStatement) || errors.Is(err, ErrUnsupportedFormat) {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		response.InternalError(w, "Failed to import statement")
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, batch.ToResponse())
+}
+
+// ListBatches handles GET /imports/bank
+func (h *Handler) ListBatches(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	batches, total, err := h.service.ListBatches(r.Context(), userID, page, perPage)
+	if err != nil {
+		response.InternalError(w, "Failed to list imports")
+		return
+	}
+
+	responses := make([]*BatchResponse, len(batches))
+	for i, b := range batches {
+		responses[i] = b.ToResponse()
+	}
+
+	totalPages := total / perPage
+	if total%perPage > 0 {
+		totalPages++
+	}
+
+	response.JSONWithMeta(w, http.StatusOK, responses, &response.Meta{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+	})
+}
+
+// GetBatch handles GET /imports/bank/{batchId}
+func (h *Handler) GetBatch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	batchID, err := strconv.ParseInt(chi.URLParam(r, "batchId"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid import ID")
+		return
+	}
+
+	batch, err := h.service.GetBatch(r.Context(), batchID, userID)
+	if err != nil {
+		if errors.Is(err, ErrBatchNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		response.InternalError(w, "Failed to get import")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, batch.ToResponse())
+}
+
+// ConfirmBatch handles POST /imports/bank/{batchId}/confirm
+func (h *Handler) ConfirmBatch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	batchID, err := strconv.ParseInt(chi.URLParam(r, "batchId"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid import ID")
+		return
+	}
+
+	var req ConfirmBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+	if req.GroupID == 0 {
+		response.BadRequest(w, "group_id is required")
+		return
+	}
+
+	batch, err := h.service.ConfirmBatch(r.Context(), batchID, userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrBatchNotFound), errors.Is(err, group.ErrGroupNotFound):
+			response.NotFound(w, err.Error())
+		case errors.Is(err, group.ErrNotAuthorized):
+			response.Forbidden(w, err.Error())
+		case errors.Is(err, ErrBatchClosed), errors.Is(err, group.ErrGroupArchived):
+			response.Conflict(w, err.Error())
+		case errors.Is(err, ErrInvalidSplitRule), errors.Is(err, ErrUnknownTransaction):
+			response.BadRequest(w, err.Error())
+		default:
+			response.InternalError(w, "Failed to confirm import")
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, batch.ToResponse())
+}
+
+// DiscardBatch handles DELETE /imports/bank/{batchId}
+func (h *Handler) DiscardBatch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	batchID, err := strconv.ParseInt(chi.URLParam(r, "batchId"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid import ID")
+		return
+	}
+
+	if err := h.service.DiscardBatch(r.Context(), batchID, userID); err != nil {
+		if errors.Is(err, ErrBatchNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrBatchClosed) {
+			response.Conflict(w, err.Error())
+			return
+		}
+		response.InternalError(w, "Failed to discard import")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{"message": "Import discarded"})
+}
+
+// readUpload returns the uploaded file, its name if known, and the optional member mapping
+func readUpload(w http.ResponseWriter, r *http.Request) (io.ReadCloser, string, map[string]int64, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return r.Body, "", nil, nil
+	}
+
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		return nil, "", nil, errors.New("invalid multipart form")
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return nil, "", nil, errors.New("missing \"file\" field")
+	}
+
+	var mapping map[string]int64
+	if raw := r.FormValue("members"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			file.Close()
+			return nil, "", nil, errors.New("\"members\" must be a JSON object of name to user ID")
+		}
+	}
+
+	return file, header.Filename, mapping, nil
+}
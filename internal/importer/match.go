@@ -0,0 +1,77 @@
+package importer
+
+import (
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/fkhayef/splitwise/internal/expense"
+)
+
+const (
+	// duplicateWindowDays is how far apart a bank posting and an expense can be
+	// and still be the same purchase (card payments often post a few days late)
+	duplicateWindowDays = 3
+
+	// duplicateThreshold is the match score at which a transaction is treated
+	// as already recorded rather than merely similar
+	duplicateThreshold = 0.6
+)
+
+// bestMatch scores candidate expenses (already filtered to the same amount)
+// against a transaction and returns the closest one with its score in [0, 1].
+// The score weighs description similarity against how close the dates are.
+func bestMatch(t *StatementTransaction, candidates []*expense.Expense) (*expense.Expense, float64) {
+	var best *expense.Expense
+	var bestScore float64
+
+	for _, e := range candidates {
+		days := math.Abs(dateOnly(e.CreatedAt).Sub(dateOnly(t.PostedAt)).Hours() / 24)
+		closeness := math.Max(0, 1-days/(duplicateWindowDays+1))
+
+		score := 0.6*descriptionSimilarity(t.Description, e.Description) + 0.4*closeness
+		score = math.Round(score*100) / 100
+		if best == nil || score > bestScore {
+			best, bestScore = e, score
+		}
+	}
+
+	return best, bestScore
+}
+
+// descriptionSimilarity is the Jaccard similarity of the words in two descriptions.
+// Bank descriptions carry noise like card numbers and branch codes, so digits
+// and very short words are ignored.
+func descriptionSimilarity(a, b string) float64 {
+	wa, wb := descriptionWords(a), descriptionWords(b)
+	if len(wa) == 0 || len(wb) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for w := range wa {
+		if wb[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(wa)+len(wb)-shared)
+}
+
+// descriptionWords returns the distinct lowercase words of at least three letters
+func descriptionWords(s string) map[string]bool {
+	words := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		if len([]rune(w)) >= 3 {
+			words[w] = true
+		}
+	}
+	return words
+}
+
+// dateOnly drops the time of day
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
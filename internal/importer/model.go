@@ -0,0 +1,53 @@
+package importer
+
+import "time"
+
+// BatchStatus represents the state of a bank statement import
+type BatchStatus string
+
+const (
+	BatchStatusOpen      BatchStatus = "OPEN"      // Waiting for the user to confirm
+	BatchStatusCompleted BatchStatus = "COMPLETED" // Every proposed expense was imported
+	BatchStatusDiscarded BatchStatus = "DISCARDED"
+)
+
+// TransactionStatus represents what will happen to a statement transaction
+type TransactionStatus string
+
+const (
+	TransactionStatusProposed  TransactionStatus = "PROPOSED"  // Will become an expense on confirm
+	TransactionStatusDuplicate TransactionStatus = "DUPLICATE" // Matches an existing expense
+	TransactionStatusIgnored   TransactionStatus = "IGNORED"   // Money in, not an expense
+	TransactionStatusImported  TransactionStatus = "IMPORTED"
+)
+
+// Batch is an uploaded bank statement awaiting confirmation
+type Batch struct {
+	ID           int64           `json:"id"`
+	UserID       int64           `json:"user_id"`
+	Format       StatementFormat `json:"format"`
+	Filename     *string         `json:"filename,omitempty"`
+	CurrencyCode *string         `json:"currency_code,omitempty"`
+	Status       BatchStatus     `json:"status"`
+	GroupID      *int64          `json:"group_id,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	CompletedAt  *time.Time      `json:"completed_at,omitempty"`
+
+	Transactions []*BankTransaction `json:"transactions,omitempty"`
+}
+
+// BankTransaction is one transaction of an imported statement
+type BankTransaction struct {
+	ID             int64             `json:"id"`
+	BatchID        int64             `json:"batch_id"`
+	ExternalID     string            `json:"external_id"`
+	PostedAt       time.Time         `json:"posted_at"`
+	Description    string            `json:"description"`
+	Amount         float64           `json:"amount"` // Negative for money out
+	Category       *string           `json:"category,omitempty"`
+	Status         TransactionStatus `json:"status"`
+	Reason         *string           `json:"reason,omitempty"`
+	MatchExpenseID *int64            `json:"match_expense_id,omitempty"` // Closest existing expense, if any
+	MatchScore     *float64          `json:"match_score,omitempty"`
+	ExpenseID      *int64            `json:"expense_id,omitempty"` // Set once imported
+}
@@ -0,0 +1,93 @@
+package importer
+
+import (
+	"errors"
+	"html"
+	"strings"
+	"time"
+)
+
+// ErrInvalidOFX is returned when an OFX/QFX file has no statement transactions
+var ErrInvalidOFX = errors.New("file is not a valid OFX/QFX statement")
+
+// ParseOFX reads the transactions of an OFX or QFX statement.
+// Both the SGML (1.x, unclosed tags) and XML (2.x) flavours are accepted;
+// only the elements needed to propose expenses are read.
+func ParseOFX(data []byte) (*Statement, error) {
+	text := string(data)
+	if !strings.Contains(strings.ToUpper(text), "<OFX>") {
+		return nil, ErrInvalidOFX
+	}
+
+	stmt := &Statement{
+		Format:       StatementFormatOFX,
+		CurrencyCode: strings.ToUpper(ofxValue(text, "CURDEF")),
+	}
+
+	parts := strings.Split(text, "<STMTTRN>")
+	for _, block := range parts[1:] {
+		if end := strings.Index(block, "</STMTTRN>"); end >= 0 {
+			block = block[:end]
+		} else if end := strings.Index(block, "</BANKTRANLIST>"); end >= 0 {
+			block = block[:end]
+		}
+
+		posted, err := parseOFXDate(ofxValue(block, "DTPOSTED"))
+		if err != nil {
+			return nil, ErrInvalidOFX
+		}
+		amount, err := parseAmount(normalizeOFXAmount(ofxValue(block, "TRNAMT")))
+		if err != nil {
+			return nil, ErrInvalidOFX
+		}
+
+		description := ofxValue(block, "NAME")
+		if description == "" {
+			description = ofxValue(block, "MEMO")
+		}
+		if description == "" {
+			description = ofxValue(block, "TRNTYPE")
+		}
+
+		stmt.Transactions = append(stmt.Transactions, &StatementTransaction{
+			ExternalID:  ofxValue(block, "FITID"),
+			PostedAt:    posted,
+			Description: description,
+			Amount:      amount,
+		})
+	}
+
+	if len(stmt.Transactions) == 0 {
+		return nil, ErrInvalidOFX
+	}
+	return stmt, nil
+}
+
+// ofxValue returns the text following <tag> up to the next tag or line break
+func ofxValue(block, tag string) string {
+	start := strings.Index(block, "<"+tag+">")
+	if start < 0 {
+		return ""
+	}
+	value := block[start+len(tag)+2:]
+	if end := strings.IndexAny(value, "<\r\n"); end >= 0 {
+		value = value[:end]
+	}
+	return strings.TrimSpace(html.UnescapeString(value))
+}
+
+// parseOFXDate reads the date part of an OFX datetime such as 20240115120000.000[-5:EST]
+func parseOFXDate(s string) (time.Time, error) {
+	if len(s) < 8 {
+		return time.Time{}, ErrInvalidOFX
+	}
+	return time.Parse("20060102", s[:8])
+}
+
+// normalizeOFXAmount accepts a comma as the decimal separator, which some banks use
+func normalizeOFXAmount(s string) string {
+	if !strings.Contains(s, ".") {
+		return strings.Replace(s, ",", ".", 1)
+	}
+	return s
+}
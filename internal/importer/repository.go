@@ -0,0 +1,309 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/fkhayef/splitwise/internal/database"
+)
+
+// Repository handles bank import data persistence
+type Repository struct {
+	db   database.DBTX
+	conn *sql.DB
+}
+
+// NewRepository creates a new importer repository
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db, conn: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx
+func (r *Repository) WithTx(tx *sql.Tx) *Repository {
+	return &Repository{db: tx, conn: r.conn}
+}
+
+// InTx runs fn in a transaction; bind repositories to it with WithTx
+func (r *Repository) InTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	return database.WithTx(ctx, r.conn, fn)
+}
+
+// CreateBatch inserts a new open batch
+func (r *Repository) CreateBatch(ctx context.Context, userID int64, format StatementFormat, filename, currencyCode *string) (*Batch, error) {
+	query := `
+		INSERT INTO bank_import_batches (user_id, format, filename, currency_code)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, user_id, format, filename, currency_code, status, group_id, created_at, completed_at
+	`
+
+	b := &Batch{}
+	err := r.db.QueryRowContext(ctx, query, userID, format, filename, currencyCode).Scan(
+		&b.ID,
+		&b.UserID,
+		&b.Format,
+		&b.Filename,
+		&b.CurrencyCode,
+		&b.Status,
+		&b.GroupID,
+		&b.CreatedAt,
+		&b.CompletedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create import batch: %w", err)
+	}
+
+	return b, nil
+}
+
+// GetBatch retrieves a batch by its ID, without its transactions
+func (r *Repository) GetBatch(ctx context.Context, id int64) (*Batch, error) {
+	query := `
+		SELECT id, user_id, format, filename, currency_code, status, group_id, created_at, completed_at
+		FROM bank_import_batches
+		WHERE id = $1
+	`
+
+	b := &Batch{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&b.ID,
+		&b.UserID,
+		&b.Format,
+		&b.Filename,
+		&b.CurrencyCode,
+		&b.Status,
+		&b.GroupID,
+		&b.CreatedAt,
+		&b.CompletedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get import batch: %w", err)
+	}
+
+	return b, nil
+}
+
+// ListBatchesByUserID retrieves a user's batches, newest first
+func (r *Repository) ListBatchesByUserID(ctx context.Context, userID int64, limit, offset int) ([]*Batch, int, error) {
+	var total int
+	countQuery := `SELECT COUNT(*) FROM bank_import_batches WHERE user_id = $1`
+	if err := r.db.QueryRowContext(ctx, countQuery, userID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count import batches: %w", err)
+	}
+
+	query := `
+		SELECT id, user_id, format, filename, currency_code, status, group_id, created_at, completed_at
+		FROM bank_import_batches
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list import batches: %w", err)
+	}
+	defer rows.Close()
+
+	var batches []*Batch
+	for rows.Next() {
+		b := &Batch{}
+		if err := rows.Scan(
+			&b.ID,
+			&b.UserID,
+			&b.Format,
+			&b.Filename,
+			&b.CurrencyCode,
+			&b.Status,
+			&b.GroupID,
+			&b.CreatedAt,
+			&b.CompletedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan import batch: %w", err)
+		}
+		batches = append(batches, b)
+	}
+
+	return batches, total, rows.Err()
+}
+
+// LockBatch locks a batch's row until the transaction ends
+func (r *Repository) LockBatch(ctx context.Context, id int64) error {
+	query := `SELECT id FROM bank_import_batches WHERE id = $1 FOR UPDATE`
+
+	var locked int64
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&locked)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to lock import batch: %w", err)
+	}
+	return nil
+}
+
+// UpdateBatchStatus changes a batch's status and records the group it was imported into.
+// Completing a batch stamps completed_at.
+func (r *Repository) UpdateBatchStatus(ctx context.Context, id int64, status BatchStatus, groupID *int64) error {
+	query := `
+		UPDATE bank_import_batches
+		SET status = $2,
+		    group_id = COALESCE($3, group_id),
+		    completed_at = CASE WHEN $2 = 'COMPLETED' THEN NOW() ELSE completed_at END
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, status, groupID); err != nil {
+		return fmt.Errorf("failed to update import batch: %w", err)
+	}
+	return nil
+}
+
+// CreateTransaction inserts a statement transaction into a batch
+func (r *Repository) CreateTransaction(ctx context.Context, t *BankTransaction) (*BankTransaction, error) {
+	query := `
+		INSERT INTO bank_import_transactions
+			(batch_id, external_id, posted_at, description, amount, category, status, reason, match_expense_id, match_score)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		t.BatchID,
+		t.ExternalID,
+		t.PostedAt,
+		t.Description,
+		t.Amount,
+		t.Category,
+		t.Status,
+		t.Reason,
+		t.MatchExpenseID,
+		t.MatchScore,
+	).Scan(&t.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create import transaction: %w", err)
+	}
+
+	return t, nil
+}
+
+// ListTransactions retrieves the transactions of a batch in statement order
+func (r *Repository) ListTransactions(ctx context.Context, batchID int64) ([]*BankTransaction, error) {
+	query := `
+		SELECT id, batch_id, external_id, posted_at, description, amount, category, status,
+		       reason, match_expense_id, match_score, expense_id
+		FROM bank_import_transactions
+		WHERE batch_id = $1
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list import transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var txns []*BankTransaction
+	for rows.Next() {
+		t := &BankTransaction{}
+		if err := rows.Scan(
+			&t.ID,
+			&t.BatchID,
+			&t.ExternalID,
+			&t.PostedAt,
+			&t.Description,
+			&t.Amount,
+			&t.Category,
+			&t.Status,
+			&t.Reason,
+			&t.MatchExpenseID,
+			&t.MatchScore,
+			&t.ExpenseID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan import transaction: %w", err)
+		}
+		txns = append(txns, t)
+	}
+
+	return txns, rows.Err()
+}
+
+// MarkTransactionImported links a transaction to the expense created for it
+func (r *Repository) MarkTransactionImported(ctx context.Context, id, expenseID int64) error {
+	query := `
+		UPDATE bank_import_transactions
+		SET status = 'IMPORTED', expense_id = $2, reason = NULL
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, expenseID); err != nil {
+		return fmt.Errorf("failed to mark transaction imported: %w", err)
+	}
+	return nil
+}
+
+// SetTransactionReason records why a transaction couldn't be imported
+func (r *Repository) SetTransactionReason(ctx context.Context, id int64, reason string) error {
+	query := `UPDATE bank_import_transactions SET reason = $2 WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id, reason); err != nil {
+		return fmt.Errorf("failed to update import transaction: %w", err)
+	}
+	return nil
+}
+
+// FindImportedTransaction returns the expense a user already imported for a
+// bank transaction ID from an earlier statement, or 0 if there is none
+func (r *Repository) FindImportedTransaction(ctx context.Context, userID int64, externalID string) (int64, error) {
+	query := `
+		SELECT t.expense_id
+		FROM bank_import_transactions t
+		JOIN bank_import_batches b ON t.batch_id = b.id
+		WHERE b.user_id = $1 AND t.external_id = $2
+		  AND t.status = 'IMPORTED' AND t.expense_id IS NOT NULL
+		ORDER BY t.id DESC
+		LIMIT 1
+	`
+
+	var expenseID int64
+	err := r.db.QueryRowContext(ctx, query, userID, externalID).Scan(&expenseID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up imported transaction: %w", err)
+	}
+
+	return expenseID, nil
+}
+
+// FindImportedRow returns the expense already imported into a group for a
+// Splitwise row hash, or 0 if there is none
+func (r *Repository) FindImportedRow(ctx context.Context, groupID int64, rowHash string) (int64, error) {
+	query := `SELECT expense_id FROM splitwise_import_rows WHERE group_id = $1 AND row_hash = $2`
+
+	var expenseID int64
+	err := r.db.QueryRowContext(ctx, query, groupID, rowHash).Scan(&expenseID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up imported row: %w", err)
+	}
+
+	return expenseID, nil
+}
+
+// RecordImportedRow remembers the expense a Splitwise row was imported as
+func (r *Repository) RecordImportedRow(ctx context.Context, groupID int64, rowHash string, expenseID int64) error {
+	query := `
+		INSERT INTO splitwise_import_rows (group_id, row_hash, expense_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, groupID, rowHash, expenseID); err != nil {
+		return fmt.Errorf("failed to record imported row: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,634 @@
+package importer
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fkhayef/splitwise/internal/expense"
+	"github.com/fkhayef/splitwise/internal/group"
+)
+
+// Common errors
+var (
+	ErrUnknownMappedUser  = errors.New("member mapping refers to a user who is not a joined member of the group")
+	ErrBatchNotFound      = errors.New("import not found")
+	ErrBatchClosed        = errors.New("import has already been completed or discarded")
+	ErrInvalidSplitRule   = errors.New("split_type must be EVEN or PERCENTAGE, and PERCENTAGE needs participants")
+	ErrUnknownTransaction = errors.New("transaction does not belong to this import")
+)
+
+// Service imports expenses from other apps and bank statements into groups.
+// Expenses are created through the expense service, so the usual split
+// validation, permissions and activity recording all apply.
+type Service struct {
+	repo        *Repository
+	expenses    *expense.Service
+	expenseRepo *expense.Repository
+	groups      *group.Service
+}
+
+// NewService creates a new importer service
+func NewService(repo *Repository, expenseService *expense.Service, expenseRepo *expense.Repository, groupService *group.Service) *Service {
+	return &Service{
+		repo:        repo,
+		expenses:    expenseService,
+		expenseRepo: expenseRepo,
+		groups:      groupService,
+	}
+}
+
+// ImportSplitwise imports a Splitwise group export into a group as EXACT expenses.
+// Member names in the file are matched to group members by username unless
+// mapping (name -> user ID) says otherwise. With dryRun set nothing is
+// created and the report shows what would happen.
+func (s *Service) ImportSplitwise(ctx context.Context, groupID, actorID int64, r io.Reader, mapping map[string]int64, dryRun bool) (*ImportReport, error) {
+	// The importing user must be allowed to add expenses to the group
+	if err := s.groups.CanAddExpense(ctx, groupID, actorID); err != nil {
+		return nil, err
+	}
+
+	export, err := ParseSplitwiseCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.joinedMembers(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{GroupID: groupID, DryRun: dryRun}
+
+	// Resolve each column of the file to a group member
+	userIDs := make([]int64, len(export.Members))
+	for i, name := range export.Members {
+		m, err := matchMember(name, members, mapping)
+		if err != nil {
+			return nil, err
+		}
+		mm := &MemberMapping{Name: name}
+		if m != nil {
+			userIDs[i] = m.UserID
+			mm.UserID = m.UserID
+			mm.Username = m.Username
+		}
+		report.Members = append(report.Members, mm)
+	}
+
+	currencies := map[string]bool{}
+	seen := map[string]int{}
+	for _, row := range export.Rows {
+		if row.Currency != "" {
+			currencies[row.Currency] = true
+		}
+
+		result := planSplitwiseRow(row, export.Members, userIDs)
+		if result.Status != RowStatusWouldCreate {
+			report.add(result)
+			continue
+		}
+
+		// Rows created by an earlier import of the same export aren't created twice
+		hash := splitwiseRowHash(result, seen)
+		existingID, err := s.repo.FindImportedRow(ctx, groupID, hash)
+		if err != nil {
+			return nil, err
+		}
+		if existingID != 0 {
+			result.Status = RowStatusSkippedDuplicate
+			result.ExpenseID = existingID
+			result.Reason = fmt.Sprintf("already imported as expense %d", existingID)
+			report.add(result)
+			continue
+		}
+
+		req := splitwiseExpenseRequest(groupID, row, result)
+		if dryRun {
+			// Run the split validation a real import would, so the report agrees with it
+			if err := s.expenses.ValidateExpense(result.PayerID, req); err != nil {
+				result.Status = RowStatusFailed
+				result.Reason = err.Error()
+			}
+			report.add(result)
+			continue
+		}
+
+		// The importing user records each expense on behalf of whoever paid it
+		created, err := s.expenses.CreateExpenseFor(ctx, actorID, result.PayerID, req)
+		if err != nil {
+			result.Status = RowStatusFailed
+			result.Reason = err.Error()
+		} else {
+			result.Status = RowStatusCreated
+			result.ExpenseID = created.Expense.ID
+			if err := s.repo.RecordImportedRow(ctx, groupID, hash, created.Expense.ID); err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf(
+					"line %d was imported but won't be recognised if the file is imported again: %v", row.Line, err))
+			}
+		}
+		report.add(result)
+	}
+
+	if len(currencies) > 1 {
+		names := make([]string, 0, len(currencies))
+		for c := range currencies {
+			names = append(names, c)
+		}
+		sort.Strings(names)
+		report.Warnings = append(report.Warnings, fmt.Sprintf(
+			"the file mixes currencies (%s); amounts are imported as-is without conversion", strings.Join(names, ", ")))
+	}
+
+	return report, nil
+}
+
+// joinedMembers returns the group's current members
+func (s *Service) joinedMembers(ctx context.Context, groupID int64) ([]*group.GroupMember, error) {
+	all, err := s.groups.GetMembers(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []*group.GroupMember
+	for _, m := range all {
+		if m.Status == group.MemberStatusJoined {
+			members = append(members, m)
+		}
+	}
+	return members, nil
+}
+
+// matchMember finds the group member for a name in an imported file.
+// An explicit mapping wins; otherwise the name is compared to usernames
+// case-insensitively, treating spaces as underscores ("Jane Doe" matches
+// "jane_doe"). It returns nil if there is no match.
+func matchMember(name string, members []*group.GroupMember, mapping map[string]int64) (*group.GroupMember, error) {
+	for mappedName, userID := range mapping {
+		if !strings.EqualFold(mappedName, name) {
+			continue
+		}
+		for _, m := range members {
+			if m.UserID == userID {
+				return m, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %q -> %d", ErrUnknownMappedUser, name, userID)
+	}
+
+	normalized := strings.ToLower(strings.Join(strings.Fields(name), "_"))
+	for _, m := range members {
+		if strings.ToLower(m.Username) == normalized {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+// planSplitwiseRow works out the payer and shares of a Splitwise row.
+// The payer is the one member with a positive balance; their share is the
+// cost minus what they lent, and everyone else's share is what they owe.
+// Rows that can be imported come back with status WOULD_CREATE.
+func planSplitwiseRow(row *SplitwiseRow, names []string, userIDs []int64) *RowResult {
+	result := &RowResult{
+		Line:        row.Line,
+		Description: row.Description,
+		Amount:      row.Cost,
+	}
+	if !row.Date.IsZero() {
+		result.Date = row.Date.Format("2006-01-02")
+	}
+
+	fail := func(status RowStatus, format string, args ...interface{}) *RowResult {
+		result.Status = status
+		result.Reason = fmt.Sprintf(format, args...)
+		return result
+	}
+
+	if row.Err != nil {
+		return fail(RowStatusFailed, "%v", row.Err)
+	}
+	if row.IsPayment() {
+		return fail(RowStatusSkipped, "payments between members are not imported; record them as settlements")
+	}
+	if row.Description == "" {
+		return fail(RowStatusFailed, "description is empty")
+	}
+	if row.Cost <= 0 {
+		return fail(RowStatusSkipped, "cost must be greater than zero")
+	}
+
+	var sum float64
+	payerIdx := -1
+	for i, balance := range row.Balances {
+		if balance == 0 {
+			continue
+		}
+		if userIDs[i] == 0 {
+			return fail(RowStatusFailed, "%q is not a member of the group", names[i])
+		}
+		if balance > 0 {
+			if payerIdx >= 0 {
+				return fail(RowStatusFailed, "expenses paid by more than one member are not supported")
+			}
+			payerIdx = i
+		}
+		sum += balance
+	}
+
+	if math.Abs(sum) > 0.01 {
+		return fail(RowStatusFailed, "member balances add up to %.2f instead of zero", sum)
+	}
+	if payerIdx < 0 {
+		return fail(RowStatusSkipped, "nobody is owed anything for this expense")
+	}
+
+	payerShare := roundCents(row.Cost - row.Balances[payerIdx])
+	if payerShare < -0.01 {
+		return fail(RowStatusFailed, "payer lent %.2f, more than the cost of %.2f", row.Balances[payerIdx], row.Cost)
+	}
+
+	result.PayerID = userIDs[payerIdx]
+	result.Shares = append(result.Shares, &RowShare{UserID: result.PayerID, Amount: math.Max(payerShare, 0)})
+	for i, balance := range row.Balances {
+		if balance < 0 {
+			result.Shares = append(result.Shares, &RowShare{UserID: userIDs[i], Amount: -balance})
+		}
+	}
+
+	result.Status = RowStatusWouldCreate
+	return result
+}
+
+// splitwiseRowHash identifies a planned row by its date, description, cost and
+// shares. Identical rows are numbered so two equal expenses on one day stay distinct.
+func splitwiseRowHash(plan *RowResult, seen map[string]int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s|%s|%.2f", plan.Date, strings.ToLower(plan.Description), plan.Amount)
+	for _, share := range plan.Shares {
+		fmt.Fprintf(&b, "|%d:%.2f", share.UserID, share.Amount)
+	}
+	key := b.String()
+	seen[key]++
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d", key, seen[key])))
+	return "sha256:" + hex.EncodeToString(sum[:16])
+}
+
+// splitwiseExpenseRequest builds the EXACT expense for a planned row
+func splitwiseExpenseRequest(groupID int64, row *SplitwiseRow, plan *RowResult) *expense.CreateExpenseRequest {
+	req := &expense.CreateExpenseRequest{
+		GroupID:     groupID,
+		Description: row.Description,
+		Amount:      row.Cost,
+		SplitType:   "EXACT",
+		Date:        &plan.Date,
+	}
+	if row.Category != "" {
+		category := row.Category
+		if len(category) > 50 {
+			category = category[:50]
+		}
+		req.Category = &category
+	}
+	for _, share := range plan.Shares {
+		amount := share.Amount
+		req.Participants = append(req.Participants, &expense.SplitParticipant{
+			UserID: share.UserID,
+			Amount: &amount,
+		})
+	}
+	return req
+}
+
+// ImportStatement parses a bank statement into a new batch for the user to review.
+// Money leaving the account is proposed as expenses unless it was already
+// imported from an earlier statement or closely matches an expense the user
+// paid, in which case it is flagged as a duplicate.
+func (s *Service) ImportStatement(ctx context.Context, userID int64, data []byte, format StatementFormat, filename string) (*Batch, error) {
+	stmt, err := ParseStatement(data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	var filenamePtr, currencyPtr *string
+	if filename != "" {
+		filenamePtr = &filename
+	}
+	if stmt.CurrencyCode != "" {
+		currencyPtr = &stmt.CurrencyCode
+	}
+
+	// The batch and its transactions are saved together, so a failure part
+	// way through doesn't leave a half-built batch to review
+	var batch *Batch
+	err = s.repo.InTx(ctx, func(tx *sql.Tx) error {
+		repo := s.repo.WithTx(tx)
+
+		batch, err = repo.CreateBatch(ctx, userID, stmt.Format, filenamePtr, currencyPtr)
+		if err != nil {
+			return err
+		}
+
+		seen := map[string]bool{}
+		for _, st := range stmt.Transactions {
+			// Statements occasionally repeat a line; keep the first
+			if seen[st.ExternalID] {
+				continue
+			}
+			seen[st.ExternalID] = true
+
+			t, err := s.classify(ctx, userID, st)
+			if err != nil {
+				return err
+			}
+			t.BatchID = batch.ID
+			if _, err := repo.CreateTransaction(ctx, t); err != nil {
+				return err
+			}
+			batch.Transactions = append(batch.Transactions, t)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+// classify decides whether a statement transaction should become an expense
+func (s *Service) classify(ctx context.Context, userID int64, st *StatementTransaction) (*BankTransaction, error) {
+	t := &BankTransaction{
+		ExternalID:  st.ExternalID,
+		PostedAt:    st.PostedAt,
+		Description: truncate(st.Description, 255),
+		Amount:      st.Amount,
+		Status:      TransactionStatusProposed,
+	}
+	if t.Description == "" {
+		t.Description = "Bank transaction"
+	}
+	if st.Category != "" {
+		category := truncate(st.Category, 50)
+		t.Category = &category
+	}
+
+	reason := func(r string) *string { return &r }
+
+	if st.Amount >= 0 {
+		t.Status = TransactionStatusIgnored
+		t.Reason = reason("money coming into the account is not an expense")
+		return t, nil
+	}
+
+	// The same bank transaction from an overlapping statement
+	expenseID, err := s.repo.FindImportedTransaction(ctx, userID, st.ExternalID)
+	if err != nil {
+		return nil, err
+	}
+	if expenseID != 0 {
+		score := 1.0
+		t.Status = TransactionStatusDuplicate
+		t.Reason = reason("already imported from an earlier statement")
+		t.MatchExpenseID = &expenseID
+		t.MatchScore = &score
+		return t, nil
+	}
+
+	// An expense the user recorded by hand for the same purchase
+	window := time.Duration(duplicateWindowDays) * 24 * time.Hour
+	candidates, err := s.expenseRepo.ListPayerExpensesByAmount(ctx, userID, -st.Amount,
+		dateOnly(st.PostedAt).Add(-window), dateOnly(st.PostedAt).Add(window))
+	if err != nil {
+		return nil, err
+	}
+	if match, score := bestMatch(st, candidates); match != nil {
+		t.MatchExpenseID = &match.ID
+		t.MatchScore = &score
+		if score >= duplicateThreshold {
+			t.Status = TransactionStatusDuplicate
+			t.Reason = reason(fmt.Sprintf("looks like \"%s\" recorded on %s", match.Description, match.CreatedAt.Format("2006-01-02")))
+		}
+	}
+
+	return t, nil
+}
+
+// GetBatch retrieves one of the user's imports with its transactions
+func (s *Service) GetBatch(ctx context.Context, batchID, userID int64) (*Batch, error) {
+	batch, err := s.repo.GetBatch(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if batch == nil || batch.UserID != userID {
+		return nil, ErrBatchNotFound
+	}
+
+	if batch.Transactions, err = s.repo.ListTransactions(ctx, batchID); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// ListBatches retrieves the user's imports, without their transactions
+func (s *Service) ListBatches(ctx context.Context, userID int64, page, perPage int) ([]*Batch, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	offset := (page - 1) * perPage
+	return s.repo.ListBatchesByUserID(ctx, userID, perPage, offset)
+}
+
+// ConfirmBatch creates expenses paid by the user in a group for the chosen
+// transactions (every PROPOSED one by default), all split by the same rule.
+// Duplicates are only imported when picked explicitly. Transactions that fail
+// keep their status with the error as the reason; the batch completes once no
+// proposals remain.
+func (s *Service) ConfirmBatch(ctx context.Context, batchID, userID int64, req *ConfirmBatchRequest) (*Batch, error) {
+	// Hold the batch's row lock while confirming, so a second confirm of the
+	// same batch waits for this one and then only sees what is left to import.
+	// Expenses are created through the expense service, outside the lock's
+	// transaction, and each transaction is marked as soon as its expense exists.
+	var batch *Batch
+	err := s.repo.InTx(ctx, func(tx *sql.Tx) error {
+		if err := s.repo.WithTx(tx).LockBatch(ctx, batchID); err != nil {
+			return err
+		}
+
+		var err error
+		batch, err = s.confirmBatch(ctx, batchID, userID, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+// confirmBatch does the work of ConfirmBatch once the batch is locked
+func (s *Service) confirmBatch(ctx context.Context, batchID, userID int64, req *ConfirmBatchRequest) (*Batch, error) {
+	batch, err := s.GetBatch(ctx, batchID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Status != BatchStatusOpen {
+		return nil, ErrBatchClosed
+	}
+
+	if err := s.groups.CanAddExpense(ctx, req.GroupID, userID); err != nil {
+		return nil, err
+	}
+
+	splitType, participants, err := s.splitRule(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	selected, err := selectTransactions(batch.Transactions, req.TransactionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range selected {
+		date := t.PostedAt.Format("2006-01-02")
+		category := t.Category
+		if category == nil {
+			category = req.Category
+		}
+
+		created, err := s.expenses.CreateExpense(ctx, userID, &expense.CreateExpenseRequest{
+			GroupID:      req.GroupID,
+			Description:  t.Description,
+			Amount:       -t.Amount,
+			SplitType:    splitType,
+			Category:     category,
+			Date:         &date,
+			Participants: participants,
+		})
+		if err != nil {
+			reason := err.Error()
+			t.Reason = &reason
+			if err := s.repo.SetTransactionReason(ctx, t.ID, reason); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if err := s.repo.MarkTransactionImported(ctx, t.ID, created.Expense.ID); err != nil {
+			return nil, err
+		}
+		t.Status = TransactionStatusImported
+		t.ExpenseID = &created.Expense.ID
+		t.Reason = nil
+	}
+
+	status := BatchStatusCompleted
+	for _, t := range batch.Transactions {
+		if t.Status == TransactionStatusProposed {
+			status = BatchStatusOpen
+			break
+		}
+	}
+	if err := s.repo.UpdateBatchStatus(ctx, batchID, status, &req.GroupID); err != nil {
+		return nil, err
+	}
+
+	return s.GetBatch(ctx, batchID, userID)
+}
+
+// DiscardBatch abandons an open import without creating anything
+func (s *Service) DiscardBatch(ctx context.Context, batchID, userID int64) error {
+	batch, err := s.repo.GetBatch(ctx, batchID)
+	if err != nil {
+		return err
+	}
+	if batch == nil || batch.UserID != userID {
+		return ErrBatchNotFound
+	}
+	if batch.Status != BatchStatusOpen {
+		return ErrBatchClosed
+	}
+
+	return s.repo.UpdateBatchStatus(ctx, batchID, BatchStatusDiscarded, nil)
+}
+
+// splitRule resolves the split applied to every confirmed transaction.
+// Without participants the expense is split evenly across the group.
+func (s *Service) splitRule(ctx context.Context, req *ConfirmBatchRequest) (string, []*expense.SplitParticipant, error) {
+	splitType := strings.ToUpper(req.SplitType)
+	if splitType == "" {
+		splitType = "EVEN"
+	}
+	if splitType != "EVEN" && splitType != "PERCENTAGE" {
+		return "", nil, ErrInvalidSplitRule
+	}
+
+	if len(req.Participants) > 0 {
+		return splitType, req.Participants, nil
+	}
+	if splitType == "PERCENTAGE" {
+		return "", nil, ErrInvalidSplitRule
+	}
+
+	members, err := s.joinedMembers(ctx, req.GroupID)
+	if err != nil {
+		return "", nil, err
+	}
+	participants := make([]*expense.SplitParticipant, len(members))
+	for i, m := range members {
+		participants[i] = &expense.SplitParticipant{UserID: m.UserID}
+	}
+	return splitType, participants, nil
+}
+
+// selectTransactions picks the transactions to confirm: the listed IDs, or
+// every proposal if none are listed. Imported and ignored ones are skipped.
+func selectTransactions(txns []*BankTransaction, ids []int64) ([]*BankTransaction, error) {
+	if len(ids) == 0 {
+		var selected []*BankTransaction
+		for _, t := range txns {
+			if t.Status == TransactionStatusProposed {
+				selected = append(selected, t)
+			}
+		}
+		return selected, nil
+	}
+
+	byID := make(map[int64]*BankTransaction, len(txns))
+	for _, t := range txns {
+		byID[t.ID] = t
+	}
+
+	var selected []*BankTransaction
+	for _, id := range ids {
+		t, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %d", ErrUnknownTransaction, id)
+		}
+		if t.Status == TransactionStatusProposed || t.Status == TransactionStatusDuplicate {
+			selected = append(selected, t)
+		}
+	}
+	return selected, nil
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
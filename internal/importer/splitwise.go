@@ -0,0 +1,155 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// splitwiseFixedColumns are the columns before the per-member balances
+// in a Splitwise group export: Date, Description, Category, Cost, Currency
+const splitwiseFixedColumns = 5
+
+// ErrInvalidSplitwiseCSV is returned when the file isn't a Splitwise group export
+var ErrInvalidSplitwiseCSV = errors.New("file is not a Splitwise export: expected Date, Description, Category, Cost, Currency and member columns")
+
+// SplitwiseExport is a parsed Splitwise group export
+type SplitwiseExport struct {
+	Members []string // Member names from the header, in column order
+	Rows    []*SplitwiseRow
+}
+
+// SplitwiseRow is a single expense or payment line of a Splitwise export.
+// Balances holds each member's net for the row, in the same order as
+// SplitwiseExport.Members: positive means the member lent money, negative
+// means they owe it.
+type SplitwiseRow struct {
+	Line        int
+	Date        time.Time
+	Description string
+	Category    string
+	Cost        float64
+	Currency    string
+	Balances    []float64
+	Err         error // Set when the row couldn't be parsed
+}
+
+// IsPayment reports whether the row records a payment between members
+// rather than an expense
+func (r *SplitwiseRow) IsPayment() bool {
+	return strings.EqualFold(r.Category, "Payment")
+}
+
+// ParseSplitwiseCSV reads a Splitwise group export.
+// Blank lines and the trailing "Total balance" line are skipped; rows that
+// can't be parsed are returned with Err set so they show up in the report.
+func ParseSplitwiseCSV(r io.Reader) (*SplitwiseExport, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrInvalidSplitwiseCSV
+		}
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	if len(header) <= splitwiseFixedColumns || !strings.EqualFold(strings.TrimPrefix(header[0], "\ufeff"), "Date") {
+		return nil, ErrInvalidSplitwiseCSV
+	}
+
+	export := &SplitwiseExport{}
+	for _, name := range header[splitwiseFixedColumns:] {
+		export.Members = append(export.Members, strings.TrimSpace(name))
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		if isBlankRecord(record) || strings.TrimSpace(record[0]) == "" {
+			// Blank separator lines and the "Total balance" summary have no date
+			continue
+		}
+
+		export.Rows = append(export.Rows, parseSplitwiseRow(line, record, len(export.Members)))
+	}
+
+	return export, nil
+}
+
+// parseSplitwiseRow converts a CSV record into a row, recording the first problem in Err
+func parseSplitwiseRow(line int, record []string, memberCount int) *SplitwiseRow {
+	row := &SplitwiseRow{Line: line}
+
+	if len(record) != splitwiseFixedColumns+memberCount {
+		row.Err = fmt.Errorf("expected %d columns, got %d", splitwiseFixedColumns+memberCount, len(record))
+		return row
+	}
+
+	row.Description = strings.TrimSpace(record[1])
+	row.Category = strings.TrimSpace(record[2])
+	row.Currency = strings.TrimSpace(record[4])
+
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(record[0]))
+	if err != nil {
+		row.Err = fmt.Errorf("invalid date %q", record[0])
+		return row
+	}
+	row.Date = date
+
+	if row.Cost, err = parseAmount(record[3]); err != nil {
+		row.Err = fmt.Errorf("invalid cost %q", record[3])
+		return row
+	}
+
+	row.Balances = make([]float64, memberCount)
+	for i, cell := range record[splitwiseFixedColumns:] {
+		if strings.TrimSpace(cell) == "" {
+			continue
+		}
+		if row.Balances[i], err = parseAmount(cell); err != nil {
+			row.Err = fmt.Errorf("invalid amount %q in column %d", cell, splitwiseFixedColumns+i+1)
+			return row
+		}
+	}
+
+	return row
+}
+
+// parseAmount parses a decimal amount, tolerating thousands separators
+func parseAmount(s string) (float64, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return roundCents(v), nil
+}
+
+// roundCents rounds an amount to two decimal places
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// isBlankRecord reports whether every field of a record is empty
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
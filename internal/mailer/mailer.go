@@ -0,0 +1,135 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Message represents an outgoing email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer is the interface that all email transports must implement
+type Mailer interface {
+	// Send delivers a single message
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Config selects and configures a mail transport
+type Config struct {
+	Transport    string // "log", "file", "smtp" or "sink"
+	From         string
+	Dir          string // Output directory for the "file" transport
+	SMTPAddr     string // host:port of the SMTP server for the "smtp" transport
+	SMTPUsername string // Optional; enables PLAIN authentication
+	SMTPPassword string
+}
+
+// New creates a mailer based on the configured transport name.
+// "log" (the default) prints messages to the application log, "file" writes
+// each message as an .eml file into the mail directory and "smtp" sends them
+// through an SMTP server. "sink" sends them over SMTP to a local Sink that
+// logs what it receives, exercising the SMTP path without a real server.
+func New(cfg Config) (Mailer, error) {
+	switch cfg.Transport {
+	case "", "log":
+		return &LogMailer{From: cfg.From}, nil
+	case "file":
+		if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create mail directory: %w", err)
+		}
+		return &FileMailer{From: cfg.From, Dir: cfg.Dir}, nil
+	case "smtp":
+		if cfg.SMTPAddr == "" {
+			return nil, fmt.Errorf("smtp mail transport needs an SMTP address")
+		}
+		m := &SMTPMailer{From: cfg.From, Addr: cfg.SMTPAddr}
+		if cfg.SMTPUsername != "" {
+			host, _, _ := net.SplitHostPort(cfg.SMTPAddr)
+			m.Auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, host)
+		}
+		return m, nil
+	case "sink":
+		sink, err := StartSink("127.0.0.1:0")
+		if err != nil {
+			return nil, err
+		}
+		sink.OnMessage = func(msg *ReceivedMessage) {
+			log.Printf("mail sink: from=%s to=%s\n%s", msg.From, strings.Join(msg.To, ","), msg.Data)
+		}
+		return &SMTPMailer{From: cfg.From, Addr: sink.Addr()}, nil
+	default:
+		return nil, fmt.Errorf("unknown mail transport: %s", cfg.Transport)
+	}
+}
+
+// =============================================================================
+// DEVELOPMENT TRANSPORTS
+// Neither of these sends real email; they make messages easy to inspect locally
+// =============================================================================
+
+// LogMailer writes messages to the application log
+type LogMailer struct {
+	From string
+}
+
+// Send logs the message
+func (m *LogMailer) Send(ctx context.Context, msg *Message) error {
+	log.Printf("mail: from=%s to=%s subject=%q\n%s", m.From, msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileMailer writes each message to its own .eml file
+type FileMailer struct {
+	From string
+	Dir  string
+}
+
+// Send writes the message to a timestamped file in the mail directory
+func (m *FileMailer) Send(ctx context.Context, msg *Message) error {
+	now := time.Now().UTC()
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405.000000000"), sanitize(msg.To))
+
+	if err := os.WriteFile(filepath.Join(m.Dir, name), format(m.From, msg, now), 0o644); err != nil {
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+	return nil
+}
+
+// format renders a message with its headers. The subject is MIME-encoded
+// so non-ASCII subjects (e.g. Arabic) survive transports that expect ASCII headers.
+func format(from string, msg *Message, date time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(msg.Body)
+	return []byte(b.String())
+}
+
+// sanitize makes an email address safe to use in a file name
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
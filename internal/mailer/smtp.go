@@ -0,0 +1,213 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dialTimeout bounds how long connecting to the SMTP server may take
+const dialTimeout = 10 * time.Second
+
+// SMTPMailer sends messages through an SMTP server, upgrading to TLS when the
+// server offers STARTTLS
+type SMTPMailer struct {
+	From string
+	Addr string    // host:port
+	Auth smtp.Auth // nil to send without authenticating
+}
+
+// Send delivers the message to the SMTP server
+func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
+	}
+
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", m.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	host, _, _ := net.SplitHostPort(m.Addr)
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if m.Auth != nil {
+		if err := c.Auth(m.Auth); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	if err := c.Mail(from.Address); err != nil {
+		return fmt.Errorf("SMTP server rejected sender: %w", err)
+	}
+	if err := c.Rcpt(msg.To); err != nil {
+		return fmt.Errorf("SMTP server rejected recipient: %w", err)
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	if _, err := w.Write(format(m.From, msg, time.Now().UTC())); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return c.Quit()
+}
+
+// =============================================================================
+// LOCAL SMTP SINK
+// Accepts mail like a real server would but keeps it in memory, so tests and
+// local development can check what was sent without an outside mail server
+// =============================================================================
+
+// ReceivedMessage is a message accepted by a Sink
+type ReceivedMessage struct {
+	From string
+	To   []string
+	Data string // Headers and body as received
+}
+
+// Sink is a minimal SMTP server that stores every message it receives
+type Sink struct {
+	// OnMessage, if set, is called with every message received
+	OnMessage func(msg *ReceivedMessage)
+
+	listener net.Listener
+	mu       sync.Mutex
+	messages []*ReceivedMessage
+}
+
+// StartSink starts a sink listening on addr, e.g. "127.0.0.1:0" for a random port
+func StartSink(addr string) (*Sink, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start mail sink: %w", err)
+	}
+
+	s := &Sink{listener: listener}
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the address the sink is listening on
+func (s *Sink) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Messages returns the messages received so far
+func (s *Sink) Messages() []*ReceivedMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*ReceivedMessage(nil), s.messages...)
+}
+
+// Close stops accepting connections
+func (s *Sink) Close() error {
+	return s.listener.Close()
+}
+
+func (s *Sink) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle runs one SMTP session, accepting every sender and recipient
+func (s *Sink) handle(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+
+	tp.PrintfLine("220 localhost splitwise mail sink")
+
+	msg := &ReceivedMessage{}
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250 localhost")
+		case "MAIL":
+			msg = &ReceivedMessage{From: trimPath(arg, "FROM:")}
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			msg.To = append(msg.To, trimPath(arg, "TO:"))
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := io.ReadAll(bufio.NewReader(tp.DotReader()))
+			if err != nil {
+				return
+			}
+			msg.Data = string(data)
+			s.store(msg)
+			tp.PrintfLine("250 OK")
+		case "RSET":
+			msg = &ReceivedMessage{}
+			tp.PrintfLine("250 OK")
+		case "NOOP":
+			tp.PrintfLine("250 OK")
+		case "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+		default:
+			tp.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+func (s *Sink) store(msg *ReceivedMessage) {
+	s.mu.Lock()
+	s.messages = append(s.messages, msg)
+	s.mu.Unlock()
+
+	if s.OnMessage != nil {
+		s.OnMessage(msg)
+	}
+}
+
+// trimPath extracts the address from a MAIL FROM:<a@b> or RCPT TO:<a@b> argument
+func trimPath(arg, prefix string) string {
+	if len(arg) >= len(prefix) && strings.EqualFold(arg[:len(prefix)], prefix) {
+		arg = arg[len(prefix):]
+	}
+	if i := strings.IndexByte(arg, ' '); i >= 0 {
+		arg = arg[:i] // Drop parameters such as BODY=8BITMIME
+	}
+	return strings.Trim(arg, "<>")
+}
@@ -35,18 +35,20 @@ func (h *Handler) Routes() chi.Router {
 
 // NotificationResponse represents the response for a notification
 type NotificationResponse struct {
-	ID                int64   `json:"id"`
-	Message           string  `json:"message"`
-	IsRead            bool    `json:"is_read"`
-	RelatedEntityType *string `json:"related_entity_type,omitempty"`
-	RelatedEntityID   *int64  `json:"related_entity_id,omitempty"`
-	CreatedAt         string  `json:"created_at"`
+	ID                int64            `json:"id"`
+	Type              NotificationType `json:"type,omitempty"`
+	Message           string           `json:"message"`
+	IsRead            bool             `json:"is_read"`
+	RelatedEntityType *string          `json:"related_entity_type,omitempty"`
+	RelatedEntityID   *int64           `json:"related_entity_id,omitempty"`
+	CreatedAt         string           `json:"created_at"`
 }
 
 // ToResponse converts a Notification to a NotificationResponse
-func toResponse(n *Notification) *NotificationResponse {
+func (n *Notification) ToResponse() *NotificationResponse {
 	return &NotificationResponse{
 		ID:                n.ID,
+		Type:              n.Type,
 		Message:           n.Message,
 		IsRead:            n.IsRead,
 		RelatedEntityType: n.RelatedEntityType,
@@ -81,7 +83,7 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 
 	notificationResponses := make([]*NotificationResponse, len(notifications))
 	for i, n := range notifications {
-		notificationResponses[i] = toResponse(n)
+		notificationResponses[i] = n.ToResponse()
 	}
 
 	totalPages := (total + perPage - 1) / perPage
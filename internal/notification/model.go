@@ -4,13 +4,14 @@ import "time"
 
 // Notification represents a notification in the system
 type Notification struct {
-	ID                int64     `json:"id"`
-	RecipientID       int64     `json:"recipient_id"`
-	Message           string    `json:"message"`
-	IsRead            bool      `json:"is_read"`
-	RelatedEntityType *string   `json:"related_entity_type,omitempty"` // e.g., "SETTLEMENT", "EXPENSE", "GROUP"
-	RelatedEntityID   *int64    `json:"related_entity_id,omitempty"`
-	CreatedAt         time.Time `json:"created_at"`
+	ID                int64            `json:"id"`
+	RecipientID       int64            `json:"recipient_id"`
+	Type              NotificationType `json:"type,omitempty"` // Empty for notifications created before types were stored
+	Message           string           `json:"message"`
+	IsRead            bool             `json:"is_read"`
+	RelatedEntityType *string          `json:"related_entity_type,omitempty"` // e.g., "SETTLEMENT", "EXPENSE", "GROUP"
+	RelatedEntityID   *int64           `json:"related_entity_id,omitempty"`
+	CreatedAt         time.Time        `json:"created_at"`
 }
 
 // NotificationType represents the type of notification
@@ -23,4 +24,28 @@ const (
 	NotificationTypeSplitPaid      NotificationType = "SPLIT_PAID"
 	NotificationTypeSplitConfirmed NotificationType = "SPLIT_CONFIRMED"
 	NotificationTypeSettlement     NotificationType = "SETTLEMENT"
+	NotificationTypeBudgetAlert    NotificationType = "BUDGET_ALERT"
+	NotificationTypeReminder       NotificationType = "REMINDER"
 )
+
+// NotificationTypes lists every notification type, e.g. for delivery preferences
+var NotificationTypes = []NotificationType{
+	NotificationTypeGroupInvite,
+	NotificationTypeExpenseAdded,
+	NotificationTypeSplitAssigned,
+	NotificationTypeSplitPaid,
+	NotificationTypeSplitConfirmed,
+	NotificationTypeSettlement,
+	NotificationTypeBudgetAlert,
+	NotificationTypeReminder,
+}
+
+// Valid reports whether t is a known notification type
+func (t NotificationType) Valid() bool {
+	for _, known := range NotificationTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
@@ -17,17 +17,18 @@ func NewRepository(db *sql.DB) *Repository {
 }
 
 // Create inserts a new notification into the database
-func (r *Repository) Create(ctx context.Context, recipientID int64, message string, entityType *string, entityID *int64) (*Notification, error) {
+func (r *Repository) Create(ctx context.Context, recipientID int64, notificationType NotificationType, message string, entityType *string, entityID *int64) (*Notification, error) {
 	query := `
-		INSERT INTO notifications (recipient_id, message, related_entity_type, related_entity_id)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, recipient_id, message, is_read, related_entity_type, related_entity_id, created_at
+		INSERT INTO notifications (recipient_id, type, message, related_entity_type, related_entity_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, recipient_id, COALESCE(type, ''), message, is_read, related_entity_type, related_entity_id, created_at
 	`
 
 	notification := &Notification{}
-	err := r.db.QueryRowContext(ctx, query, recipientID, message, entityType, entityID).Scan(
+	err := r.db.QueryRowContext(ctx, query, recipientID, notificationType, message, entityType, entityID).Scan(
 		&notification.ID,
 		&notification.RecipientID,
+		&notification.Type,
 		&notification.Message,
 		&notification.IsRead,
 		&notification.RelatedEntityType,
@@ -41,10 +42,27 @@ func (r *Repository) Create(ctx context.Context, recipientID int64, message stri
 	return notification, nil
 }
 
+// GetRecipientLanguage returns the language a user wants notifications in, or
+// an empty string if the user doesn't exist
+func (r *Repository) GetRecipientLanguage(ctx context.Context, userID int64) (string, error) {
+	query := `SELECT language FROM users WHERE id = $1`
+
+	var lang string
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&lang)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get recipient language: %w", err)
+	}
+
+	return lang, nil
+}
+
 // GetByID retrieves a notification by its ID
 func (r *Repository) GetByID(ctx context.Context, id int64) (*Notification, error) {
 	query := `
-		SELECT id, recipient_id, message, is_read, related_entity_type, related_entity_id, created_at
+		SELECT id, recipient_id, COALESCE(type, ''), message, is_read, related_entity_type, related_entity_id, created_at
 		FROM notifications
 		WHERE id = $1
 	`
@@ -53,6 +71,7 @@ func (r *Repository) GetByID(ctx context.Context, id int64) (*Notification, erro
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&notification.ID,
 		&notification.RecipientID,
+		&notification.Type,
 		&notification.Message,
 		&notification.IsRead,
 		&notification.RelatedEntityType,
@@ -83,7 +102,7 @@ func (r *Repository) ListByRecipientID(ctx context.Context, recipientID int64, l
 
 	// Get notifications
 	query := `
-		SELECT id, recipient_id, message, is_read, related_entity_type, related_entity_id, created_at
+		SELECT id, recipient_id, COALESCE(type, ''), message, is_read, related_entity_type, related_entity_id, created_at
 		FROM notifications
 		WHERE recipient_id = $1
 	`
@@ -104,6 +123,7 @@ func (r *Repository) ListByRecipientID(ctx context.Context, recipientID int64, l
 		if err := rows.Scan(
 			&notification.ID,
 			&notification.RecipientID,
+			&notification.Type,
 			&notification.Message,
 			&notification.IsRead,
 			&notification.RelatedEntityType,
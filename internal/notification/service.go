@@ -3,6 +3,10 @@ package notification
 import (
 	"context"
 	"errors"
+	"log"
+	"time"
+
+	"github.com/fkhayef/splitwise/pkg/i18n"
 )
 
 // Common errors
@@ -11,19 +15,39 @@ var (
 	ErrNotRecipient         = errors.New("not the recipient of this notification")
 )
 
+// Deliverer sends notifications outside the app, e.g. by email or push
+type Deliverer interface {
+	// Enqueue queues a new notification for delivery on the recipient's channels
+	Enqueue(ctx context.Context, n *Notification) error
+}
+
 // Service handles notification business logic
 type Service struct {
-	repo *Repository
+	repo      *Repository
+	deliverer Deliverer // nil when notifications are only shown in the app
 }
 
 // NewService creates a new notification service
-func NewService(repo *Repository) *Service {
-	return &Service{repo: repo}
+func NewService(repo *Repository, deliverer Deliverer) *Service {
+	return &Service{repo: repo, deliverer: deliverer}
 }
 
-// Create creates a new notification
-func (s *Service) Create(ctx context.Context, recipientID int64, message string, entityType *string, entityID *int64) (*Notification, error) {
-	return s.repo.Create(ctx, recipientID, message, entityType, entityID)
+// Create creates a new notification and queues it for delivery.
+// Delivery is best-effort: the notification exists once it is stored,
+// so failing to queue it is logged rather than returned to the caller.
+func (s *Service) Create(ctx context.Context, recipientID int64, notificationType NotificationType, message string, entityType *string, entityID *int64) (*Notification, error) {
+	notification, err := s.repo.Create(ctx, recipientID, notificationType, message, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.deliverer != nil {
+		if err := s.deliverer.Enqueue(ctx, notification); err != nil {
+			log.Printf("notification: failed to queue delivery of notification %d: %v", notification.ID, err)
+		}
+	}
+
+	return notification, nil
 }
 
 // GetByID retrieves a notification by its ID
@@ -77,32 +101,138 @@ func (s *Service) GetUnreadCount(ctx context.Context, userID int64) (int, error)
 	return s.repo.GetUnreadCount(ctx, userID)
 }
 
-// Helper methods for creating specific notification types
+// Helper methods for creating specific notification types. Messages are
+// rendered in the recipient's language when the notification is created.
+
+// language returns the language to write a recipient's notifications in
+func (s *Service) language(ctx context.Context, recipientID int64) (string, error) {
+	lang, err := s.repo.GetRecipientLanguage(ctx, recipientID)
+	if err != nil {
+		return "", err
+	}
+	if lang, err = i18n.Normalize(lang); err != nil {
+		return i18n.Default, nil
+	}
+	return lang, nil
+}
+
+// money formats an amount in the default currency for lang
+func money(lang string, amount float64) string {
+	return i18n.FormatMoney(lang, amount, i18n.DefaultCurrency)
+}
 
 // NotifyGroupInvite creates a notification for a group invitation
 func (s *Service) NotifyGroupInvite(ctx context.Context, recipientID int64, groupName string, groupID int64) (*Notification, error) {
-	message := "You have been invited to join group: " + groupName
+	lang, err := s.language(ctx, recipientID)
+	if err != nil {
+		return nil, err
+	}
+	message := i18n.T(lang, "notification.group_invite", groupName)
 	entityType := "GROUP"
-	return s.repo.Create(ctx, recipientID, message, &entityType, &groupID)
+	return s.Create(ctx, recipientID, NotificationTypeGroupInvite, message, &entityType, &groupID)
 }
 
 // NotifyExpenseAdded creates a notification for a new expense
 func (s *Service) NotifyExpenseAdded(ctx context.Context, recipientID int64, payerName string, amount float64, expenseID int64) (*Notification, error) {
-	message := payerName + " added an expense and you owe money"
+	lang, err := s.language(ctx, recipientID)
+	if err != nil {
+		return nil, err
+	}
+	message := i18n.T(lang, "notification.expense_added", payerName)
 	entityType := "EXPENSE"
-	return s.repo.Create(ctx, recipientID, message, &entityType, &expenseID)
+	return s.Create(ctx, recipientID, NotificationTypeExpenseAdded, message, &entityType, &expenseID)
 }
 
 // NotifySplitPaid creates a notification when someone marks a split as paid
 func (s *Service) NotifySplitPaid(ctx context.Context, recipientID int64, borrowerName string, splitID int64) (*Notification, error) {
-	message := borrowerName + " says they paid you. Please confirm."
+	lang, err := s.language(ctx, recipientID)
+	if err != nil {
+		return nil, err
+	}
+	message := i18n.T(lang, "notification.split_paid", borrowerName)
 	entityType := "SPLIT"
-	return s.repo.Create(ctx, recipientID, message, &entityType, &splitID)
+	return s.Create(ctx, recipientID, NotificationTypeSplitPaid, message, &entityType, &splitID)
 }
 
 // NotifySettlementCreated creates a notification for a new settlement
 func (s *Service) NotifySettlementCreated(ctx context.Context, recipientID int64, payerName string, amount float64, settlementID int64) (*Notification, error) {
-	message := payerName + " wants to settle up with you"
+	lang, err := s.language(ctx, recipientID)
+	if err != nil {
+		return nil, err
+	}
+	message := i18n.T(lang, "notification.settlement_created", payerName)
+	entityType := "SETTLEMENT"
+	return s.Create(ctx, recipientID, NotificationTypeSettlement, message, &entityType, &settlementID)
+}
+
+// NotifySettleReminder reminds a member to settle up before a temporary group ends
+func (s *Service) NotifySettleReminder(ctx context.Context, recipientID int64, groupName string, endDate time.Time, groupID int64) (*Notification, error) {
+	lang, err := s.language(ctx, recipientID)
+	if err != nil {
+		return nil, err
+	}
+	message := i18n.T(lang, "notification.settle_reminder", groupName, i18n.FormatDate(lang, endDate))
+	entityType := "GROUP"
+	return s.Create(ctx, recipientID, NotificationTypeReminder, message, &entityType, &groupID)
+}
+
+// NotifyBudgetThreshold tells a member their group has used a share of a monthly
+// budget. category is nil for the group's overall budget.
+func (s *Service) NotifyBudgetThreshold(ctx context.Context, recipientID int64, groupName string, category *string, month time.Time, threshold int, spent, limit float64, groupID int64) (*Notification, error) {
+	lang, err := s.language(ctx, recipientID)
+	if err != nil {
+		return nil, err
+	}
+
+	budgetName := i18n.T(lang, "budget.overall")
+	if category != nil {
+		budgetName = i18n.T(lang, "budget.category", *category)
+	}
+
+	var message string
+	if threshold >= 100 {
+		message = i18n.T(lang, "notification.budget_over", groupName, budgetName, i18n.FormatMonth(lang, month),
+			money(lang, spent), money(lang, limit))
+	} else {
+		message = i18n.T(lang, "notification.budget_threshold", groupName, i18n.FormatPercent(lang, threshold), budgetName,
+			i18n.FormatMonth(lang, month), money(lang, spent), money(lang, limit))
+	}
+	entityType := "GROUP"
+	return s.Create(ctx, recipientID, NotificationTypeBudgetAlert, message, &entityType, &groupID)
+}
+
+// NotifySplitReminder reminds a borrower of a split they still owe. fromName is
+// the person who sent the reminder, or empty for a scheduled reminder.
+func (s *Service) NotifySplitReminder(ctx context.Context, recipientID int64, fromName, creditorName, description string, amount float64, splitID int64) (*Notification, error) {
+	lang, err := s.language(ctx, recipientID)
+	if err != nil {
+		return nil, err
+	}
+
+	var message string
+	if fromName != "" {
+		message = i18n.T(lang, "notification.split_reminder_nudge", fromName, money(lang, amount), description)
+	} else {
+		message = i18n.T(lang, "notification.split_reminder", creditorName, money(lang, amount), description)
+	}
+	entityType := "SPLIT"
+	return s.Create(ctx, recipientID, NotificationTypeReminder, message, &entityType, &splitID)
+}
+
+// NotifySettlementReminder reminds a payer of a settlement they haven't paid yet.
+// fromName is the person who sent the reminder, or empty for a scheduled reminder.
+func (s *Service) NotifySettlementReminder(ctx context.Context, recipientID int64, fromName, receiverName string, amount float64, settlementID int64) (*Notification, error) {
+	lang, err := s.language(ctx, recipientID)
+	if err != nil {
+		return nil, err
+	}
+
+	var message string
+	if fromName != "" {
+		message = i18n.T(lang, "notification.settlement_reminder_nudge", fromName, money(lang, amount))
+	} else {
+		message = i18n.T(lang, "notification.settlement_reminder", money(lang, amount), receiverName)
+	}
 	entityType := "SETTLEMENT"
-	return s.repo.Create(ctx, recipientID, message, &entityType, &settlementID)
+	return s.Create(ctx, recipientID, NotificationTypeReminder, message, &entityType, &settlementID)
 }
@@ -0,0 +1,126 @@
+package payment
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Common errors
+var (
+	ErrInvalidMethod = errors.New("method must be one of CASH, BANK_TRANSFER, CARD or WALLET")
+	ErrInvalidPaidAt = errors.New("paid_at must be a YYYY-MM-DD date or an RFC 3339 time, not in the future")
+	ErrReferenceLong = errors.New("reference must be at most 100 characters")
+	ErrNoteLong      = errors.New("note must be at most 500 characters")
+)
+
+// Method is how money moved outside the app
+type Method string
+
+const (
+	MethodCash         Method = "CASH"
+	MethodBankTransfer Method = "BANK_TRANSFER"
+	MethodCard         Method = "CARD"
+	MethodWallet       Method = "WALLET"
+)
+
+// Valid reports whether m is a known payment method
+func (m Method) Valid() bool {
+	switch m {
+	case MethodCash, MethodBankTransfer, MethodCard, MethodWallet:
+		return true
+	}
+	return false
+}
+
+// Details records how a split or settlement was paid.
+// Every field is nil until it is marked as paid.
+type Details struct {
+	Method    *Method    `json:"payment_method,omitempty"`
+	Reference *string    `json:"payment_reference,omitempty"` // External transaction ID
+	Note      *string    `json:"payment_note,omitempty"`
+	PaidAt    *time.Time `json:"paid_at,omitempty"`
+}
+
+// Request represents the optional body of a "mark as paid" request
+type Request struct {
+	Method    *string `json:"method,omitempty" validate:"omitempty,oneof=CASH BANK_TRANSFER CARD WALLET"`
+	Reference *string `json:"reference,omitempty" validate:"omitempty,max=100"`
+	Note      *string `json:"note,omitempty" validate:"omitempty,max=500"`
+	PaidAt    *string `json:"paid_at,omitempty"` // YYYY-MM-DD or RFC 3339; defaults to now
+}
+
+// Details validates the request and converts it to payment details.
+// Blank strings are treated as missing and paid_at defaults to now.
+func (r *Request) Details(now time.Time) (*Details, error) {
+	d := &Details{
+		Reference: trimmed(r.Reference),
+		Note:      trimmed(r.Note),
+	}
+	if d.Reference != nil && len(*d.Reference) > 100 {
+		return nil, ErrReferenceLong
+	}
+	if d.Note != nil && len(*d.Note) > 500 {
+		return nil, ErrNoteLong
+	}
+
+	if method := trimmed(r.Method); method != nil {
+		m := Method(strings.ToUpper(*method))
+		if !m.Valid() {
+			return nil, ErrInvalidMethod
+		}
+		d.Method = &m
+	}
+
+	paidAt := now
+	if s := trimmed(r.PaidAt); s != nil {
+		t, err := time.Parse(time.RFC3339, *s)
+		if err != nil {
+			if t, err = time.Parse("2006-01-02", *s); err != nil {
+				return nil, ErrInvalidPaidAt
+			}
+		}
+		if t.After(now) {
+			return nil, ErrInvalidPaidAt
+		}
+		paidAt = t
+	}
+	paidAt = paidAt.UTC()
+	d.PaidAt = &paidAt
+
+	return d, nil
+}
+
+// DetailsResponse represents payment details in API responses
+type DetailsResponse struct {
+	PaymentMethod    *Method `json:"payment_method,omitempty"`
+	PaymentReference *string `json:"payment_reference,omitempty"`
+	PaymentNote      *string `json:"payment_note,omitempty"`
+	PaidAt           *string `json:"paid_at,omitempty"`
+}
+
+// ToResponse converts payment details to a DetailsResponse DTO
+func (d *Details) ToResponse() DetailsResponse {
+	resp := DetailsResponse{
+		PaymentMethod:    d.Method,
+		PaymentReference: d.Reference,
+		PaymentNote:      d.Note,
+	}
+	if d.PaidAt != nil {
+		paidAt := d.PaidAt.Format("2006-01-02T15:04:05Z")
+		resp.PaidAt = &paidAt
+	}
+	return resp
+}
+
+// trimmed returns s without surrounding spaces, or nil if that leaves it empty
+func trimmed(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	t := strings.TrimSpace(*s)
+	if t == "" {
+		return nil
+	}
+	return &t
+}
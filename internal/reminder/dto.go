@@ -0,0 +1,21 @@
+package reminder
+
+// UpdateSettingsRequest changes a user's reminder settings; omitted fields are kept
+type UpdateSettingsRequest struct {
+	Automatic *bool `json:"automatic,omitempty"`
+	Nudges    *bool `json:"nudges,omitempty"`
+}
+
+// SettingsResponse represents a user's reminder settings in API responses
+type SettingsResponse struct {
+	Automatic bool `json:"automatic"`
+	Nudges    bool `json:"nudges"`
+}
+
+// ToResponse converts Settings to SettingsResponse
+func (s *Settings) ToResponse() *SettingsResponse {
+	return &SettingsResponse{
+		Automatic: s.Automatic,
+		Nudges:    s.Nudges,
+	}
+}
@@ -0,0 +1,122 @@
+package reminder
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/fkhayef/splitwise/pkg/middleware"
+	"github.com/fkhayef/splitwise/pkg/response"
+)
+
+// Handler handles HTTP requests for reminders
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new reminder handler with service dependency injected
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Reminder endpoints live under the users, expenses and settlements routers,
+// so main mounts these handlers there instead of a router of their own.
+
+// GetSettings handles GET /users/me/reminder-settings
+func (h *Handler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	settings, err := h.service.GetSettings(r.Context(), userID)
+	if err != nil {
+		h.handleError(w, err, "Failed to get reminder settings")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, settings.ToResponse())
+}
+
+// UpdateSettings handles PUT /users/me/reminder-settings
+func (h *Handler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	var req UpdateSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(r.Context(), userID, &req)
+	if err != nil {
+		h.handleError(w, err, "Failed to update reminder settings")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, settings.ToResponse())
+}
+
+// RemindSplit handles POST /expenses/splits/{splitId}/remind
+func (h *Handler) RemindSplit(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	splitID, err := strconv.ParseInt(chi.URLParam(r, "splitId"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid split ID")
+		return
+	}
+
+	if err := h.service.NudgeSplit(r.Context(), splitID, userID); err != nil {
+		h.handleError(w, err, "Failed to send reminder")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{"message": "Reminder sent"})
+}
+
+// RemindSettlement handles POST /settlements/{id}/remind
+func (h *Handler) RemindSettlement(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	settlementID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid settlement ID")
+		return
+	}
+
+	if err := h.service.NudgeSettlement(r.Context(), settlementID, userID); err != nil {
+		h.handleError(w, err, "Failed to send reminder")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{"message": "Reminder sent"})
+}
+
+// handleError maps reminder errors to HTTP responses
+func (h *Handler) handleError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrSplitNotFound), errors.Is(err, ErrSettlementNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, ErrNotCreditor):
+		response.Forbidden(w, err.Error())
+	case errors.Is(err, ErrNothingOwed), errors.Is(err, ErrNudgesDisabled):
+		response.Conflict(w, err.Error())
+	case errors.Is(err, ErrTooSoon):
+		response.TooManyRequests(w, err.Error())
+	default:
+		response.InternalError(w, fallback)
+	}
+}
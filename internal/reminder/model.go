@@ -0,0 +1,32 @@
+package reminder
+
+import "time"
+
+// Entity types a reminder can be about
+const (
+	EntitySplit      = "SPLIT"
+	EntitySettlement = "SETTLEMENT"
+)
+
+// Settings are a user's reminder preferences. Users without a row get the defaults.
+type Settings struct {
+	UserID    int64     `json:"user_id"`
+	Automatic bool      `json:"automatic"` // Scheduled reminders about open debts
+	Nudges    bool      `json:"nudges"`    // Reminders sent by the people they owe
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DefaultSettings returns the settings of a user who hasn't changed them
+func DefaultSettings(userID int64) *Settings {
+	return &Settings{UserID: userID, Automatic: true, Nudges: true}
+}
+
+// Due is an open split or settlement whose debtor should be reminded
+type Due struct {
+	EntityType   string
+	EntityID     int64
+	DebtorID     int64
+	CreditorName string
+	Amount       float64
+	Description  string // Expense description; empty for settlements
+}
@@ -0,0 +1,202 @@
+package reminder
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/fkhayef/splitwise/internal/database"
+)
+
+// Repository handles reminder data persistence
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository creates a new reminder repository
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// GetSettings retrieves a user's reminder settings, or the defaults if they have none
+func (r *Repository) GetSettings(ctx context.Context, userID int64) (*Settings, error) {
+	query := `SELECT user_id, automatic, nudges, updated_at FROM reminder_settings WHERE user_id = $1`
+
+	settings := &Settings{}
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&settings.UserID,
+		&settings.Automatic,
+		&settings.Nudges,
+		&settings.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return DefaultSettings(userID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reminder settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// SaveSettings creates or replaces a user's reminder settings
+func (r *Repository) SaveSettings(ctx context.Context, settings *Settings) (*Settings, error) {
+	query := `
+		INSERT INTO reminder_settings (user_id, automatic, nudges)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET automatic = EXCLUDED.automatic, nudges = EXCLUDED.nudges, updated_at = NOW()
+		RETURNING user_id, automatic, nudges, updated_at
+	`
+
+	saved := &Settings{}
+	err := r.db.QueryRowContext(ctx, query, settings.UserID, settings.Automatic, settings.Nudges).Scan(
+		&saved.UserID,
+		&saved.Automatic,
+		&saved.Nudges,
+		&saved.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save reminder settings: %w", err)
+	}
+
+	return saved, nil
+}
+
+// Record logs a reminder. fromUserID is nil for automatic reminders.
+func (r *Repository) Record(ctx context.Context, fromUserID *int64, toUserID int64, entityType string, entityID int64) error {
+	query := `
+		INSERT INTO reminders (from_user_id, to_user_id, entity_type, entity_id)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, fromUserID, toUserID, entityType, entityID); err != nil {
+		return fmt.Errorf("failed to record reminder: %w", err)
+	}
+	return nil
+}
+
+// RecordNudge records a nudge from one user to another unless the sender
+// already nudged them within cooldown. It returns the new reminder's ID, or 0
+// if it was too soon. The sender's row is locked so concurrent nudges queue
+// behind each other instead of both passing the check.
+func (r *Repository) RecordNudge(ctx context.Context, fromUserID, toUserID int64, entityType string, entityID int64, cooldown time.Duration) (int64, error) {
+	query := `
+		INSERT INTO reminders (from_user_id, to_user_id, entity_type, entity_id)
+		SELECT $1, $2, $3, $4
+		WHERE NOT EXISTS (
+		    SELECT 1 FROM reminders
+		    WHERE from_user_id = $1 AND to_user_id = $2
+		      AND sent_at > NOW() - make_interval(secs => $5)
+		)
+		RETURNING id
+	`
+
+	var id int64
+	err := database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		var locked int64
+		if err := tx.QueryRowContext(ctx, `SELECT id FROM users WHERE id = $1 FOR NO KEY UPDATE`, fromUserID).Scan(&locked); err != nil {
+			return err
+		}
+
+		err := tx.QueryRowContext(ctx, query, fromUserID, toUserID, entityType, entityID, cooldown.Seconds()).Scan(&id)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to record nudge: %w", err)
+	}
+	return id, nil
+}
+
+// Delete removes a reminder
+func (r *Repository) Delete(ctx context.Context, id int64) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM reminders WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete reminder: %w", err)
+	}
+	return nil
+}
+
+// ListDueSplits finds PENDING splits on expenses older than after whose borrower
+// wants automatic reminders and hasn't been reminded about them within every
+func (r *Repository) ListDueSplits(ctx context.Context, after, every time.Duration, limit int) ([]*Due, error) {
+	query := `
+		SELECT s.id, s.borrower_id, pu.username, s.amount_owed - s.amount_settled, e.description
+		FROM splits s
+		JOIN expenses e ON s.expense_id = e.id
+		JOIN users pu ON e.payer_id = pu.id
+		LEFT JOIN reminder_settings rs ON rs.user_id = s.borrower_id
+		WHERE s.status = 'PENDING'
+		  AND s.settlement_id IS NULL
+		  AND s.borrower_id != e.payer_id
+		  AND s.amount_owed > s.amount_settled
+		  AND e.created_at < NOW() - make_interval(secs => $1)
+		  AND COALESCE(rs.automatic, TRUE)
+		  AND NOT EXISTS (
+		      SELECT 1 FROM reminders rm
+		      WHERE rm.entity_type = 'SPLIT' AND rm.entity_id = s.id
+		        AND rm.sent_at > NOW() - make_interval(secs => $2)
+		  )
+		ORDER BY s.id
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, after.Seconds(), every.Seconds(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due splits: %w", err)
+	}
+	defer rows.Close()
+
+	var due []*Due
+	for rows.Next() {
+		d := &Due{EntityType: EntitySplit}
+		if err := rows.Scan(&d.EntityID, &d.DebtorID, &d.CreditorName, &d.Amount, &d.Description); err != nil {
+			return nil, fmt.Errorf("failed to scan due split: %w", err)
+		}
+		due = append(due, d)
+	}
+
+	return due, rows.Err()
+}
+
+// ListDueSettlements finds PENDING settlements older than after whose payer
+// wants automatic reminders and hasn't been reminded about them within every
+func (r *Repository) ListDueSettlements(ctx context.Context, after, every time.Duration, limit int) ([]*Due, error) {
+	query := `
+		SELECT st.id, st.payer_id, ru.username, st.amount
+		FROM settlements st
+		JOIN users ru ON st.receiver_id = ru.id
+		LEFT JOIN reminder_settings rs ON rs.user_id = st.payer_id
+		WHERE st.status = 'PENDING'
+		  AND st.amount > 0
+		  AND st.created_at < NOW() - make_interval(secs => $1)
+		  AND COALESCE(rs.automatic, TRUE)
+		  AND NOT EXISTS (
+		      SELECT 1 FROM reminders rm
+		      WHERE rm.entity_type = 'SETTLEMENT' AND rm.entity_id = st.id
+		        AND rm.sent_at > NOW() - make_interval(secs => $2)
+		  )
+		ORDER BY st.id
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, after.Seconds(), every.Seconds(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due settlements: %w", err)
+	}
+	defer rows.Close()
+
+	var due []*Due
+	for rows.Next() {
+		d := &Due{EntityType: EntitySettlement}
+		if err := rows.Scan(&d.EntityID, &d.DebtorID, &d.CreditorName, &d.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan due settlement: %w", err)
+		}
+		due = append(due, d)
+	}
+
+	return due, rows.Err()
+}
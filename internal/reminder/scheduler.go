@@ -0,0 +1,67 @@
+package reminder
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// batchSize caps how many reminders of each kind are sent per run
+const batchSize = 500
+
+// Scheduler periodically reminds debtors of splits and settlements that have
+// been open for a while, repeating at most once per repeat interval
+type Scheduler struct {
+	service  *Service
+	repo     *Repository
+	interval time.Duration
+	after    time.Duration // How long something has to be open before the first reminder
+	repeat   time.Duration // Minimum time between reminders about the same thing
+}
+
+// NewScheduler creates a new reminder scheduler
+func NewScheduler(service *Service, repo *Repository, interval, after, repeat time.Duration) *Scheduler {
+	return &Scheduler{
+		service:  service,
+		repo:     repo,
+		interval: interval,
+		after:    after,
+		repeat:   repeat,
+	}
+}
+
+// Start sends due reminders immediately and then on every interval
+// until the context is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.RunOnce(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce sends reminders for open splits and unpaid settlements
+func (s *Scheduler) RunOnce(ctx context.Context) {
+	splits, err := s.repo.ListDueSplits(ctx, s.after, s.repeat, batchSize)
+	if err != nil {
+		log.Printf("reminder scheduler: %v", err)
+	} else if sent := s.service.sendDue(ctx, splits); sent > 0 {
+		log.Printf("reminder scheduler: sent %d split reminders", sent)
+	}
+
+	settlements, err := s.repo.ListDueSettlements(ctx, s.after, s.repeat, batchSize)
+	if err != nil {
+		log.Printf("reminder scheduler: %v", err)
+	} else if sent := s.service.sendDue(ctx, settlements); sent > 0 {
+		log.Printf("reminder scheduler: sent %d settlement reminders", sent)
+	}
+}
@@ -0,0 +1,181 @@
+package reminder
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/fkhayef/splitwise/internal/expense"
+	"github.com/fkhayef/splitwise/internal/notification"
+	"github.com/fkhayef/splitwise/internal/settlement"
+)
+
+// Business logic errors
+var (
+	ErrSplitNotFound      = errors.New("split not found")
+	ErrSettlementNotFound = errors.New("settlement not found")
+	ErrNotCreditor        = errors.New("only the person owed can send a reminder")
+	ErrNothingOwed        = errors.New("nothing is owed on this anymore")
+	ErrNudgesDisabled     = errors.New("this user has turned off reminders")
+	ErrTooSoon            = errors.New("you have already reminded this user recently")
+)
+
+// Service handles reminder business logic
+type Service struct {
+	repo          *Repository
+	expenses      *expense.Repository
+	settlements   *settlement.Repository
+	notifications *notification.Service
+	cooldown      time.Duration // Minimum time between nudges from one user to another
+}
+
+// NewService creates a new reminder service
+func NewService(repo *Repository, expenses *expense.Repository, settlements *settlement.Repository, notifications *notification.Service, cooldown time.Duration) *Service {
+	return &Service{
+		repo:          repo,
+		expenses:      expenses,
+		settlements:   settlements,
+		notifications: notifications,
+		cooldown:      cooldown,
+	}
+}
+
+// GetSettings returns a user's reminder settings
+func (s *Service) GetSettings(ctx context.Context, userID int64) (*Settings, error) {
+	return s.repo.GetSettings(ctx, userID)
+}
+
+// UpdateSettings changes the given reminder settings, keeping the rest
+func (s *Service) UpdateSettings(ctx context.Context, userID int64, req *UpdateSettingsRequest) (*Settings, error) {
+	settings, err := s.repo.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Automatic != nil {
+		settings.Automatic = *req.Automatic
+	}
+	if req.Nudges != nil {
+		settings.Nudges = *req.Nudges
+	}
+
+	return s.repo.SaveSettings(ctx, settings)
+}
+
+// NudgeSplit lets an expense's payer remind a borrower of a split they still owe
+func (s *Service) NudgeSplit(ctx context.Context, splitID, userID int64) error {
+	split, err := s.expenses.GetSplitByID(ctx, splitID)
+	if err != nil {
+		return err
+	}
+	if split == nil {
+		return ErrSplitNotFound
+	}
+
+	exp, err := s.expenses.GetExpenseByID(ctx, split.ExpenseID)
+	if err != nil {
+		return err
+	}
+	if exp == nil {
+		return ErrSplitNotFound
+	}
+	if exp.PayerID != userID || split.BorrowerID == userID {
+		return ErrNotCreditor
+	}
+	if split.Status != expense.SplitStatusPending || split.SettlementID != nil || split.Remaining() <= 0 {
+		return ErrNothingOwed
+	}
+
+	id, err := s.recordNudge(ctx, userID, split.BorrowerID, EntitySplit, split.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.notifications.NotifySplitReminder(ctx, split.BorrowerID, exp.PayerUsername, exp.PayerUsername, exp.Description, split.Remaining(), split.ID); err != nil {
+		s.forgetNudge(ctx, id)
+		return err
+	}
+
+	return nil
+}
+
+// NudgeSettlement lets a settlement's receiver remind the payer to pay it
+func (s *Service) NudgeSettlement(ctx context.Context, settlementID, userID int64) error {
+	st, err := s.settlements.GetByID(ctx, settlementID)
+	if err != nil {
+		return err
+	}
+	if st == nil {
+		return ErrSettlementNotFound
+	}
+	if st.ReceiverID != userID {
+		return ErrNotCreditor
+	}
+	if st.Status != settlement.SettlementStatusPending || st.Amount <= 0 {
+		return ErrNothingOwed
+	}
+
+	id, err := s.recordNudge(ctx, userID, st.PayerID, EntitySettlement, st.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.notifications.NotifySettlementReminder(ctx, st.PayerID, st.ReceiverUsername, st.ReceiverUsername, st.Amount, st.ID); err != nil {
+		s.forgetNudge(ctx, id)
+		return err
+	}
+
+	return nil
+}
+
+// recordNudge makes sure the debtor accepts nudges and records the nudge,
+// failing if the same user already nudged them within the cooldown.
+// Recording before sending keeps two nudges at once from both going out.
+func (s *Service) recordNudge(ctx context.Context, fromUserID, toUserID int64, entityType string, entityID int64) (int64, error) {
+	settings, err := s.repo.GetSettings(ctx, toUserID)
+	if err != nil {
+		return 0, err
+	}
+	if !settings.Nudges {
+		return 0, ErrNudgesDisabled
+	}
+
+	id, err := s.repo.RecordNudge(ctx, fromUserID, toUserID, entityType, entityID, s.cooldown)
+	if err != nil {
+		return 0, err
+	}
+	if id == 0 {
+		return 0, ErrTooSoon
+	}
+	return id, nil
+}
+
+// forgetNudge removes a nudge that couldn't be sent so the user can try again
+func (s *Service) forgetNudge(ctx context.Context, id int64) {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		log.Printf("reminder: %v", err)
+	}
+}
+
+// sendDue sends automatic reminders for everything returned by the repository
+// queries. Failures are logged so one bad row doesn't hold up the rest.
+func (s *Service) sendDue(ctx context.Context, due []*Due) int {
+	sent := 0
+	for _, d := range due {
+		var err error
+		switch d.EntityType {
+		case EntitySplit:
+			_, err = s.notifications.NotifySplitReminder(ctx, d.DebtorID, "", d.CreditorName, d.Description, d.Amount, d.EntityID)
+		case EntitySettlement:
+			_, err = s.notifications.NotifySettlementReminder(ctx, d.DebtorID, "", d.CreditorName, d.Amount, d.EntityID)
+		}
+		if err == nil {
+			err = s.repo.Record(ctx, nil, d.DebtorID, d.EntityType, d.EntityID)
+		}
+		if err != nil {
+			log.Printf("reminder: failed to remind user %d about %s %d: %v", d.DebtorID, d.EntityType, d.EntityID, err)
+			continue
+		}
+		sent++
+	}
+	return sent
+}
@@ -1,9 +1,32 @@
 package settlement
 
+import (
+	"github.com/fkhayef/splitwise/internal/expense"
+	"github.com/fkhayef/splitwise/internal/payment"
+	"github.com/fkhayef/splitwise/pkg/i18n"
+)
+
 // CreateSettlementRequest represents the request to create a settlement
 type CreateSettlementRequest struct {
 	OtherUserID int64 `json:"other_user_id" validate:"required"` // The user you want to settle with
-	// Payer/Receiver roles and Amount are calculated automatically based on net balance
+	// Payer/Receiver roles are calculated automatically based on net balance.
+	// Amount defaults to the full net balance; less pays part of it.
+	Amount *float64 `json:"amount,omitempty" validate:"omitempty,gt=0"`
+}
+
+// MarkPaidRequest represents the optional request body to mark a settlement as paid
+type MarkPaidRequest struct {
+	payment.Request // How it was paid: method, reference, note and paid_at
+}
+
+// CreatePaymentIntentRequest represents the request to pay a settlement through a provider
+type CreatePaymentIntentRequest struct {
+	Provider string `json:"provider" validate:"required"`
+}
+
+// ReverseRequest represents the request to reverse a confirmed settlement
+type ReverseRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"` // e.g. "Bank transfer bounced"
 }
 
 // SettlementResponse represents the response for a settlement
@@ -16,15 +39,24 @@ type SettlementResponse struct {
 	Amount           float64          `json:"amount"`
 	CurrencyCode     string           `json:"currency_code"`
 	Status           SettlementStatus `json:"status"`
+	Partial          bool             `json:"partial"`
 	CreatedAt        string           `json:"created_at"`
+	payment.DetailsResponse
+}
+
+// GroupSettlementResponse represents a settlement in a group export
+type GroupSettlementResponse struct {
+	*SettlementResponse
+	GroupAmount float64 `json:"group_amount"` // Part of the settlement from this group's splits
 }
 
 // NetBalanceResponse represents the net balance with another user
 type NetBalanceResponse struct {
-	UserID   int64   `json:"user_id"`
-	Username string  `json:"username"`
-	Amount   float64 `json:"amount"`
-	Message  string  `json:"message"` // e.g., "You owe John $50" or "John owes you $30"
+	UserID    int64            `json:"user_id"`
+	Username  string           `json:"username"`
+	Amount    float64          `json:"amount"`
+	Message   string           `json:"message"`   // e.g., "You owe John $50" or "John owes you $30"
+	Breakdown BalanceBreakdown `json:"breakdown"` // Pending (= amount), in settlement, disputed and settled
 }
 
 // ToResponse converts a Settlement model to a SettlementResponse DTO
@@ -38,6 +70,170 @@ func (s *Settlement) ToResponse() *SettlementResponse {
 		Amount:           s.Amount,
 		CurrencyCode:     s.CurrencyCode,
 		Status:           s.Status,
+		Partial:          s.Partial,
 		CreatedAt:        s.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		DetailsResponse:  s.Payment.ToResponse(),
+	}
+}
+
+// PaymentIntentResponse represents a payment intent in API responses
+type PaymentIntentResponse struct {
+	ID               int64                 `json:"id"`
+	SettlementID     int64                 `json:"settlement_id"`
+	Provider         string                `json:"provider"`
+	ExternalID       string                `json:"external_id"`
+	Amount           float64               `json:"amount"`
+	CurrencyCode     string                `json:"currency_code"`
+	Status           IntentStatus          `json:"status"`
+	CheckoutURL      *string               `json:"checkout_url,omitempty"`
+	AmountReceived   *float64              `json:"amount_received,omitempty"`
+	CurrencyReceived *string               `json:"currency_received,omitempty"`
+	Reconciliation   *ReconciliationStatus `json:"reconciliation,omitempty"`
+	CreatedAt        string                `json:"created_at"`
+	UpdatedAt        string                `json:"updated_at"`
+}
+
+// ToResponse converts a PaymentIntent model to a PaymentIntentResponse DTO
+func (i *PaymentIntent) ToResponse() *PaymentIntentResponse {
+	return &PaymentIntentResponse{
+		ID:               i.ID,
+		SettlementID:     i.SettlementID,
+		Provider:         i.Provider,
+		ExternalID:       i.ExternalID,
+		Amount:           i.Amount,
+		CurrencyCode:     i.CurrencyCode,
+		Status:           i.Status,
+		CheckoutURL:      i.CheckoutURL,
+		AmountReceived:   i.AmountReceived,
+		CurrencyReceived: i.CurrencyReceived,
+		Reconciliation:   i.Reconciliation,
+		CreatedAt:        i.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:        i.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+// ReversalResponse represents a settlement reversal in API responses
+type ReversalResponse struct {
+	ID           int64            `json:"id"`
+	SettlementID int64            `json:"settlement_id"`
+	ReversedBy   int64            `json:"reversed_by"`
+	Reason       string           `json:"reason"`
+	CreatedAt    string           `json:"created_at"`
+	Splits       []*ReversedSplit `json:"splits"`
+}
+
+// ReverseResponse represents the result of reversing a settlement
+type ReverseResponse struct {
+	Settlement *SettlementResponse `json:"settlement"`
+	Reversal   *ReversalResponse   `json:"reversal"`
+}
+
+// ToResponse converts a Reversal model to a ReversalResponse DTO
+func (r *Reversal) ToResponse() *ReversalResponse {
+	splits := r.Splits
+	if splits == nil {
+		splits = []*ReversedSplit{}
+	}
+	return &ReversalResponse{
+		ID:           r.ID,
+		SettlementID: r.SettlementID,
+		ReversedBy:   r.ReversedBy,
+		Reason:       r.Reason,
+		CreatedAt:    r.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		Splits:       splits,
+	}
+}
+
+// PreviewResponse represents what a settlement with another user would contain
+type PreviewResponse struct {
+	Direction    PreviewDirection        `json:"direction"`
+	PayerID      *int64                  `json:"payer_id,omitempty"` // Omitted when there is nothing to settle
+	ReceiverID   *int64                  `json:"receiver_id,omitempty"`
+	Amount       float64                 `json:"amount"`
+	CurrencyCode string                  `json:"currency_code"`
+	YouOwe       []*PreviewSplitResponse `json:"you_owe"`
+	TheyOwe      []*PreviewSplitResponse `json:"they_owe"`
+	GroupBalance *float64                `json:"group_balance,omitempty"` // The group's part of the balance, positive when you owe them
+}
+
+// PreviewSplitResponse is a split a settlement would lock, with its expense details
+type PreviewSplitResponse struct {
+	*expense.SplitResponse
+	ExpenseDescription string `json:"expense_description"`
+	GroupID            int64  `json:"group_id"`
+}
+
+// ToResponse converts a Preview model to a PreviewResponse DTO
+func (p *Preview) ToResponse() *PreviewResponse {
+	resp := &PreviewResponse{
+		Direction:    p.Direction,
+		Amount:       p.Amount,
+		CurrencyCode: i18n.DefaultCurrency,
+		YouOwe:       previewSplits(p.YouOwe),
+		TheyOwe:      previewSplits(p.TheyOwe),
+		GroupBalance: p.GroupBalance,
+	}
+	if p.Direction != PreviewSettled {
+		resp.PayerID = &p.PayerID
+		resp.ReceiverID = &p.ReceiverID
+	}
+	return resp
+}
+
+// previewSplits converts splits with their expense details to response DTOs
+func previewSplits(splits []*expense.SplitWithExpense) []*PreviewSplitResponse {
+	items := make([]*PreviewSplitResponse, len(splits))
+	for i, s := range splits {
+		items[i] = &PreviewSplitResponse{
+			SplitResponse:      s.Split.ToResponse(),
+			ExpenseDescription: s.ExpenseDescription,
+			GroupID:            s.GroupID,
+		}
+	}
+	return items
+}
+
+// LedgerResponse represents the ledger between the caller and another user
+type LedgerResponse struct {
+	UserID  int64                  `json:"user_id"`
+	Balance float64                `json:"balance"` // Final running balance; equals the net balance
+	Entries []*LedgerEntryResponse `json:"entries"`
+}
+
+// LedgerEntryResponse represents one ledger entry in API responses
+type LedgerEntryResponse struct {
+	Date         string          `json:"date"`
+	Type         LedgerEntryType `json:"type"`
+	Description  string          `json:"description"`
+	Status       string          `json:"status"`
+	GroupID      *int64          `json:"group_id,omitempty"`
+	ExpenseID    *int64          `json:"expense_id,omitempty"`
+	SplitID      *int64          `json:"split_id,omitempty"`
+	SettlementID *int64          `json:"settlement_id,omitempty"`
+	Amount       float64         `json:"amount"`  // Positive = you owe them more
+	Balance      float64         `json:"balance"` // Running balance after this entry
+}
+
+// ledgerResponse converts a user pair's ledger entries to a LedgerResponse DTO
+func ledgerResponse(otherUserID int64, entries []*LedgerEntry) *LedgerResponse {
+	resp := &LedgerResponse{
+		UserID:  otherUserID,
+		Entries: make([]*LedgerEntryResponse, len(entries)),
+	}
+	for i, e := range entries {
+		resp.Entries[i] = &LedgerEntryResponse{
+			Date:         e.Date.Format("2006-01-02T15:04:05Z"),
+			Type:         e.Type,
+			Description:  e.Description,
+			Status:       e.Status,
+			GroupID:      e.GroupID,
+			ExpenseID:    e.ExpenseID,
+			SplitID:      e.SplitID,
+			SettlementID: e.SettlementID,
+			Amount:       e.Amount,
+			Balance:      e.Balance,
+		}
+		resp.Balance = e.Balance
 	}
+	return resp
 }
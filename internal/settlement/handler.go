@@ -3,11 +3,18 @@ package settlement
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/fkhayef/splitwise/internal/group"
+	"github.com/fkhayef/splitwise/pkg/export"
+	"github.com/fkhayef/splitwise/pkg/i18n"
 	"github.com/fkhayef/splitwise/pkg/middleware"
 	"github.com/fkhayef/splitwise/pkg/response"
 )
@@ -28,12 +35,20 @@ func (h *Handler) Routes() chi.Router {
 
 	r.Post("/", h.Create)
 	r.Get("/", h.List)
+	r.Get("/preview", h.Preview)
 	r.Get("/balances", h.GetNetBalances)
 	r.Get("/balances/{userId}", h.GetNetBalanceWithUser)
+	r.Get("/balances/{userId}/ledger", h.GetLedger)
+	r.Get("/payments/mismatched", h.ListMismatchedPayments)
 	r.Get("/{id}", h.GetByID)
 	r.Post("/{id}/pay", h.MarkAsPaid)
+	r.Post("/{id}/payment-intents", h.CreatePaymentIntent)
+	r.Get("/{id}/payment-intents", h.ListPaymentIntents)
 	r.Post("/{id}/confirm", h.Confirm)
 	r.Post("/{id}/reject", h.Reject)
+	r.Post("/{id}/cancel", h.Cancel)
+	r.Post("/{id}/reverse", h.Reverse)
+	r.Get("/{id}/reversal", h.GetReversal)
 
 	return r
 }
@@ -50,6 +65,10 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Invalid request body")
 		return
 	}
+	if req.Amount != nil && *req.Amount <= 0 {
+		response.BadRequest(w, "amount must be greater than zero")
+		return
+	}
 
 	settlement, err := h.service.CreateSettlement(r.Context(), payerID, &req)
 	if err != nil {
@@ -57,6 +76,10 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 			response.BadRequest(w, err.Error())
 			return
 		}
+		if errors.Is(err, ErrAmountExceedsBalance) || errors.Is(err, ErrAmountTooSmall) {
+			response.BadRequest(w, err.Error())
+			return
+		}
 		if errors.Is(err, ErrCannotSettleSelf) {
 			response.BadRequest(w, err.Error())
 			return
@@ -89,6 +112,50 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 	response.JSON(w, http.StatusOK, settlement.ToResponse())
 }
 
+// Preview handles GET /settlements/preview?other_user_id=&group_id=
+func (h *Handler) Preview(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	otherUserID, err := strconv.ParseInt(r.URL.Query().Get("other_user_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid other_user_id")
+		return
+	}
+
+	var groupID *int64
+	if raw := r.URL.Query().Get("group_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			response.BadRequest(w, "Invalid group_id")
+			return
+		}
+		groupID = &id
+	}
+
+	preview, err := h.service.Preview(r.Context(), userID, otherUserID, groupID)
+	if err != nil {
+		if errors.Is(err, ErrCannotSettleSelf) {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		if errors.Is(err, group.ErrGroupNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		if errors.Is(err, group.ErrNotAuthorized) {
+			response.Forbidden(w, "You are not a member of this group")
+			return
+		}
+		response.InternalError(w, "Failed to preview settlement")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, preview.ToResponse())
+}
+
 // List handles GET /settlements
 func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserID(r.Context())
@@ -141,7 +208,19 @@ func (h *Handler) MarkAsPaid(w http.ResponseWriter, r *http.Request) {
 		userID = 1
 	}
 
-	settlement, err := h.service.MarkAsPaid(r.Context(), id, userID)
+	// The body is optional; without one only the paid-at time is recorded
+	var req MarkPaidRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+	details, err := req.Details(time.Now())
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	settlement, err := h.service.MarkAsPaid(r.Context(), id, userID, details)
 	if err != nil {
 		if errors.Is(err, ErrSettlementNotFound) {
 			response.NotFound(w, err.Error())
@@ -218,6 +297,106 @@ func (h *Handler) Reject(w http.ResponseWriter, r *http.Request) {
 	response.JSON(w, http.StatusOK, settlement.ToResponse())
 }
 
+// Cancel handles POST /settlements/{id}/cancel
+func (h *Handler) Cancel(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid settlement ID")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	settlement, err := h.service.Cancel(r.Context(), id, userID)
+	if err != nil {
+		if errors.Is(err, ErrSettlementNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrNotCanceller) || errors.Is(err, ErrInvalidStatusChange) {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		response.InternalError(w, "Failed to cancel settlement")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, settlement.ToResponse())
+}
+
+// Reverse handles POST /settlements/{id}/reverse
+func (h *Handler) Reverse(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid settlement ID")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	var req ReverseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	settlement, reversal, err := h.service.Reverse(r.Context(), id, userID, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrSettlementNotFound):
+			response.NotFound(w, err.Error())
+		case errors.Is(err, ErrNotReceiver), errors.Is(err, ErrInvalidStatusChange),
+			errors.Is(err, ErrReasonRequired), errors.Is(err, ErrReasonTooLong):
+			response.BadRequest(w, err.Error())
+		case errors.Is(err, ErrReversalWindowEnded), errors.Is(err, ErrSettledElsewhere):
+			response.Conflict(w, err.Error())
+		default:
+			response.InternalError(w, "Failed to reverse settlement")
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, &ReverseResponse{
+		Settlement: settlement.ToResponse(),
+		Reversal:   reversal.ToResponse(),
+	})
+}
+
+// GetReversal handles GET /settlements/{id}/reversal
+func (h *Handler) GetReversal(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid settlement ID")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	reversal, err := h.service.GetReversal(r.Context(), id, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrSettlementNotFound), errors.Is(err, ErrReversalNotFound):
+			response.NotFound(w, err.Error())
+		case errors.Is(err, ErrNotParticipant):
+			response.Forbidden(w, err.Error())
+		default:
+			response.InternalError(w, "Failed to get settlement reversal")
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, reversal.ToResponse())
+}
+
 // GetNetBalances handles GET /settlements/balances
 func (h *Handler) GetNetBalances(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserID(r.Context())
@@ -247,7 +426,7 @@ func (h *Handler) GetNetBalanceWithUser(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	balance, err := h.service.GetNetBalanceWithUser(r.Context(), userID, otherUserID, "User")
+	balance, err := h.service.GetNetBalanceWithUser(r.Context(), userID, otherUserID, i18n.T(i18n.Language(r.Context()), "balance.unknown_user"))
 	if err != nil {
 		response.InternalError(w, "Failed to get net balance")
 		return
@@ -255,3 +434,216 @@ func (h *Handler) GetNetBalanceWithUser(w http.ResponseWriter, r *http.Request)
 
 	response.JSON(w, http.StatusOK, balance)
 }
+
+// GetLedger handles GET /settlements/balances/{userId}/ledger
+func (h *Handler) GetLedger(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	otherUserID, err := strconv.ParseInt(chi.URLParam(r, "userId"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid user ID")
+		return
+	}
+
+	entries, err := h.service.GetLedger(r.Context(), userID, otherUserID)
+	if err != nil {
+		if errors.Is(err, ErrCannotSettleSelf) {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		response.InternalError(w, "Failed to get ledger")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, ledgerResponse(otherUserID, entries))
+}
+
+// settlementCSVHeader lists the columns of the settlements CSV export
+var settlementCSVHeader = []string{
+	"settlement_id", "created_at", "payer_id", "payer_username", "receiver_id", "receiver_username",
+	"amount", "group_amount", "currency_code", "status",
+}
+
+// ExportGroupSettlements handles GET /groups/{id}/export/settlements?format=csv|json.
+// It is mounted on the groups router by main, since groups can't depend on settlements.
+func (h *Handler) ExportGroupSettlements(w http.ResponseWriter, r *http.Request) {
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid group ID")
+		return
+	}
+
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	out := export.NewWriter(w, format, fmt.Sprintf("group-%d-settlements", groupID), settlementCSVHeader)
+
+	err = h.service.ExportGroupSettlements(r.Context(), groupID, func(gs *GroupSettlement) error {
+		if format == export.FormatJSON {
+			return out.WriteJSON(&GroupSettlementResponse{
+				SettlementResponse: gs.ToResponse(),
+				GroupAmount:        gs.GroupAmount,
+			})
+		}
+		return out.WriteCSV([]string{
+			strconv.FormatInt(gs.ID, 10),
+			gs.CreatedAt.Format("2006-01-02T15:04:05Z"),
+			strconv.FormatInt(gs.PayerID, 10),
+			gs.PayerUsername,
+			strconv.FormatInt(gs.ReceiverID, 10),
+			gs.ReceiverUsername,
+			strconv.FormatFloat(gs.Amount, 'f', 2, 64),
+			strconv.FormatFloat(gs.GroupAmount, 'f', 2, 64),
+			gs.CurrencyCode,
+			string(gs.Status),
+		})
+	})
+	if err == nil {
+		err = out.Close()
+	}
+
+	if err != nil {
+		if out.Started() {
+			// Headers are already sent; the client sees a truncated file
+			log.Printf("settlement: export for group %d failed: %v", groupID, err)
+			return
+		}
+		if errors.Is(err, group.ErrGroupNotFound) {
+			response.NotFound(w, err.Error())
+			return
+		}
+		response.InternalError(w, "Failed to export settlements")
+	}
+}
+
+// CreatePaymentIntent handles POST /settlements/{id}/payment-intents
+func (h *Handler) CreatePaymentIntent(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid settlement ID")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	var req CreatePaymentIntentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+	if req.Provider == "" {
+		response.BadRequest(w, "provider is required")
+		return
+	}
+
+	intent, err := h.service.CreatePaymentIntent(r.Context(), id, userID, req.Provider)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrSettlementNotFound):
+			response.NotFound(w, err.Error())
+		case errors.Is(err, ErrNotPayer):
+			response.Forbidden(w, err.Error())
+		case errors.Is(err, ErrUnknownProvider), errors.Is(err, ErrInvalidStatusChange), errors.Is(err, ErrNothingToPay):
+			response.BadRequest(w, err.Error())
+		case errors.Is(err, ErrPaymentStarted):
+			response.Conflict(w, err.Error())
+		default:
+			log.Printf("settlement: %v", err)
+			response.InternalError(w, "Failed to create payment intent")
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, intent.ToResponse())
+}
+
+// ListPaymentIntents handles GET /settlements/{id}/payment-intents
+func (h *Handler) ListPaymentIntents(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid settlement ID")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	intents, err := h.service.ListPaymentIntents(r.Context(), id, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrSettlementNotFound):
+			response.NotFound(w, err.Error())
+		case errors.Is(err, ErrNotParticipant):
+			response.Forbidden(w, err.Error())
+		default:
+			response.InternalError(w, "Failed to list payment intents")
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, intentResponses(intents))
+}
+
+// ListMismatchedPayments handles GET /settlements/payments/mismatched
+func (h *Handler) ListMismatchedPayments(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	intents, err := h.service.ListMismatchedPayments(r.Context(), userID)
+	if err != nil {
+		response.InternalError(w, "Failed to list mismatched payments")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, intentResponses(intents))
+}
+
+// PaymentWebhook handles POST /webhooks/payments/{provider}.
+// It is mounted outside /settlements by main since providers call it directly.
+func (h *Handler) PaymentWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	err = h.service.HandleWebhook(r.Context(), chi.URLParam(r, "provider"), r.Header, body)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUnknownProvider), errors.Is(err, ErrIntentNotFound):
+			response.NotFound(w, err.Error())
+		case errors.Is(err, ErrInvalidSignature):
+			response.Unauthorized(w, err.Error())
+		case errors.Is(err, ErrInvalidWebhook):
+			response.BadRequest(w, err.Error())
+		default:
+			log.Printf("settlement: webhook failed: %v", err)
+			response.InternalError(w, "Failed to process webhook")
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{"message": "Webhook processed"})
+}
+
+// intentResponses converts payment intents to DTOs
+func intentResponses(intents []*PaymentIntent) []*PaymentIntentResponse {
+	responses := make([]*PaymentIntentResponse, len(intents))
+	for i, intent := range intents {
+		responses[i] = intent.ToResponse()
+	}
+	return responses
+}
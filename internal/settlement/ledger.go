@@ -0,0 +1,138 @@
+package settlement
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/fkhayef/splitwise/pkg/i18n"
+)
+
+// GetLedger returns every split and settlement between two users in date order, each with
+// the running balance after it, so the net balance can be traced back to what made it up.
+// Entries are counted so the final balance equals the net balance: expenses add what was
+// borrowed, settlements take off what they paid, disputed splits and splits confirmed on
+// their own leave the balance, and rejected, cancelled or reversed settlements give back
+// what they took.
+func (s *Service) GetLedger(ctx context.Context, userID, otherUserID int64) ([]*LedgerEntry, error) {
+	if userID == otherUserID {
+		return nil, ErrCannotSettleSelf
+	}
+
+	splits, err := s.repo.ListPairSplits(ctx, userID, otherUserID)
+	if err != nil {
+		return nil, err
+	}
+	settlements, err := s.repo.ListPairSettlements(ctx, userID, otherUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*LedgerEntry
+	for _, split := range splits {
+		entries = append(entries, splitEntries(split)...)
+	}
+	for _, settlement := range settlements {
+		entries = append(entries, settlementEntries(i18n.Language(ctx), settlement, userID)...)
+	}
+
+	// Stable, so an expense stays ahead of what happened to it at the same instant
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Date.Before(entries[j].Date)
+	})
+
+	var balance float64
+	for _, e := range entries {
+		balance = math.Round((balance+e.Amount)*100) / 100
+		e.Balance = balance
+	}
+
+	return entries, nil
+}
+
+// splitEntries returns the ledger entries for one split: the debt, plus how it
+// left the balance if it was disputed or confirmed without a settlement
+func splitEntries(split *PairSplit) []*LedgerEntry {
+	sign := 1.0
+	if !split.YouOwe {
+		sign = -1
+	}
+	groupID, expenseID, splitID := split.GroupID, split.ExpenseID, split.SplitID
+
+	entries := []*LedgerEntry{{
+		Date:        split.ExpenseDate,
+		Type:        LedgerExpense,
+		Description: split.ExpenseDescription,
+		Status:      split.Status,
+		GroupID:     &groupID,
+		ExpenseID:   &expenseID,
+		SplitID:     &splitID,
+		Amount:      sign * split.AmountOwed,
+	}}
+
+	// Parts covered by partial settlements are taken off by those settlements' entries
+	remaining := math.Round((split.AmountOwed-split.AmountSettled)*100) / 100
+	var left LedgerEntryType
+	switch {
+	case split.Status == "DISPUTED":
+		left = LedgerSplitDisputed
+	case split.Status == "CONFIRMED" && split.SettlementID == nil:
+		left = LedgerSplitConfirmed
+	default:
+		return entries
+	}
+
+	return append(entries, &LedgerEntry{
+		Date:        split.UpdatedAt,
+		Type:        left,
+		Description: split.ExpenseDescription,
+		Status:      split.Status,
+		GroupID:     &groupID,
+		ExpenseID:   &expenseID,
+		SplitID:     &splitID,
+		Amount:      -sign * remaining,
+	})
+}
+
+// settlementEntries returns the ledger entries for one settlement, seen by userID
+// and described in lang
+func settlementEntries(lang string, settlement *PairSettlement, userID int64) []*LedgerEntry {
+	// Paying lowers what you owe; being paid lowers what they owe you
+	effect := -settlement.Amount
+	description := i18n.T(lang, "ledger.settlement_you_paid")
+	if settlement.PayerID != userID {
+		effect = settlement.Amount
+		description = i18n.T(lang, "ledger.settlement_paid_to_you")
+	}
+	settlementID := settlement.ID
+
+	entry := &LedgerEntry{
+		Date:         settlement.CreatedAt,
+		Type:         LedgerSettlement,
+		Description:  description,
+		Status:       string(settlement.Status),
+		SettlementID: &settlementID,
+		Amount:       effect,
+	}
+
+	switch settlement.Status {
+	case SettlementStatusRejected, SettlementStatusCancelled:
+		// Its splits were released, so it never counted
+		entry.Amount = 0
+	case SettlementStatusReversed:
+		reversedAt := settlement.CreatedAt
+		if settlement.ReversedAt != nil {
+			reversedAt = *settlement.ReversedAt
+		}
+		return []*LedgerEntry{entry, {
+			Date:         reversedAt,
+			Type:         LedgerSettlementReversed,
+			Description:  description,
+			Status:       string(settlement.Status),
+			SettlementID: &settlementID,
+			Amount:       -effect,
+		}}
+	}
+
+	return []*LedgerEntry{entry}
+}
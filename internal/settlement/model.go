@@ -1,6 +1,11 @@
 package settlement
 
-import "time"
+import (
+	"time"
+
+	"github.com/fkhayef/splitwise/internal/expense"
+	"github.com/fkhayef/splitwise/internal/payment"
+)
 
 // SettlementStatus represents the status of a settlement
 type SettlementStatus string
@@ -10,6 +15,8 @@ const (
 	SettlementStatusPaid      SettlementStatus = "PAID"
 	SettlementStatusConfirmed SettlementStatus = "CONFIRMED"
 	SettlementStatusRejected  SettlementStatus = "REJECTED"
+	SettlementStatusCancelled SettlementStatus = "CANCELLED"
+	SettlementStatusReversed  SettlementStatus = "REVERSED"
 )
 
 // Settlement represents a bulk payment between two users
@@ -20,16 +27,155 @@ type Settlement struct {
 	Amount       float64          `json:"amount"`        // The net amount
 	CurrencyCode string           `json:"currency_code"`
 	Status       SettlementStatus `json:"status"`
+	Partial      bool             `json:"partial"`       // Pays part of what was owed
 	CreatedAt    time.Time        `json:"created_at"`
+	Payment      payment.Details  `json:"payment"`       // Set when the payer marks it as paid
 
 	// Populated via JOIN
 	PayerUsername    string `json:"payer_username,omitempty"`
 	ReceiverUsername string `json:"receiver_username,omitempty"`
 }
 
+// GroupSettlement is a settlement seen from one group. Settlements net debts across
+// groups, so GroupAmount is the part of it made up of splits from this group.
+type GroupSettlement struct {
+	Settlement
+	GroupAmount float64 `json:"group_amount"`
+}
+
 // NetBalance represents the net amount owed between two users
 type NetBalance struct {
-	UserID   int64   `json:"user_id"`
-	Username string  `json:"username"`
-	Amount   float64 `json:"amount"` // Positive = you owe them, Negative = they owe you
+	UserID    int64            `json:"user_id"`
+	Username  string           `json:"username"`
+	Amount    float64          `json:"amount"` // Positive = you owe them, Negative = they owe you
+	Breakdown BalanceBreakdown `json:"breakdown"`
+}
+
+// BalanceBreakdown splits everything between two users by where it stands.
+// Each component has the same sign as NetBalance.Amount, which equals Pending.
+type BalanceBreakdown struct {
+	Pending      float64 `json:"pending"`       // Open debts a new settlement would settle
+	InSettlement float64 `json:"in_settlement"` // Covered by settlements awaiting confirmation
+	Disputed     float64 `json:"disputed"`      // In dispute; not part of the net balance
+	Settled      float64 `json:"settled"`       // Already settled and confirmed
+}
+
+// ReconciliationStatus compares what a provider collected with what was owed
+type ReconciliationStatus string
+
+const (
+	ReconciliationMatched          ReconciliationStatus = "MATCHED"
+	ReconciliationUnderpaid        ReconciliationStatus = "UNDERPAID"
+	ReconciliationOverpaid         ReconciliationStatus = "OVERPAID"
+	ReconciliationCurrencyMismatch ReconciliationStatus = "CURRENCY_MISMATCH"
+	ReconciliationSettlementClosed ReconciliationStatus = "SETTLEMENT_CLOSED" // Arrived after the settlement was confirmed, rejected or cancelled
+)
+
+// PreviewDirection says which way money would move if a settlement were created now
+type PreviewDirection string
+
+const (
+	PreviewYouPay  PreviewDirection = "YOU_PAY"  // You owe the other user
+	PreviewTheyPay PreviewDirection = "THEY_PAY" // The other user owes you
+	PreviewEven    PreviewDirection = "EVEN"     // Open debts cancel out; settling clears them at zero
+	PreviewSettled PreviewDirection = "SETTLED"  // Nothing to settle
+)
+
+// Preview is what a settlement between two users would contain, from one user's side
+type Preview struct {
+	Direction  PreviewDirection
+	PayerID    int64 // Zero when there is nothing to settle
+	ReceiverID int64
+	Amount     float64
+	YouOwe     []*expense.SplitWithExpense // Splits you borrowed on expenses they paid
+	TheyOwe    []*expense.SplitWithExpense // Splits they borrowed on expenses you paid
+	// Balance of one group's splits when the preview is narrowed to it,
+	// positive when you owe them
+	GroupBalance *float64
+}
+
+// Reversal records that a receiver reversed a confirmed settlement and why
+type Reversal struct {
+	ID           int64            `json:"id"`
+	SettlementID int64            `json:"settlement_id"`
+	ReversedBy   int64            `json:"reversed_by"`
+	Reason       string           `json:"reason"`
+	CreatedAt    time.Time        `json:"created_at"`
+	Splits       []*ReversedSplit `json:"splits"`
+}
+
+// ReversedSplit is the compensating record for one split a reversal reopened
+type ReversedSplit struct {
+	SplitID        int64   `json:"split_id"`
+	PreviousStatus string  `json:"previous_status"`
+	WasLocked      bool    `json:"was_locked"` // Locked to the settlement in full, or only partly covered
+	Amount         float64 `json:"amount"`     // Amount reopened on the split
+}
+
+// PaymentIntent is an attempt to pay a settlement through a payment provider
+type PaymentIntent struct {
+	ID               int64                 `json:"id"`
+	SettlementID     int64                 `json:"settlement_id"`
+	Provider         string                `json:"provider"`
+	ExternalID       string                `json:"external_id"`
+	Amount           float64               `json:"amount"`
+	CurrencyCode     string                `json:"currency_code"`
+	Status           IntentStatus          `json:"status"`
+	CheckoutURL      *string               `json:"checkout_url,omitempty"`
+	AmountReceived   *float64              `json:"amount_received,omitempty"`
+	CurrencyReceived *string               `json:"currency_received,omitempty"`
+	Reconciliation   *ReconciliationStatus `json:"reconciliation,omitempty"` // Set once the money arrives
+	CreatedAt        time.Time             `json:"created_at"`
+	UpdatedAt        time.Time             `json:"updated_at"`
+}
+
+// LedgerEntryType is the kind of event in a ledger between two users
+type LedgerEntryType string
+
+const (
+	LedgerExpense            LedgerEntryType = "EXPENSE"             // A split of a shared expense
+	LedgerSplitConfirmed     LedgerEntryType = "SPLIT_CONFIRMED"     // A split paid and confirmed on its own
+	LedgerSplitDisputed      LedgerEntryType = "SPLIT_DISPUTED"      // A split put in dispute, leaving the balance
+	LedgerSettlement         LedgerEntryType = "SETTLEMENT"          // A settlement, counted unless rejected or cancelled
+	LedgerSettlementReversed LedgerEntryType = "SETTLEMENT_REVERSED" // A confirmed settlement undone by its receiver
+)
+
+// LedgerEntry is one event between two users and its effect on the balance.
+// Amount and Balance follow NetBalance's sign: positive = you owe them.
+type LedgerEntry struct {
+	Date         time.Time
+	Type         LedgerEntryType
+	Description  string
+	Status       string // The split's or settlement's current status
+	GroupID      *int64
+	ExpenseID    *int64
+	SplitID      *int64
+	SettlementID *int64
+	Amount       float64 // Change to the balance
+	Balance      float64 // Running balance after this entry
+}
+
+// PairSplit is a split between two users, as read for their ledger
+type PairSplit struct {
+	SplitID            int64
+	ExpenseID          int64
+	GroupID            int64
+	ExpenseDescription string
+	ExpenseDate        time.Time
+	UpdatedAt          time.Time
+	Status             string
+	YouOwe             bool // You borrowed on an expense they paid
+	AmountOwed         float64
+	AmountSettled      float64
+	SettlementID       *int64
+}
+
+// PairSettlement is a settlement between two users, as read for their ledger
+type PairSettlement struct {
+	ID         int64
+	PayerID    int64
+	Amount     float64
+	Status     SettlementStatus
+	CreatedAt  time.Time
+	ReversedAt *time.Time
 }
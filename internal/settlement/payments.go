@@ -0,0 +1,276 @@
+package settlement
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fkhayef/splitwise/internal/payment"
+)
+
+// Payment provider errors
+var (
+	ErrUnknownProvider = errors.New("unknown payment provider")
+	ErrIntentNotFound  = errors.New("payment intent not found")
+	ErrNothingToPay    = errors.New("settlement has no amount to pay")
+	ErrNotParticipant  = errors.New("only the payer or receiver can view this settlement's payments")
+	ErrInvalidWebhook  = errors.New("invalid webhook payload")
+	ErrPaymentStarted  = errors.New("settlement already has a payment in progress or completed")
+)
+
+// CreatePaymentIntent starts paying a PENDING settlement through a provider (payer only).
+// The settlement moves on when the provider's webhooks report progress.
+func (s *Service) CreatePaymentIntent(ctx context.Context, settlementID, userID int64, providerName string) (*PaymentIntent, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	// The settlement's row lock keeps a concurrent request or a cancellation
+	// from slipping in between the check for a live intent and the insert
+	var intent *PaymentIntent
+	err := s.repo.InTx(ctx, func(tx *sql.Tx) error {
+		repo := s.repo.WithTx(tx)
+		if err := repo.LockSettlement(ctx, settlementID); err != nil {
+			return err
+		}
+
+		settlement, err := repo.GetByID(ctx, settlementID)
+		if err != nil {
+			return err
+		}
+		if settlement == nil {
+			return ErrSettlementNotFound
+		}
+		if settlement.PayerID != userID {
+			return ErrNotPayer
+		}
+		if settlement.Status != SettlementStatusPending {
+			return ErrInvalidStatusChange
+		}
+		if settlement.Amount <= 0 {
+			return ErrNothingToPay
+		}
+
+		// Only one payment at a time; the payer can try again once an intent fails
+		intents, err := repo.ListIntentsBySettlementID(ctx, settlementID)
+		if err != nil {
+			return err
+		}
+		for _, existing := range intents {
+			if existing.Status != IntentStatusFailed {
+				return ErrPaymentStarted
+			}
+		}
+
+		created, err := provider.CreateIntent(ctx, &IntentRequest{
+			SettlementID: settlement.ID,
+			Amount:       settlement.Amount,
+			CurrencyCode: settlement.CurrencyCode,
+			Description:  fmt.Sprintf("Settlement #%d to %s", settlement.ID, settlement.ReceiverUsername),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create %s payment intent: %w", providerName, err)
+		}
+
+		intent, err = repo.CreateIntent(ctx, settlement, providerName, created, provider.MapStatus(created.Status))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return intent, nil
+}
+
+// ListPaymentIntents returns a settlement's payment intents (payer or receiver only)
+func (s *Service) ListPaymentIntents(ctx context.Context, settlementID, userID int64) ([]*PaymentIntent, error) {
+	settlement, err := s.repo.GetByID(ctx, settlementID)
+	if err != nil {
+		return nil, err
+	}
+	if settlement == nil {
+		return nil, ErrSettlementNotFound
+	}
+	if settlement.PayerID != userID && settlement.ReceiverID != userID {
+		return nil, ErrNotParticipant
+	}
+
+	return s.repo.ListIntentsBySettlementID(ctx, settlementID)
+}
+
+// ListMismatchedPayments returns provider payments to the user that collected a
+// different amount or currency than the settlement asked for. Those settlements
+// stay PAID until the receiver confirms or rejects them.
+func (s *Service) ListMismatchedPayments(ctx context.Context, userID int64) ([]*PaymentIntent, error) {
+	return s.repo.ListMismatchedIntents(ctx, userID)
+}
+
+// HandleWebhook applies a provider's webhook to the payment intent and its settlement.
+// It is idempotent: redelivered events are ignored, and a settlement only ever moves
+// forward, PENDING→PAID when the payer has paid and PAID→CONFIRMED when the money
+// arrives. If the amount collected doesn't match, the settlement is left PAID and the
+// intent is flagged for the receiver to reconcile, as is money that arrives after the
+// settlement was confirmed, rejected or cancelled.
+func (s *Service) HandleWebhook(ctx context.Context, providerName string, header http.Header, body []byte) error {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return ErrUnknownProvider
+	}
+
+	event, err := provider.VerifyWebhook(header, body)
+	if err != nil {
+		if errors.Is(err, ErrInvalidSignature) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+	if event.EventID == "" || event.IntentID == "" {
+		return ErrInvalidWebhook
+	}
+
+	intent, err := s.repo.GetIntentByExternalID(ctx, providerName, event.IntentID)
+	if err != nil {
+		return err
+	}
+	if intent == nil {
+		return ErrIntentNotFound
+	}
+
+	isNew, err := s.repo.RecordWebhookEvent(ctx, providerName, event)
+	if err != nil {
+		return err
+	}
+	if !isNew {
+		return nil
+	}
+
+	if err := s.applyWebhook(ctx, provider, intent, event); err != nil {
+		// Forget the event so the provider's retry is applied
+		if ferr := s.repo.DeleteWebhookEvent(ctx, providerName, event.EventID); ferr != nil {
+			log.Printf("settlement: %v", ferr)
+		}
+		return err
+	}
+	return nil
+}
+
+// applyWebhook moves a payment intent and its settlement on to the event's status
+func (s *Service) applyWebhook(ctx context.Context, provider PaymentProvider, intent *PaymentIntent, event *WebhookEvent) error {
+	providerName := provider.Name()
+	status := provider.MapStatus(event.Status)
+	if !intentAdvances(intent.Status, status) {
+		return nil
+	}
+
+	settlement, err := s.repo.GetByID(ctx, intent.SettlementID)
+	if err != nil {
+		return err
+	}
+	if settlement == nil {
+		return ErrSettlementNotFound
+	}
+
+	switch status {
+	case IntentStatusProcessing:
+		if err := s.repo.UpdateIntentStatus(ctx, intent.ID, status); err != nil {
+			return err
+		}
+		_, err = s.markPaidByProvider(ctx, settlement, intent, event)
+		return err
+
+	case IntentStatusSucceeded:
+		result := ReconciliationSettlementClosed
+		if settlement.Status == SettlementStatusPending || settlement.Status == SettlementStatusPaid {
+			result = reconcile(settlement, event)
+		}
+		if _, err := s.repo.ReconcileIntent(ctx, intent.ID, event.Amount, event.CurrencyCode, result); err != nil {
+			return err
+		}
+		if settlement, err = s.markPaidByProvider(ctx, settlement, intent, event); err != nil {
+			return err
+		}
+		if result != ReconciliationMatched {
+			log.Printf("settlement: %s payment %s for settlement %d is %s (expected %.2f %s, got %.2f %s)",
+				providerName, intent.ExternalID, settlement.ID, result,
+				settlement.Amount, settlement.CurrencyCode, event.Amount, event.CurrencyCode)
+			return nil
+		}
+		if settlement.Status == SettlementStatusPaid {
+			_, err = s.confirm(ctx, settlement)
+		}
+		return err
+
+	case IntentStatusFailed:
+		// The settlement stays as it is so the payer can try again
+		return s.repo.UpdateIntentStatus(ctx, intent.ID, status)
+	}
+
+	return nil
+}
+
+// markPaidByProvider moves a PENDING settlement to PAID with the provider's reference.
+// Settlements in any other status are returned unchanged.
+func (s *Service) markPaidByProvider(ctx context.Context, settlement *Settlement, intent *PaymentIntent, event *WebhookEvent) (*Settlement, error) {
+	if settlement.Status != SettlementStatusPending {
+		return settlement, nil
+	}
+
+	paidAt := event.OccurredAt
+	if paidAt.IsZero() {
+		paidAt = time.Now()
+	}
+	paidAt = paidAt.UTC()
+	note := "Paid via " + intent.Provider
+
+	updated, err := s.repo.MarkPaid(ctx, settlement.ID, &payment.Details{
+		Reference: &intent.ExternalID,
+		Note:      &note,
+		PaidAt:    &paidAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	updated.PayerUsername = settlement.PayerUsername
+	updated.ReceiverUsername = settlement.ReceiverUsername
+	return updated, nil
+}
+
+// intentAdvances reports whether moving a payment intent from one status to
+// another is progress. Failed intents can still succeed (e.g. a retried card),
+// but nothing moves an intent back once its money has arrived.
+func intentAdvances(from, to IntentStatus) bool {
+	switch from {
+	case IntentStatusPending:
+		return to != IntentStatusPending
+	case IntentStatusProcessing:
+		return to == IntentStatusSucceeded || to == IntentStatusFailed
+	case IntentStatusFailed:
+		return to == IntentStatusProcessing || to == IntentStatusSucceeded
+	default:
+		return false
+	}
+}
+
+// reconcile compares what a provider collected with the settlement's amount
+func reconcile(settlement *Settlement, event *WebhookEvent) ReconciliationStatus {
+	if !strings.EqualFold(event.CurrencyCode, settlement.CurrencyCode) {
+		return ReconciliationCurrencyMismatch
+	}
+
+	diff := math.Round((event.Amount-settlement.Amount)*100) / 100
+	switch {
+	case diff < 0:
+		return ReconciliationUnderpaid
+	case diff > 0:
+		return ReconciliationOverpaid
+	default:
+		return ReconciliationMatched
+	}
+}
@@ -0,0 +1,121 @@
+package settlement
+
+import (
+	"context"
+	"math"
+
+	"github.com/fkhayef/splitwise/internal/expense"
+	"github.com/fkhayef/splitwise/internal/group"
+)
+
+// Preview shows what CreateSettlement would do between two users without writing
+// anything: who would pay whom, how much, and every split it would lock.
+// CreateSettlement settles across all groups, so a groupID (which the user must
+// belong to) only narrows the listed splits and adds the group's subtotal.
+func (s *Service) Preview(ctx context.Context, userID, otherUserID int64, groupID *int64) (*Preview, error) {
+	if userID == otherUserID {
+		return nil, ErrCannotSettleSelf
+	}
+	if groupID != nil {
+		if _, err := s.groups.GetByID(ctx, *groupID); err != nil {
+			return nil, err
+		}
+		joined, err := s.groups.IsJoinedMember(ctx, *groupID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !joined {
+			return nil, group.ErrNotAuthorized
+		}
+	}
+
+	// The same splits CreateSettlement locks, in both directions
+	youOwe, err := s.expenseRepo.GetPendingSplitsBetweenUsers(ctx, userID, otherUserID)
+	if err != nil {
+		return nil, err
+	}
+	theyOwe, err := s.expenseRepo.GetPendingSplitsBetweenUsers(ctx, otherUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &Preview{}
+	if preview.YouOwe, err = s.withExpenses(ctx, youOwe); err != nil {
+		return nil, err
+	}
+	if preview.TheyOwe, err = s.withExpenses(ctx, theyOwe); err != nil {
+		return nil, err
+	}
+	net := netOf(preview.YouOwe, preview.TheyOwe)
+
+	switch {
+	case net > 0:
+		preview.Direction, preview.PayerID, preview.ReceiverID, preview.Amount = PreviewYouPay, userID, otherUserID, net
+	case net < 0:
+		preview.Direction, preview.PayerID, preview.ReceiverID, preview.Amount = PreviewTheyPay, otherUserID, userID, -net
+	case len(preview.YouOwe) > 0 || len(preview.TheyOwe) > 0:
+		// Zero-amount settlement: you request, they confirm
+		preview.Direction, preview.PayerID, preview.ReceiverID = PreviewEven, userID, otherUserID
+	default:
+		preview.Direction = PreviewSettled
+	}
+
+	if groupID != nil {
+		preview.YouOwe = inGroup(preview.YouOwe, *groupID)
+		preview.TheyOwe = inGroup(preview.TheyOwe, *groupID)
+		subtotal := netOf(preview.YouOwe, preview.TheyOwe)
+		preview.GroupBalance = &subtotal
+	}
+
+	return preview, nil
+}
+
+// netOf returns the balance of two lists of splits, positive when you owe them
+// as in GetNetBalanceBetweenUsers
+func netOf(youOwe, theyOwe []*expense.SplitWithExpense) float64 {
+	var net float64
+	for _, split := range youOwe {
+		net += split.Remaining()
+	}
+	for _, split := range theyOwe {
+		net -= split.Remaining()
+	}
+	return math.Round(net*100) / 100
+}
+
+// inGroup keeps the splits on a group's expenses
+func inGroup(splits []*expense.SplitWithExpense, groupID int64) []*expense.SplitWithExpense {
+	result := []*expense.SplitWithExpense{}
+	for _, split := range splits {
+		if split.GroupID == groupID {
+			result = append(result, split)
+		}
+	}
+	return result
+}
+
+// withExpenses attaches each split's expense details
+func (s *Service) withExpenses(ctx context.Context, splits []*expense.Split) ([]*expense.SplitWithExpense, error) {
+	ids := make([]int64, len(splits))
+	for i, split := range splits {
+		ids[i] = split.ExpenseID
+	}
+	expenses, err := s.expenseRepo.GetExpensesByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*expense.SplitWithExpense{}
+	for _, split := range splits {
+		e, ok := expenses[split.ExpenseID]
+		if !ok {
+			continue
+		}
+		result = append(result, &expense.SplitWithExpense{
+			Split:              *split,
+			ExpenseDescription: e.Description,
+			GroupID:            e.GroupID,
+		})
+	}
+	return result, nil
+}
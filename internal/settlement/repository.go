@@ -4,35 +4,68 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
+
+	"github.com/fkhayef/splitwise/internal/database"
+	"github.com/fkhayef/splitwise/internal/payment"
 )
 
 // Repository handles settlement data persistence
 type Repository struct {
-	db *sql.DB
+	db   database.DBTX
+	conn *sql.DB
 }
 
 // NewRepository creates a new settlement repository
 func NewRepository(db *sql.DB) *Repository {
-	return &Repository{db: db}
+	return &Repository{db: db, conn: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx
+func (r *Repository) WithTx(tx *sql.Tx) *Repository {
+	return &Repository{db: tx, conn: r.conn}
+}
+
+// InTx runs fn in a transaction; bind repositories to it with WithTx
+func (r *Repository) InTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	return database.WithTx(ctx, r.conn, fn)
+}
+
+// LockSettlement locks a settlement's row until the transaction ends
+func (r *Repository) LockSettlement(ctx context.Context, id int64) error {
+	query := `SELECT id FROM settlements WHERE id = $1 FOR UPDATE`
+
+	var locked int64
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&locked)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to lock settlement: %w", err)
+	}
+	return nil
 }
 
 // Create inserts a new settlement into the database
-func (r *Repository) Create(ctx context.Context, payerID, receiverID int64, amount float64) (*Settlement, error) {
+func (r *Repository) Create(ctx context.Context, payerID, receiverID int64, amount float64, partial bool) (*Settlement, error) {
 	query := `
-		INSERT INTO settlements (payer_id, receiver_id, amount, currency_code, status)
-		VALUES ($1, $2, $3, 'SAR', $4)
-		RETURNING id, payer_id, receiver_id, amount, currency_code, status, created_at
+		INSERT INTO settlements (payer_id, receiver_id, amount, currency_code, status, is_partial)
+		VALUES ($1, $2, $3, 'SAR', $4, $5)
+		RETURNING id, payer_id, receiver_id, amount, currency_code, status, is_partial, created_at,
+		          payment_method, payment_reference, payment_note, paid_at
 	`
 
 	settlement := &Settlement{}
-	err := r.db.QueryRowContext(ctx, query, payerID, receiverID, amount, SettlementStatusPending).Scan(
+	err := r.db.QueryRowContext(ctx, query, payerID, receiverID, amount, SettlementStatusPending, partial).Scan(
 		&settlement.ID,
 		&settlement.PayerID,
 		&settlement.ReceiverID,
 		&settlement.Amount,
 		&settlement.CurrencyCode,
 		&settlement.Status,
+		&settlement.Partial,
 		&settlement.CreatedAt,
+		&settlement.Payment.Method,
+		&settlement.Payment.Reference,
+		&settlement.Payment.Note,
+		&settlement.Payment.PaidAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create settlement: %w", err)
@@ -44,7 +77,8 @@ func (r *Repository) Create(ctx context.Context, payerID, receiverID int64, amou
 // GetByID retrieves a settlement by its ID
 func (r *Repository) GetByID(ctx context.Context, id int64) (*Settlement, error) {
 	query := `
-		SELECT s.id, s.payer_id, s.receiver_id, s.amount, s.currency_code, s.status, s.created_at,
+		SELECT s.id, s.payer_id, s.receiver_id, s.amount, s.currency_code, s.status, s.is_partial, s.created_at,
+		       s.payment_method, s.payment_reference, s.payment_note, s.paid_at,
 		       p.username as payer_username, recv.username as receiver_username
 		FROM settlements s
 		JOIN users p ON s.payer_id = p.id
@@ -60,7 +94,12 @@ func (r *Repository) GetByID(ctx context.Context, id int64) (*Settlement, error)
 		&settlement.Amount,
 		&settlement.CurrencyCode,
 		&settlement.Status,
+		&settlement.Partial,
 		&settlement.CreatedAt,
+		&settlement.Payment.Method,
+		&settlement.Payment.Reference,
+		&settlement.Payment.Note,
+		&settlement.Payment.PaidAt,
 		&settlement.PayerUsername,
 		&settlement.ReceiverUsername,
 	)
@@ -88,7 +127,8 @@ func (r *Repository) ListByUserID(ctx context.Context, userID int64, limit, offs
 
 	// Get settlements
 	query := `
-		SELECT s.id, s.payer_id, s.receiver_id, s.amount, s.currency_code, s.status, s.created_at,
+		SELECT s.id, s.payer_id, s.receiver_id, s.amount, s.currency_code, s.status, s.is_partial, s.created_at,
+		       s.payment_method, s.payment_reference, s.payment_note, s.paid_at,
 		       p.username as payer_username, recv.username as receiver_username
 		FROM settlements s
 		JOIN users p ON s.payer_id = p.id
@@ -114,7 +154,12 @@ func (r *Repository) ListByUserID(ctx context.Context, userID int64, limit, offs
 			&settlement.Amount,
 			&settlement.CurrencyCode,
 			&settlement.Status,
+			&settlement.Partial,
 			&settlement.CreatedAt,
+			&settlement.Payment.Method,
+			&settlement.Payment.Reference,
+			&settlement.Payment.Note,
+			&settlement.Payment.PaidAt,
 			&settlement.PayerUsername,
 			&settlement.ReceiverUsername,
 		); err != nil {
@@ -126,13 +171,163 @@ func (r *Repository) ListByUserID(ctx context.Context, userID int64, limit, offs
 	return settlements, total, nil
 }
 
+// ListByReceiverAndStatus retrieves settlements a user is receiving with the given status, oldest first
+func (r *Repository) ListByReceiverAndStatus(ctx context.Context, receiverID int64, status SettlementStatus) ([]*Settlement, error) {
+	query := `
+		SELECT s.id, s.payer_id, s.receiver_id, s.amount, s.currency_code, s.status, s.is_partial, s.created_at,
+		       s.payment_method, s.payment_reference, s.payment_note, s.paid_at,
+		       p.username as payer_username, recv.username as receiver_username
+		FROM settlements s
+		JOIN users p ON s.payer_id = p.id
+		JOIN users recv ON s.receiver_id = recv.id
+		WHERE s.receiver_id = $1 AND s.status = $2
+		ORDER BY s.created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, receiverID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list settlements: %w", err)
+	}
+	defer rows.Close()
+
+	var settlements []*Settlement
+	for rows.Next() {
+		settlement := &Settlement{}
+		if err := rows.Scan(
+			&settlement.ID,
+			&settlement.PayerID,
+			&settlement.ReceiverID,
+			&settlement.Amount,
+			&settlement.CurrencyCode,
+			&settlement.Status,
+			&settlement.Partial,
+			&settlement.CreatedAt,
+			&settlement.Payment.Method,
+			&settlement.Payment.Reference,
+			&settlement.Payment.Note,
+			&settlement.Payment.PaidAt,
+			&settlement.PayerUsername,
+			&settlement.ReceiverUsername,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan settlement: %w", err)
+		}
+		settlements = append(settlements, settlement)
+	}
+
+	return settlements, rows.Err()
+}
+
+// StreamGroupSettlements calls fn for every settlement with splits in a group, oldest first.
+// Rows are read one at a time so large groups are never held in memory.
+func (r *Repository) StreamGroupSettlements(ctx context.Context, groupID int64, fn func(*GroupSettlement) error) error {
+	query := `
+		SELECT s.id, s.payer_id, s.receiver_id, s.amount, s.currency_code, s.status, s.is_partial, s.created_at,
+		       s.payment_method, s.payment_reference, s.payment_note, s.paid_at,
+		       p.username as payer_username, recv.username as receiver_username,
+		       ga.group_amount
+		FROM (
+			SELECT settlement_id, SUM(amount) AS group_amount
+			FROM (
+				-- Splits locked to a settlement, less what earlier partial settlements covered
+				SELECT sp.settlement_id, sp.amount_owed - sp.amount_settled AS amount
+				FROM splits sp
+				JOIN expenses e ON sp.expense_id = e.id
+				WHERE e.group_id = $1 AND sp.settlement_id IS NOT NULL
+				UNION ALL
+				-- Splits a partial settlement only partly covers
+				SELECT a.settlement_id, a.amount
+				FROM settlement_allocations a
+				JOIN splits sp ON a.split_id = sp.id
+				JOIN expenses e ON sp.expense_id = e.id
+				WHERE e.group_id = $1
+			) covered
+			GROUP BY settlement_id
+		) ga
+		JOIN settlements s ON s.id = ga.settlement_id
+		JOIN users p ON s.payer_id = p.id
+		JOIN users recv ON s.receiver_id = recv.id
+		ORDER BY s.created_at, s.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, groupID)
+	if err != nil {
+		return fmt.Errorf("failed to export settlements: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		gs := &GroupSettlement{}
+		if err := rows.Scan(
+			&gs.ID,
+			&gs.PayerID,
+			&gs.ReceiverID,
+			&gs.Amount,
+			&gs.CurrencyCode,
+			&gs.Status,
+			&gs.Partial,
+			&gs.CreatedAt,
+			&gs.Payment.Method,
+			&gs.Payment.Reference,
+			&gs.Payment.Note,
+			&gs.Payment.PaidAt,
+			&gs.PayerUsername,
+			&gs.ReceiverUsername,
+			&gs.GroupAmount,
+		); err != nil {
+			return fmt.Errorf("failed to scan settlement: %w", err)
+		}
+		if err := fn(gs); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// MarkPaid sets a settlement to PAID and records how it was paid
+func (r *Repository) MarkPaid(ctx context.Context, id int64, details *payment.Details) (*Settlement, error) {
+	query := `
+		UPDATE settlements
+		SET status = $2, payment_method = $3, payment_reference = $4, payment_note = $5, paid_at = $6
+		WHERE id = $1
+		RETURNING id, payer_id, receiver_id, amount, currency_code, status, is_partial, created_at,
+		          payment_method, payment_reference, payment_note, paid_at
+	`
+
+	settlement := &Settlement{}
+	err := r.db.QueryRowContext(ctx, query, id, SettlementStatusPaid, details.Method, details.Reference, details.Note, details.PaidAt).Scan(
+		&settlement.ID,
+		&settlement.PayerID,
+		&settlement.ReceiverID,
+		&settlement.Amount,
+		&settlement.CurrencyCode,
+		&settlement.Status,
+		&settlement.Partial,
+		&settlement.CreatedAt,
+		&settlement.Payment.Method,
+		&settlement.Payment.Reference,
+		&settlement.Payment.Note,
+		&settlement.Payment.PaidAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to mark settlement as paid: %w", err)
+	}
+
+	return settlement, nil
+}
+
 // UpdateStatus updates the status of a settlement
 func (r *Repository) UpdateStatus(ctx context.Context, id int64, status SettlementStatus) (*Settlement, error) {
 	query := `
 		UPDATE settlements
-		SET status = $2
+		SET status = $2,
+		    confirmed_at = CASE WHEN $2::text = 'CONFIRMED' THEN NOW() ELSE confirmed_at END
 		WHERE id = $1
-		RETURNING id, payer_id, receiver_id, amount, currency_code, status, created_at
+		RETURNING id, payer_id, receiver_id, amount, currency_code, status, is_partial, created_at,
+		          payment_method, payment_reference, payment_note, paid_at
 	`
 
 	settlement := &Settlement{}
@@ -143,7 +338,12 @@ func (r *Repository) UpdateStatus(ctx context.Context, id int64, status Settleme
 		&settlement.Amount,
 		&settlement.CurrencyCode,
 		&settlement.Status,
+		&settlement.Partial,
 		&settlement.CreatedAt,
+		&settlement.Payment.Method,
+		&settlement.Payment.Reference,
+		&settlement.Payment.Note,
+		&settlement.Payment.PaidAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -155,49 +355,96 @@ func (r *Repository) UpdateStatus(ctx context.Context, id int64, status Settleme
 	return settlement, nil
 }
 
-// GetNetBalancesForUser calculates net balances with all other users
-func (r *Repository) GetNetBalancesForUser(ctx context.Context, userID int64) ([]*NetBalance, error) {
-	// This query calculates the net balance:
-	// Positive = user owes them (they paid for user)
-	// Negative = they owe user (user paid for them)
-	query := `
-		WITH 
-		-- What user owes others (from expenses where others paid)
-		user_owes AS (
-			SELECT e.payer_id as other_user_id, SUM(s.amount_owed) as amount
+// balanceQuery breaks down everything between a user ($1) and each other user, or only
+// $2 if it isn't NULL, by where the splits stand. Every component follows the net balance's
+// sign: positive = user owes them (they paid for user), negative = they owe user.
+//   - pending:       open PENDING/PAID splits, what a new settlement would settle
+//   - in_settlement: splits locked to, or partly covered by, settlements not yet confirmed
+//   - disputed:      DISPUTED splits, left out of the net balance until resolved
+//   - settled:       confirmed splits and parts covered by confirmed settlements
+const balanceQuery = `
+		WITH
+		pair_splits AS (
+			-- What user owes others (from expenses where others paid)
+			SELECT e.payer_id AS other_user_id, 1 AS sign, s.id, s.status, s.amount_owed, s.amount_settled, s.settlement_id
 			FROM splits s
 			JOIN expenses e ON s.expense_id = e.id
-			WHERE s.borrower_id = $1 
-			  AND s.status IN ('PENDING', 'PAID')
-			  AND s.settlement_id IS NULL
-			GROUP BY e.payer_id
-		),
-		-- What others owe user (from expenses where user paid)
-		others_owe AS (
-			SELECT s.borrower_id as other_user_id, SUM(s.amount_owed) as amount
+			WHERE s.borrower_id = $1 AND e.payer_id != $1
+			UNION ALL
+			-- What others owe user (from expenses where user paid)
+			SELECT s.borrower_id, -1, s.id, s.status, s.amount_owed, s.amount_settled, s.settlement_id
 			FROM splits s
 			JOIN expenses e ON s.expense_id = e.id
-			WHERE e.payer_id = $1 
-			  AND s.status IN ('PENDING', 'PAID')
-			  AND s.settlement_id IS NULL
-			GROUP BY s.borrower_id
+			WHERE e.payer_id = $1 AND s.borrower_id != $1
+		),
+		-- Parts of splits covered by partial settlements that aren't confirmed yet
+		open_allocations AS (
+			SELECT a.split_id, SUM(a.amount) AS amount
+			FROM settlement_allocations a
+			JOIN settlements st ON a.settlement_id = st.id
+			WHERE st.status IN ('PENDING', 'PAID')
+			GROUP BY a.split_id
 		),
-		-- Combine and calculate net
-		net_balances AS (
-			SELECT 
-				COALESCE(uo.other_user_id, oo.other_user_id) as other_user_id,
-				COALESCE(uo.amount, 0) - COALESCE(oo.amount, 0) as net_amount
-			FROM user_owes uo
-			FULL OUTER JOIN others_owe oo ON uo.other_user_id = oo.other_user_id
+		components AS (
+			SELECT
+				ps.other_user_id,
+				COALESCE(SUM(ps.sign * (ps.amount_owed - ps.amount_settled))
+					FILTER (WHERE ps.status IN ('PENDING', 'PAID') AND ps.settlement_id IS NULL AND oa.split_id IS NULL), 0) AS pending,
+				-- Splits partly covered by an open partial settlement wait for it with the rest
+				COALESCE(SUM(ps.sign * (ps.amount_owed - ps.amount_settled))
+					FILTER (WHERE ps.status IN ('PENDING', 'PAID') AND (ps.settlement_id IS NOT NULL OR oa.split_id IS NOT NULL)), 0)
+					+ COALESCE(SUM(ps.sign * oa.amount), 0) AS in_settlement,
+				COALESCE(SUM(ps.sign * (ps.amount_owed - ps.amount_settled))
+					FILTER (WHERE ps.status = 'DISPUTED'), 0) AS disputed,
+				COALESCE(SUM(ps.sign * CASE
+					WHEN ps.status = 'CONFIRMED' THEN ps.amount_owed
+					ELSE ps.amount_settled - COALESCE(oa.amount, 0)
+				END), 0) AS settled
+			FROM pair_splits ps
+			LEFT JOIN open_allocations oa ON oa.split_id = ps.id
+			WHERE $2::int IS NULL OR ps.other_user_id = $2
+			GROUP BY ps.other_user_id
 		)
-		SELECT nb.other_user_id, u.username, nb.net_amount
-		FROM net_balances nb
-		JOIN users u ON nb.other_user_id = u.id
-		WHERE nb.net_amount != 0
-		ORDER BY ABS(nb.net_amount) DESC
+		SELECT c.other_user_id, u.username, c.pending, c.in_settlement, c.disputed, c.settled
+		FROM components c
+		JOIN users u ON c.other_user_id = u.id
+		ORDER BY ABS(c.pending) DESC, ABS(c.disputed) DESC, c.other_user_id
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, userID)
+// GetNetBalancesForUser calculates net balances with all other users, with their breakdown.
+// Users with nothing open, pending, in settlement or in dispute, are left out.
+func (r *Repository) GetNetBalancesForUser(ctx context.Context, userID int64) ([]*NetBalance, error) {
+	all, err := r.queryBalances(ctx, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var balances []*NetBalance
+	for _, b := range all {
+		if b.Breakdown.Pending != 0 || b.Breakdown.InSettlement != 0 || b.Breakdown.Disputed != 0 {
+			balances = append(balances, b)
+		}
+	}
+
+	return balances, nil
+}
+
+// GetBalanceWithUser calculates the net balance and its breakdown between two specific users.
+// The username is empty if they have never shared an expense.
+func (r *Repository) GetBalanceWithUser(ctx context.Context, userID, otherUserID int64) (*NetBalance, error) {
+	balances, err := r.queryBalances(ctx, userID, &otherUserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(balances) == 0 {
+		return &NetBalance{UserID: otherUserID}, nil
+	}
+	return balances[0], nil
+}
+
+// queryBalances runs balanceQuery
+func (r *Repository) queryBalances(ctx context.Context, userID int64, otherUserID *int64) ([]*NetBalance, error) {
+	rows, err := r.db.QueryContext(ctx, balanceQuery, userID, otherUserID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get net balances: %w", err)
 	}
@@ -206,36 +453,58 @@ func (r *Repository) GetNetBalancesForUser(ctx context.Context, userID int64) ([
 	var balances []*NetBalance
 	for rows.Next() {
 		balance := &NetBalance{}
-		if err := rows.Scan(&balance.UserID, &balance.Username, &balance.Amount); err != nil {
+		if err := rows.Scan(
+			&balance.UserID,
+			&balance.Username,
+			&balance.Breakdown.Pending,
+			&balance.Breakdown.InSettlement,
+			&balance.Breakdown.Disputed,
+			&balance.Breakdown.Settled,
+		); err != nil {
 			return nil, fmt.Errorf("failed to scan net balance: %w", err)
 		}
+		balance.Amount = balance.Breakdown.Pending
 		balances = append(balances, balance)
 	}
 
-	return balances, nil
+	return balances, rows.Err()
 }
 
 // GetNetBalanceBetweenUsers calculates the net balance between two specific users
+// over the splits a new settlement could take: splits held by a pending or paid
+// partial settlement are left out, as in expense.Repository.GetPendingSplitsBetweenUsers
 func (r *Repository) GetNetBalanceBetweenUsers(ctx context.Context, userID, otherUserID int64) (float64, error) {
 	query := `
 		WITH 
 		user_owes AS (
-			SELECT COALESCE(SUM(s.amount_owed), 0) as amount
+			SELECT COALESCE(SUM(s.amount_owed - s.amount_settled), 0) as amount
 			FROM splits s
 			JOIN expenses e ON s.expense_id = e.id
 			WHERE s.borrower_id = $1 
 			  AND e.payer_id = $2
 			  AND s.status IN ('PENDING', 'PAID')
 			  AND s.settlement_id IS NULL
+			  AND NOT EXISTS (
+			      SELECT 1
+			      FROM settlement_allocations a
+			      JOIN settlements st ON a.settlement_id = st.id
+			      WHERE a.split_id = s.id AND st.status IN ('PENDING', 'PAID')
+			  )
 		),
 		other_owes AS (
-			SELECT COALESCE(SUM(s.amount_owed), 0) as amount
+			SELECT COALESCE(SUM(s.amount_owed - s.amount_settled), 0) as amount
 			FROM splits s
 			JOIN expenses e ON s.expense_id = e.id
 			WHERE s.borrower_id = $2 
 			  AND e.payer_id = $1
 			  AND s.status IN ('PENDING', 'PAID')
 			  AND s.settlement_id IS NULL
+			  AND NOT EXISTS (
+			      SELECT 1
+			      FROM settlement_allocations a
+			      JOIN settlements st ON a.settlement_id = st.id
+			      WHERE a.split_id = s.id AND st.status IN ('PENDING', 'PAID')
+			  )
 		)
 		SELECT (SELECT amount FROM user_owes) - (SELECT amount FROM other_owes)
 	`
@@ -247,3 +516,324 @@ func (r *Repository) GetNetBalanceBetweenUsers(ctx context.Context, userID, othe
 
 	return netAmount, nil
 }
+
+// intentColumns are the payment_intents columns read by scanIntent
+const intentColumns = `id, settlement_id, provider, external_id, amount, currency_code, status, checkout_url,
+		       amount_received, currency_received, reconciliation, created_at, updated_at`
+
+// scanIntent reads a payment intent selected with intentColumns
+func scanIntent(row interface{ Scan(...any) error }) (*PaymentIntent, error) {
+	intent := &PaymentIntent{}
+	err := row.Scan(
+		&intent.ID,
+		&intent.SettlementID,
+		&intent.Provider,
+		&intent.ExternalID,
+		&intent.Amount,
+		&intent.CurrencyCode,
+		&intent.Status,
+		&intent.CheckoutURL,
+		&intent.AmountReceived,
+		&intent.CurrencyReceived,
+		&intent.Reconciliation,
+		&intent.CreatedAt,
+		&intent.UpdatedAt,
+	)
+	return intent, err
+}
+
+// CreateIntent stores a payment intent created by a provider
+func (r *Repository) CreateIntent(ctx context.Context, settlement *Settlement, provider string, created *ProviderIntent, status IntentStatus) (*PaymentIntent, error) {
+	query := `
+		INSERT INTO payment_intents (settlement_id, provider, external_id, amount, currency_code, status, checkout_url)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
+		RETURNING ` + intentColumns
+
+	intent, err := scanIntent(r.db.QueryRowContext(ctx, query, settlement.ID, provider, created.ExternalID,
+		settlement.Amount, settlement.CurrencyCode, status, created.CheckoutURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payment intent: %w", err)
+	}
+
+	return intent, nil
+}
+
+// GetIntentByExternalID retrieves a payment intent by the provider's ID for it
+func (r *Repository) GetIntentByExternalID(ctx context.Context, provider, externalID string) (*PaymentIntent, error) {
+	query := `SELECT ` + intentColumns + ` FROM payment_intents WHERE provider = $1 AND external_id = $2`
+
+	intent, err := scanIntent(r.db.QueryRowContext(ctx, query, provider, externalID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment intent: %w", err)
+	}
+
+	return intent, nil
+}
+
+// ListIntentsBySettlementID retrieves a settlement's payment intents, newest first
+func (r *Repository) ListIntentsBySettlementID(ctx context.Context, settlementID int64) ([]*PaymentIntent, error) {
+	query := `SELECT ` + intentColumns + ` FROM payment_intents WHERE settlement_id = $1 ORDER BY created_at DESC, id DESC`
+
+	return r.listIntents(ctx, query, settlementID)
+}
+
+// ListMismatchedIntents retrieves payment intents whose collected amount didn't match
+// a settlement the user is receiving, oldest first
+func (r *Repository) ListMismatchedIntents(ctx context.Context, receiverID int64) ([]*PaymentIntent, error) {
+	query := `
+		SELECT ` + intentColumns + `
+		FROM payment_intents
+		WHERE reconciliation IS NOT NULL AND reconciliation != 'MATCHED'
+		  AND settlement_id IN (SELECT id FROM settlements WHERE receiver_id = $1)
+		ORDER BY updated_at, id
+	`
+
+	return r.listIntents(ctx, query, receiverID)
+}
+
+// listIntents runs a query selecting intentColumns
+func (r *Repository) listIntents(ctx context.Context, query string, args ...any) ([]*PaymentIntent, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payment intents: %w", err)
+	}
+	defer rows.Close()
+
+	var intents []*PaymentIntent
+	for rows.Next() {
+		intent, err := scanIntent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan payment intent: %w", err)
+		}
+		intents = append(intents, intent)
+	}
+
+	return intents, rows.Err()
+}
+
+// UpdateIntentStatus changes a payment intent's status
+func (r *Repository) UpdateIntentStatus(ctx context.Context, id int64, status IntentStatus) error {
+	query := `UPDATE payment_intents SET status = $2, updated_at = NOW() WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id, status); err != nil {
+		return fmt.Errorf("failed to update payment intent: %w", err)
+	}
+	return nil
+}
+
+// ReconcileIntent records what a provider collected for a payment intent
+func (r *Repository) ReconcileIntent(ctx context.Context, id int64, amount float64, currencyCode string, result ReconciliationStatus) (*PaymentIntent, error) {
+	query := `
+		UPDATE payment_intents
+		SET status = $2, amount_received = $3, currency_received = $4, reconciliation = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + intentColumns
+
+	intent, err := scanIntent(r.db.QueryRowContext(ctx, query, id, IntentStatusSucceeded, amount, currencyCode, result))
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconcile payment intent: %w", err)
+	}
+
+	return intent, nil
+}
+
+// RecordWebhookEvent remembers a provider's webhook event.
+// It returns false if the event had already been recorded.
+func (r *Repository) RecordWebhookEvent(ctx context.Context, provider string, event *WebhookEvent) (bool, error) {
+	query := `
+		INSERT INTO payment_webhook_events (provider, event_id, external_id, status)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query, provider, event.EventID, event.IntentID, event.Status)
+	if err != nil {
+		return false, fmt.Errorf("failed to record webhook event: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to record webhook event: %w", err)
+	}
+	return n > 0, nil
+}
+
+// DeleteWebhookEvent forgets a webhook event that couldn't be applied
+func (r *Repository) DeleteWebhookEvent(ctx context.Context, provider, eventID string) error {
+	query := `DELETE FROM payment_webhook_events WHERE provider = $1 AND event_id = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, provider, eventID); err != nil {
+		return fmt.Errorf("failed to delete webhook event: %w", err)
+	}
+	return nil
+}
+
+// =============================================================================
+// REVERSALS
+// =============================================================================
+
+// GetConfirmedAt returns when a settlement was confirmed, or nil if it wasn't
+func (r *Repository) GetConfirmedAt(ctx context.Context, id int64) (*time.Time, error) {
+	var confirmedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, `SELECT confirmed_at FROM settlements WHERE id = $1`, id).Scan(&confirmedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get settlement confirmation time: %w", err)
+	}
+	if !confirmedAt.Valid {
+		return nil, nil
+	}
+	return &confirmedAt.Time, nil
+}
+
+// CreateReversal records that a settlement is being reversed
+func (r *Repository) CreateReversal(ctx context.Context, settlementID, reversedBy int64, reason string) (*Reversal, error) {
+	query := `
+		INSERT INTO settlement_reversals (settlement_id, reversed_by, reason)
+		VALUES ($1, $2, $3)
+		RETURNING id, settlement_id, reversed_by, reason, created_at
+	`
+
+	reversal := &Reversal{}
+	err := r.db.QueryRowContext(ctx, query, settlementID, reversedBy, reason).Scan(
+		&reversal.ID,
+		&reversal.SettlementID,
+		&reversal.ReversedBy,
+		&reversal.Reason,
+		&reversal.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create settlement reversal: %w", err)
+	}
+
+	return reversal, nil
+}
+
+// GetReversalBySettlementID retrieves a settlement's reversal along with the splits it reopened
+func (r *Repository) GetReversalBySettlementID(ctx context.Context, settlementID int64) (*Reversal, error) {
+	query := `
+		SELECT id, settlement_id, reversed_by, reason, created_at
+		FROM settlement_reversals
+		WHERE settlement_id = $1
+	`
+
+	reversal := &Reversal{}
+	err := r.db.QueryRowContext(ctx, query, settlementID).Scan(
+		&reversal.ID,
+		&reversal.SettlementID,
+		&reversal.ReversedBy,
+		&reversal.Reason,
+		&reversal.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get settlement reversal: %w", err)
+	}
+
+	query = `
+		SELECT split_id, previous_status, was_locked, amount
+		FROM settlement_reversal_splits
+		WHERE reversal_id = $1
+		ORDER BY split_id
+	`
+	rows, err := r.db.QueryContext(ctx, query, reversal.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reversed splits: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		split := &ReversedSplit{}
+		if err := rows.Scan(&split.SplitID, &split.PreviousStatus, &split.WasLocked, &split.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan reversed split: %w", err)
+		}
+		reversal.Splits = append(reversal.Splits, split)
+	}
+
+	return reversal, rows.Err()
+}
+
+// =============================================================================
+// LEDGER
+// =============================================================================
+
+// ListPairSplits retrieves every split between two users, in both directions
+func (r *Repository) ListPairSplits(ctx context.Context, userID, otherUserID int64) ([]*PairSplit, error) {
+	query := `
+		SELECT s.id, s.expense_id, e.group_id, e.description, e.created_at, s.updated_at, s.status,
+		       s.borrower_id = $1, s.amount_owed, s.amount_settled, s.settlement_id
+		FROM splits s
+		JOIN expenses e ON s.expense_id = e.id
+		WHERE (s.borrower_id = $1 AND e.payer_id = $2)
+		   OR (s.borrower_id = $2 AND e.payer_id = $1)
+		ORDER BY e.created_at, s.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, otherUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list splits between users: %w", err)
+	}
+	defer rows.Close()
+
+	var splits []*PairSplit
+	for rows.Next() {
+		split := &PairSplit{}
+		if err := rows.Scan(
+			&split.SplitID,
+			&split.ExpenseID,
+			&split.GroupID,
+			&split.ExpenseDescription,
+			&split.ExpenseDate,
+			&split.UpdatedAt,
+			&split.Status,
+			&split.YouOwe,
+			&split.AmountOwed,
+			&split.AmountSettled,
+			&split.SettlementID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan split: %w", err)
+		}
+		splits = append(splits, split)
+	}
+
+	return splits, rows.Err()
+}
+
+// ListPairSettlements retrieves every settlement between two users, with when it was reversed
+func (r *Repository) ListPairSettlements(ctx context.Context, userID, otherUserID int64) ([]*PairSettlement, error) {
+	query := `
+		SELECT s.id, s.payer_id, s.amount, s.status, s.created_at, rv.created_at
+		FROM settlements s
+		LEFT JOIN settlement_reversals rv ON rv.settlement_id = s.id
+		WHERE (s.payer_id = $1 AND s.receiver_id = $2)
+		   OR (s.payer_id = $2 AND s.receiver_id = $1)
+		ORDER BY s.created_at, s.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, otherUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list settlements between users: %w", err)
+	}
+	defer rows.Close()
+
+	var settlements []*PairSettlement
+	for rows.Next() {
+		settlement := &PairSettlement{}
+		if err := rows.Scan(
+			&settlement.ID,
+			&settlement.PayerID,
+			&settlement.Amount,
+			&settlement.Status,
+			&settlement.CreatedAt,
+			&settlement.ReversedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan settlement: %w", err)
+		}
+		settlements = append(settlements, settlement)
+	}
+
+	return settlements, rows.Err()
+}
@@ -0,0 +1,131 @@
+package settlement
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/fkhayef/splitwise/internal/activity"
+)
+
+// Reversal errors
+var (
+	ErrReasonRequired      = errors.New("a reason is required to reverse a settlement")
+	ErrReasonTooLong       = errors.New("reason must be at most 500 characters")
+	ErrReversalWindowEnded = errors.New("settlement can no longer be reversed")
+	ErrReversalNotFound    = errors.New("settlement has not been reversed")
+	ErrSettledElsewhere    = errors.New("splits this settlement partly covered have since been settled again; reverse that settlement first")
+)
+
+// Reverse lets the receiver undo a CONFIRMED settlement whose money never arrived
+// (e.g. a bounced bank transfer), within the reversal window after confirmation.
+// The splits it covered are reopened and each is recorded against the reversal.
+func (s *Service) Reverse(ctx context.Context, settlementID, userID int64, reason string) (*Settlement, *Reversal, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, nil, ErrReasonRequired
+	}
+	if len(reason) > 500 {
+		return nil, nil, ErrReasonTooLong
+	}
+
+	settlement, err := s.repo.GetByID(ctx, settlementID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if settlement == nil {
+		return nil, nil, ErrSettlementNotFound
+	}
+
+	// Only the receiver knows whether the money stayed in their account
+	if settlement.ReceiverID != userID {
+		return nil, nil, ErrNotReceiver
+	}
+	if settlement.Status != SettlementStatusConfirmed {
+		return nil, nil, ErrInvalidStatusChange
+	}
+
+	confirmedAt, err := s.repo.GetConfirmedAt(ctx, settlementID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if confirmedAt == nil || time.Since(*confirmedAt) > s.reversalWindow {
+		return nil, nil, ErrReversalWindowEnded
+	}
+
+	// Look up the groups first; reopening releases the allocations they're found through
+	groupIDs, err := s.expenseRepo.GetGroupIDsBySettlement(ctx, settlementID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	payerUsername := settlement.PayerUsername
+	receiverUsername := settlement.ReceiverUsername
+	err = s.repo.InTx(ctx, func(tx *sql.Tx) error {
+		repo := s.repo.WithTx(tx)
+		expenseRepo := s.expenseRepo.WithTx(tx)
+
+		// Giving back a part of a split another settlement has since taken would forgive it
+		taken, err := expenseRepo.HasAllocationsSettledElsewhere(ctx, settlementID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrSettledElsewhere
+		}
+
+		reversal, err := repo.CreateReversal(ctx, settlementID, userID, reason)
+		if err != nil {
+			return err
+		}
+		if err := expenseRepo.ReverseSplitsBySettlement(ctx, settlementID, reversal.ID); err != nil {
+			return err
+		}
+		settlement, err = repo.UpdateStatus(ctx, settlementID, SettlementStatusReversed)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	settlement.PayerUsername = payerUsername
+	settlement.ReceiverUsername = receiverUsername
+
+	for _, groupID := range groupIDs {
+		s.activity.Record(ctx, groupID, userID, activity.TypeSettlementReversed, activity.EntitySettlement, settlementID, activity.Details{
+			Amount:   settlement.Amount,
+			Username: payerUsername,
+			Reason:   reason,
+		})
+	}
+
+	reversal, err := s.repo.GetReversalBySettlementID(ctx, settlementID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return settlement, reversal, nil
+}
+
+// GetReversal returns a reversed settlement's audit record (payer or receiver only)
+func (s *Service) GetReversal(ctx context.Context, settlementID, userID int64) (*Reversal, error) {
+	settlement, err := s.repo.GetByID(ctx, settlementID)
+	if err != nil {
+		return nil, err
+	}
+	if settlement == nil {
+		return nil, ErrSettlementNotFound
+	}
+	if settlement.PayerID != userID && settlement.ReceiverID != userID {
+		return nil, ErrNotParticipant
+	}
+
+	reversal, err := s.repo.GetReversalBySettlementID(ctx, settlementID)
+	if err != nil {
+		return nil, err
+	}
+	if reversal == nil {
+		return nil, ErrReversalNotFound
+	}
+	return reversal, nil
+}
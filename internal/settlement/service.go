@@ -2,40 +2,73 @@ package settlement
 
 import (
 	"context"
+	"database/sql"
 	"errors"
-	"fmt"
 	"math"
+	"time"
 
+	"github.com/fkhayef/splitwise/internal/activity"
 	"github.com/fkhayef/splitwise/internal/expense"
+	"github.com/fkhayef/splitwise/internal/group"
+	"github.com/fkhayef/splitwise/internal/payment"
+	"github.com/fkhayef/splitwise/pkg/i18n"
 )
 
 // Common errors
 var (
-	ErrSettlementNotFound  = errors.New("settlement not found")
-	ErrAlreadySettled      = errors.New("already settled up - no pending debts")
-	ErrNotPayer            = errors.New("only the payer can mark as paid")
-	ErrNotReceiver         = errors.New("only the receiver can confirm/reject")
-	ErrInvalidStatusChange = errors.New("invalid status change")
-	ErrCannotSettleSelf    = errors.New("cannot create settlement with yourself")
+	ErrSettlementNotFound   = errors.New("settlement not found")
+	ErrAlreadySettled       = errors.New("already settled up - no pending debts")
+	ErrNotPayer             = errors.New("only the payer can mark as paid")
+	ErrNotCanceller         = errors.New("only the payer can cancel")
+	ErrNotReceiver          = errors.New("only the receiver can confirm/reject")
+	ErrInvalidStatusChange  = errors.New("invalid status change")
+	ErrCannotSettleSelf     = errors.New("cannot create settlement with yourself")
+	ErrAmountExceedsBalance = errors.New("amount is more than the net balance")
+	ErrAmountTooSmall       = errors.New("amount must be at least 0.01")
 )
 
 // Service handles settlement business logic
 type Service struct {
 	repo        *Repository
 	expenseRepo *expense.Repository
+	activity    *activity.Service
+	groups      *group.Service
+	providers   map[string]PaymentProvider // Keyed by provider name
+
+	reversalWindow time.Duration // How long after confirmation the receiver can reverse
+}
+
+// NewService creates a new settlement service.
+// Confirmed settlements can be reversed for reversalWindow, and settlements
+// can be paid through any of the given payment providers.
+func NewService(repo *Repository, expenseRepo *expense.Repository, activityService *activity.Service, groupService *group.Service, reversalWindow time.Duration, providers ...PaymentProvider) *Service {
+	s := &Service{
+		repo:           repo,
+		expenseRepo:    expenseRepo,
+		activity:       activityService,
+		groups:         groupService,
+		providers:      make(map[string]PaymentProvider),
+		reversalWindow: reversalWindow,
+	}
+	for _, p := range providers {
+		s.providers[p.Name()] = p
+	}
+	return s
 }
 
-// NewService creates a new settlement service
-func NewService(repo *Repository, expenseRepo *expense.Repository) *Service {
-	return &Service{
-		repo:        repo,
-		expenseRepo: expenseRepo,
+// ExportGroupSettlements streams every settlement that covers splits in a group to fn.
+// It fails with group.ErrGroupNotFound before calling fn if the group doesn't exist.
+func (s *Service) ExportGroupSettlements(ctx context.Context, groupID int64, fn func(*GroupSettlement) error) error {
+	if _, err := s.groups.GetByID(ctx, groupID); err != nil {
+		return err
 	}
+	return s.repo.StreamGroupSettlements(ctx, groupID, fn)
 }
 
 // CreateSettlement creates a new bulk settlement between two users
 // Anyone can initiate - system determines payer/receiver based on net balance
 // Even $0 settlements are valid (just need confirmation to clear pending debts)
+// With an amount below the net balance, only part is settled (see createPartial)
 func (s *Service) CreateSettlement(ctx context.Context, initiatorID int64, req *CreateSettlementRequest) (*Settlement, error) {
 	otherUserID := req.OtherUserID
 	
@@ -51,6 +84,27 @@ func (s *Service) CreateSettlement(ctx context.Context, initiatorID int64, req *
 		return nil, err
 	}
 
+	if req.Amount != nil {
+		owed := math.Round(math.Abs(netBalance)*100) / 100
+		amount := math.Round(*req.Amount*100) / 100
+		if amount <= 0 {
+			return nil, ErrAmountTooSmall
+		}
+		if owed == 0 {
+			return nil, ErrAlreadySettled
+		}
+		if amount > owed {
+			return nil, ErrAmountExceedsBalance
+		}
+		if amount < owed {
+			if netBalance > 0 {
+				return s.createPartial(ctx, initiatorID, otherUserID, amount)
+			}
+			return s.createPartial(ctx, otherUserID, initiatorID, amount)
+		}
+		// Paying exactly the net balance is a full settlement
+	}
+
 	// Determine payer and receiver based on who owes whom
 	var payerID, receiverID int64
 	var amount float64
@@ -92,7 +146,7 @@ func (s *Service) CreateSettlement(ctx context.Context, initiatorID int64, req *
 	}
 
 	// Create the settlement
-	settlement, err := s.repo.Create(ctx, payerID, receiverID, amount)
+	settlement, err := s.repo.Create(ctx, payerID, receiverID, amount, false)
 	if err != nil {
 		return nil, err
 	}
@@ -116,6 +170,59 @@ func (s *Service) CreateSettlement(ctx context.Context, initiatorID int64, req *
 	return settlement, nil
 }
 
+// createPartial settles part of what the payer owes the receiver. The amount is
+// applied to the payer's splits oldest-first: splits it covers in full are locked
+// to the settlement, and the last one may be covered only in part, leaving the
+// rest of it open. Splits the receiver owes the payer are left alone.
+func (s *Service) createPartial(ctx context.Context, payerID, receiverID int64, amount float64) (*Settlement, error) {
+	var settlement *Settlement
+	err := s.repo.InTx(ctx, func(tx *sql.Tx) error {
+		repo := s.repo.WithTx(tx)
+		expenseRepo := s.expenseRepo.WithTx(tx)
+
+		splits, err := expenseRepo.GetPendingSplitsBetweenUsers(ctx, payerID, receiverID)
+		if err != nil {
+			return err
+		}
+
+		settlement, err = repo.Create(ctx, payerID, receiverID, amount, true)
+		if err != nil {
+			return err
+		}
+
+		var lockIDs []int64
+		left := amount
+		for _, split := range splits {
+			if left <= 0 {
+				break
+			}
+			remaining := split.Remaining()
+			if remaining <= 0 {
+				continue
+			}
+			if left >= remaining {
+				lockIDs = append(lockIDs, split.ID)
+				left = math.Round((left-remaining)*100) / 100
+				continue
+			}
+			if err := expenseRepo.AllocateToSplit(ctx, settlement.ID, split.ID, left); err != nil {
+				return err
+			}
+			left = 0
+		}
+
+		if len(lockIDs) > 0 {
+			return expenseRepo.LockSplitsToSettlement(ctx, lockIDs, settlement.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return settlement, nil
+}
+
 // GetByID retrieves a settlement by its ID
 func (s *Service) GetByID(ctx context.Context, id int64) (*Settlement, error) {
 	settlement, err := s.repo.GetByID(ctx, id)
@@ -141,8 +248,8 @@ func (s *Service) ListByUserID(ctx context.Context, userID int64, page, perPage
 	return s.repo.ListByUserID(ctx, userID, perPage, offset)
 }
 
-// MarkAsPaid allows the payer to mark the settlement as paid
-func (s *Service) MarkAsPaid(ctx context.Context, settlementID, userID int64) (*Settlement, error) {
+// MarkAsPaid allows the payer to mark the settlement as paid, recording how it was paid
+func (s *Service) MarkAsPaid(ctx context.Context, settlementID, userID int64, details *payment.Details) (*Settlement, error) {
 	settlement, err := s.repo.GetByID(ctx, settlementID)
 	if err != nil {
 		return nil, err
@@ -161,7 +268,7 @@ func (s *Service) MarkAsPaid(ctx context.Context, settlementID, userID int64) (*
 		return nil, ErrInvalidStatusChange
 	}
 
-	return s.repo.UpdateStatus(ctx, settlementID, SettlementStatusPaid)
+	return s.repo.MarkPaid(ctx, settlementID, details)
 }
 
 // Confirm allows the receiver to confirm they received the payment
@@ -184,8 +291,17 @@ func (s *Service) Confirm(ctx context.Context, settlementID, userID int64) (*Set
 		return nil, ErrInvalidStatusChange
 	}
 
+	return s.confirm(ctx, settlement)
+}
+
+// confirm moves a PAID settlement to CONFIRMED on behalf of its receiver,
+// confirming its locked splits
+func (s *Service) confirm(ctx context.Context, settlement *Settlement) (*Settlement, error) {
+	settlementID := settlement.ID
+	payerUsername := settlement.PayerUsername
+
 	// Update settlement status
-	settlement, err = s.repo.UpdateStatus(ctx, settlementID, SettlementStatusConfirmed)
+	settlement, err := s.repo.UpdateStatus(ctx, settlementID, SettlementStatusConfirmed)
 	if err != nil {
 		return nil, err
 	}
@@ -195,6 +311,18 @@ func (s *Service) Confirm(ctx context.Context, settlementID, userID int64) (*Set
 		return nil, err
 	}
 
+	// Settlements span groups; record the confirmation in every group it touched
+	groupIDs, err := s.expenseRepo.GetGroupIDsBySettlement(ctx, settlementID)
+	if err != nil {
+		return nil, err
+	}
+	for _, groupID := range groupIDs {
+		s.activity.Record(ctx, groupID, settlement.ReceiverID, activity.TypeSettlementConfirmed, activity.EntitySettlement, settlementID, activity.Details{
+			Amount:   settlement.Amount,
+			Username: payerUsername,
+		})
+	}
+
 	return settlement, nil
 }
 
@@ -229,10 +357,79 @@ func (s *Service) Reject(ctx context.Context, settlementID, userID int64) (*Sett
 		return nil, err
 	}
 
+	// Reopen the parts of splits a partial settlement covered
+	if err := s.expenseRepo.ReleaseAllocations(ctx, settlementID); err != nil {
+		return nil, err
+	}
+
+	return settlement, nil
+}
+
+// Cancel allows the payer to withdraw a settlement they created by mistake.
+// Only PENDING settlements without a provider payment under way can be cancelled;
+// its splits are released as on rejection.
+func (s *Service) Cancel(ctx context.Context, settlementID, userID int64) (*Settlement, error) {
+	// Locked so a payment intent can't be started while the settlement is withdrawn
+	var settlement *Settlement
+	err := s.repo.InTx(ctx, func(tx *sql.Tx) error {
+		repo := s.repo.WithTx(tx)
+		expenseRepo := s.expenseRepo.WithTx(tx)
+		if err := repo.LockSettlement(ctx, settlementID); err != nil {
+			return err
+		}
+
+		current, err := repo.GetByID(ctx, settlementID)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return ErrSettlementNotFound
+		}
+
+		// Only the payer can cancel
+		if current.PayerID != userID {
+			return ErrNotCanceller
+		}
+
+		// Once marked as paid, only the receiver can reject it
+		if current.Status != SettlementStatusPending {
+			return ErrInvalidStatusChange
+		}
+
+		// Nor can it be withdrawn once a provider has taken the payer's money
+		intents, err := repo.ListIntentsBySettlementID(ctx, settlementID)
+		if err != nil {
+			return err
+		}
+		for _, intent := range intents {
+			if intent.Status == IntentStatusProcessing || intent.Status == IntentStatusSucceeded {
+				return ErrInvalidStatusChange
+			}
+		}
+
+		// Update settlement status
+		settlement, err = repo.UpdateStatus(ctx, settlementID, SettlementStatusCancelled)
+		if err != nil {
+			return err
+		}
+
+		// Unlock all splits from this settlement
+		if err := expenseRepo.UnlockSplitsFromSettlement(ctx, settlementID); err != nil {
+			return err
+		}
+
+		// Reopen the parts of splits a partial settlement covered
+		return expenseRepo.ReleaseAllocations(ctx, settlementID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return settlement, nil
 }
 
-// GetNetBalances returns all net balances for a user
+// GetNetBalances returns all net balances for a user, including users
+// whose only open splits are in settlement or in dispute
 func (s *Service) GetNetBalances(ctx context.Context, userID int64) ([]*NetBalanceResponse, error) {
 	balances, err := s.repo.GetNetBalancesForUser(ctx, userID)
 	if err != nil {
@@ -241,43 +438,45 @@ func (s *Service) GetNetBalances(ctx context.Context, userID int64) ([]*NetBalan
 
 	responses := make([]*NetBalanceResponse, len(balances))
 	for i, b := range balances {
-		var message string
-		if b.Amount > 0 {
-			message = fmt.Sprintf("You owe %s $%.2f", b.Username, b.Amount)
-		} else {
-			message = fmt.Sprintf("%s owes you $%.2f", b.Username, -b.Amount)
-		}
-		responses[i] = &NetBalanceResponse{
-			UserID:   b.UserID,
-			Username: b.Username,
-			Amount:   b.Amount,
-			Message:  message,
-		}
+		responses[i] = netBalanceResponse(i18n.Language(ctx), b)
 	}
 
 	return responses, nil
 }
 
-// GetNetBalanceWithUser returns the net balance with a specific user
+// GetNetBalanceWithUser returns the net balance with a specific user.
+// otherUsername is used if the two users have never shared an expense.
 func (s *Service) GetNetBalanceWithUser(ctx context.Context, userID, otherUserID int64, otherUsername string) (*NetBalanceResponse, error) {
-	amount, err := s.repo.GetNetBalanceBetweenUsers(ctx, userID, otherUserID)
+	balance, err := s.repo.GetBalanceWithUser(ctx, userID, otherUserID)
 	if err != nil {
 		return nil, err
 	}
+	if balance.Username == "" {
+		balance.Username = otherUsername
+	}
 
+	return netBalanceResponse(i18n.Language(ctx), balance), nil
+}
+
+// netBalanceResponse converts a NetBalance to its response, describing it in words in lang
+func netBalanceResponse(lang string, b *NetBalance) *NetBalanceResponse {
 	var message string
-	if amount > 0 {
-		message = fmt.Sprintf("You owe %s $%.2f", otherUsername, amount)
-	} else if amount < 0 {
-		message = fmt.Sprintf("%s owes you $%.2f", otherUsername, -amount)
+	if b.Amount > 0 {
+		message = i18n.T(lang, "balance.you_owe", b.Username, i18n.FormatMoney(lang, b.Amount, i18n.DefaultCurrency))
+	} else if b.Amount < 0 {
+		message = i18n.T(lang, "balance.owes_you", b.Username, i18n.FormatMoney(lang, -b.Amount, i18n.DefaultCurrency))
 	} else {
-		message = fmt.Sprintf("You and %s are settled up", otherUsername)
+		message = i18n.T(lang, "balance.settled_up", b.Username)
+	}
+	if b.Breakdown.Disputed != 0 {
+		message += i18n.T(lang, "balance.in_dispute", i18n.FormatMoney(lang, math.Abs(b.Breakdown.Disputed), i18n.DefaultCurrency))
 	}
 
 	return &NetBalanceResponse{
-		UserID:   otherUserID,
-		Username: otherUsername,
-		Amount:   amount,
-		Message:  message,
-	}, nil
+		UserID:    b.UserID,
+		Username:  b.Username,
+		Amount:    b.Amount,
+		Message:   message,
+		Breakdown: b.Breakdown,
+	}
 }
@@ -0,0 +1,91 @@
+package statement
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/fkhayef/splitwise/internal/group"
+	"github.com/fkhayef/splitwise/pkg/i18n"
+	"github.com/fkhayef/splitwise/pkg/middleware"
+	"github.com/fkhayef/splitwise/pkg/response"
+)
+
+// Handler handles HTTP requests for member statements
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new statement handler with service dependency injected
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Get handles GET /groups/{id}/statements/{userId}?format=html|pdf.
+// Without format, a PDF is returned if the Accept header asks for one.
+// It is mounted on the groups router by main.
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	viewerID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		viewerID = 1
+	}
+
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid group ID")
+		return
+	}
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userId"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid user ID")
+		return
+	}
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" && strings.Contains(r.Header.Get("Accept"), "application/pdf") {
+		format = "pdf"
+	}
+	if format != "" && format != "html" && format != "pdf" {
+		response.BadRequest(w, "format must be html or pdf")
+		return
+	}
+
+	st, err := h.service.Get(r.Context(), groupID, userID, viewerID)
+	if err != nil {
+		switch {
+		case errors.Is(err, group.ErrGroupNotFound), errors.Is(err, ErrMemberNotFound):
+			response.NotFound(w, err.Error())
+		case errors.Is(err, group.ErrNotAuthorized):
+			response.Forbidden(w, err.Error())
+		default:
+			response.InternalError(w, "Failed to build statement")
+		}
+		return
+	}
+
+	// Render into a buffer so a template failure can still return an error response
+	var buf bytes.Buffer
+	contentType := "text/html; charset=utf-8"
+	if format == "pdf" {
+		contentType = "application/pdf"
+		err = RenderPDF(&buf, st, i18n.Language(r.Context()))
+	} else {
+		err = RenderHTML(&buf, st, i18n.Language(r.Context()))
+	}
+	if err != nil {
+		response.InternalError(w, "Failed to render statement")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if format == "pdf" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="group-%d-statement-%d.pdf"`, groupID, userID))
+	}
+	w.WriteHeader(http.StatusOK)
+	buf.WriteTo(w)
+}
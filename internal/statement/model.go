@@ -0,0 +1,48 @@
+package statement
+
+import (
+	"time"
+
+	"github.com/fkhayef/splitwise/internal/expense"
+	"github.com/fkhayef/splitwise/internal/group"
+)
+
+// Statement is one member's printable account of a group: every expense they
+// took part in, the payments they made and received, and where they stand
+type Statement struct {
+	Group       *group.Group
+	Member      *group.GroupMember
+	GeneratedAt time.Time
+
+	Expenses []*expense.StatementLine
+	Payments []*expense.MemberPayment
+
+	TotalPaid        float64 // Expenses the member paid for, in full
+	TotalShare       float64 // The member's share of all their expenses
+	PaymentsMade     float64 // Splits the member paid back to others
+	PaymentsReceived float64 // Splits others paid back to the member
+	Net              float64 // Still unsettled; positive = the member owes others
+}
+
+// PaymentMade reports whether the member paid this payment, rather than received it
+func (s *Statement) PaymentMade(p *expense.MemberPayment) bool {
+	return p.FromUserID == s.Member.UserID
+}
+
+// Settled reports whether the member has nothing left to pay or collect
+func (s *Statement) Settled() bool {
+	return s.Net > -0.005 && s.Net < 0.005
+}
+
+// OwesOthers reports whether the member still owes money
+func (s *Statement) OwesOthers() bool {
+	return s.Net >= 0.005
+}
+
+// NetAbs is the unsettled amount without its sign
+func (s *Statement) NetAbs() float64 {
+	if s.Net < 0 {
+		return -s.Net
+	}
+	return s.Net
+}
@@ -0,0 +1,171 @@
+package statement
+
+import (
+	"embed"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/fkhayef/splitwise/internal/expense"
+	"github.com/fkhayef/splitwise/pkg/i18n"
+	"github.com/fkhayef/splitwise/pkg/pdf"
+)
+
+//go:embed templates/statement.html
+var templateFS embed.FS
+
+var statementTemplate = template.Must(template.New("statement.html").Funcs(templateFuncs(i18n.Default)).
+	ParseFS(templateFS, "templates/statement.html"))
+
+// templateFuncs are the template's functions, translating and formatting for lang
+func templateFuncs(lang string) template.FuncMap {
+	return template.FuncMap{
+		"t":          func(key string, args ...any) string { return i18n.T(lang, key, args...) },
+		"money":      func(v float64) string { return formatMoney(lang, v) },
+		"date":       func(v interface{}) string { return formatDate(lang, v) },
+		"datetime":   func(t time.Time) string { return i18n.FormatDateTime(lang, t) },
+		"status":     func(status expense.SplitStatus) string { return formatStatus(lang, status) },
+		"settlement": func(id int64) string { return i18n.T(lang, "statement.settlement_ref", id) },
+		"lang":       func() string { return lang },
+		"dir":        func() string { return direction(lang) },
+	}
+}
+
+// RenderHTML writes the statement as a standalone, printable HTML page in lang
+func RenderHTML(w io.Writer, st *Statement, lang string) error {
+	t, err := statementTemplate.Clone()
+	if err != nil {
+		return err
+	}
+	return t.Funcs(templateFuncs(lang)).Execute(w, st)
+}
+
+// RenderPDF writes the statement as an A4 PDF in lang, with the same sections as the HTML page
+func RenderPDF(w io.Writer, st *Statement, lang string) error {
+	doc := pdf.New()
+	doc.SetRightToLeft(lang == i18n.Arabic)
+	t := func(key string, args ...any) string { return i18n.T(lang, key, args...) }
+	money := func(v float64) string { return formatMoney(lang, v) }
+
+	doc.Heading(st.Group.Name)
+	meta := t("statement.for", st.Member.Username)
+	if st.Group.EndDate != nil {
+		meta += " - " + t("statement.group_ended", formatDate(lang, st.Group.EndDate))
+	}
+	doc.Text(meta + " - " + t("statement.generated", i18n.FormatDateTime(lang, st.GeneratedAt)))
+	doc.Gap(12)
+
+	// Column widths in points; each table adds up to pdf.ContentWidth
+	expenseCols := []float64{70, 104.24, 56, 58, 76, 76, 70}
+	section(doc, t("statement.expenses"))
+	if len(st.Expenses) == 0 {
+		doc.Text(t("statement.no_expenses"))
+	} else {
+		doc.Row(cells(expenseCols, t("statement.date"), t("statement.description"), t("statement.category"),
+			t("statement.paid_by"), ">"+t("statement.total"), ">"+t("statement.your_share"), t("statement.status")), true)
+		for _, l := range st.Expenses {
+			status := t("statement.you_paid")
+			if l.SplitStatus != nil {
+				status = formatStatus(lang, *l.SplitStatus)
+			}
+			doc.Row(cells(expenseCols,
+				formatDate(lang, l.CreatedAt), l.Description, l.Category, l.PayerUsername,
+				">"+money(l.Amount), ">"+money(l.Share), status,
+			), false)
+		}
+	}
+	doc.Gap(12)
+
+	paymentCols := []float64{70, 170.24, 62, 62, 76, 70}
+	section(doc, t("statement.payments"))
+	if len(st.Payments) == 0 {
+		doc.Text(t("statement.no_payments"))
+	} else {
+		doc.Row(cells(paymentCols, t("statement.date"), t("statement.for_expense"), t("statement.from"),
+			t("statement.to"), ">"+t("statement.amount"), t("statement.status")), true)
+		for _, p := range st.Payments {
+			what := p.ExpenseDescription
+			if p.SettlementID != nil {
+				what += " " + t("statement.settlement_ref", *p.SettlementID)
+			}
+			doc.Row(cells(paymentCols,
+				formatDate(lang, p.PaidAt), what, p.FromUsername, p.ToUsername, ">"+money(p.Amount), formatStatus(lang, p.Status),
+			), false)
+		}
+	}
+	doc.Gap(12)
+
+	summaryCols := []float64{200, 90, pdf.ContentWidth - 290}
+	section(doc, t("statement.summary"))
+	doc.Row(cells(summaryCols, t("statement.total_paid"), ">"+money(st.TotalPaid), ""), false)
+	doc.Row(cells(summaryCols, t("statement.total_share"), ">"+money(st.TotalShare), ""), false)
+	doc.Row(cells(summaryCols, t("statement.payments_made"), ">"+money(st.PaymentsMade), ""), false)
+	doc.Row(cells(summaryCols, t("statement.payments_received"), ">"+money(st.PaymentsReceived), ""), false)
+	doc.Gap(6)
+
+	var net string
+	switch {
+	case st.Settled():
+		net = t("statement.settled_up")
+	case st.OwesOthers():
+		net = t("statement.you_owe", money(st.NetAbs()))
+	default:
+		net = t("statement.owed", money(st.NetAbs()))
+	}
+	doc.Row([]pdf.Cell{{Text: net, Width: pdf.ContentWidth}}, true)
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+// section starts a titled part of the statement
+func section(doc *pdf.Document, title string) {
+	doc.Row([]pdf.Cell{{Text: title, Width: pdf.ContentWidth}}, true)
+	doc.Rule()
+}
+
+// cells builds a table row; a leading ">" right-aligns the cell
+func cells(widths []float64, texts ...string) []pdf.Cell {
+	row := make([]pdf.Cell, len(texts))
+	for i, text := range texts {
+		row[i] = pdf.Cell{Text: text, Width: widths[i]}
+		if len(text) > 0 && text[0] == '>' {
+			row[i].Text = text[1:]
+			row[i].Align = pdf.AlignRight
+		}
+	}
+	return row
+}
+
+// direction is the HTML text direction of lang
+func direction(lang string) string {
+	if lang == i18n.Arabic {
+		return "rtl"
+	}
+	return "ltr"
+}
+
+// formatMoney formats an amount in the currency statements are kept in
+func formatMoney(lang string, v float64) string {
+	return i18n.FormatMoney(lang, v, i18n.DefaultCurrency)
+}
+
+// formatStatus names a split's status in lang
+func formatStatus(lang string, status expense.SplitStatus) string {
+	return i18n.T(lang, "statement.status."+string(status))
+}
+
+// formatDate formats a time or *time.Time as a calendar date in lang
+func formatDate(lang string, v interface{}) string {
+	switch t := v.(type) {
+	case time.Time:
+		return i18n.FormatDate(lang, t)
+	case *time.Time:
+		if t == nil {
+			return ""
+		}
+		return i18n.FormatDate(lang, *t)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,102 @@
+package statement
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/fkhayef/splitwise/internal/expense"
+	"github.com/fkhayef/splitwise/internal/group"
+)
+
+// Common errors
+var (
+	ErrMemberNotFound = errors.New("user is not a member of this group")
+)
+
+// Service builds member statements from expense and group data
+type Service struct {
+	reports *expense.ReportingRepository
+	groups  *group.Service
+}
+
+// NewService creates a new statement service
+func NewService(reports *expense.ReportingRepository, groupService *group.Service) *Service {
+	return &Service{
+		reports: reports,
+		groups:  groupService,
+	}
+}
+
+// Get builds the statement of a member of a group. Members can read their own
+// statement and admins can read anyone's; people who have left the group
+// still have one.
+func (s *Service) Get(ctx context.Context, groupID, userID, viewerID int64) (*Statement, error) {
+	g, members, err := s.groups.GetByIDWithMembers(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	var member *group.GroupMember
+	for _, m := range members {
+		if m.UserID == userID && m.Status != group.MemberStatusInvited {
+			member = m
+			break
+		}
+	}
+	if member == nil {
+		return nil, ErrMemberNotFound
+	}
+
+	if viewerID != userID {
+		isAdmin, err := s.groups.IsAdmin(ctx, groupID, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		if !isAdmin {
+			return nil, group.ErrNotAuthorized
+		}
+	}
+
+	st := &Statement{
+		Group:       g,
+		Member:      member,
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	if st.Expenses, err = s.reports.GetMemberStatementLines(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+	if st.Payments, err = s.reports.GetMemberPayments(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+
+	for _, l := range st.Expenses {
+		if l.PayerID == userID {
+			st.TotalPaid += l.Amount
+		}
+		st.TotalShare += l.Share
+		st.Net += l.Outstanding
+	}
+	for _, p := range st.Payments {
+		if st.PaymentMade(p) {
+			st.PaymentsMade += p.Amount
+		} else {
+			st.PaymentsReceived += p.Amount
+		}
+	}
+
+	st.TotalPaid = roundCents(st.TotalPaid)
+	st.TotalShare = roundCents(st.TotalShare)
+	st.PaymentsMade = roundCents(st.PaymentsMade)
+	st.PaymentsReceived = roundCents(st.PaymentsReceived)
+	st.Net = roundCents(st.Net)
+
+	return st, nil
+}
+
+// roundCents rounds an amount to two decimal places
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
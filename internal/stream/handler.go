@@ -0,0 +1,156 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fkhayef/splitwise/pkg/middleware"
+	"github.com/fkhayef/splitwise/pkg/response"
+)
+
+// retryMillis tells clients how long to wait before reconnecting
+const retryMillis = 3000
+
+// seenLimit is how many recently sent event IDs a connection remembers
+const seenLimit = 1024
+
+// Handler handles the real-time stream endpoint
+type Handler struct {
+	service   *Service
+	hub       *Hub
+	heartbeat time.Duration // Interval between comments that keep idle connections open
+}
+
+// NewHandler creates a new stream handler
+func NewHandler(service *Service, hub *Hub, heartbeat time.Duration) *Handler {
+	return &Handler{service: service, hub: hub, heartbeat: heartbeat}
+}
+
+// Stream handles GET /stream, a Server-Sent Events stream of the caller's new
+// notifications, group activity and balance changes. Clients that reconnect with
+// a Last-Event-ID header are first sent the events they missed.
+func (h *Handler) Stream(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		userID = 1
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		response.InternalError(w, "Streaming is not supported")
+		return
+	}
+
+	var lastEventID int64
+	if header := r.Header.Get("Last-Event-ID"); header != "" {
+		id, err := strconv.ParseInt(header, 10, 64)
+		if err != nil || id < 0 {
+			response.BadRequest(w, "Invalid Last-Event-ID")
+			return
+		}
+		lastEventID = id
+	}
+
+	// Subscribe before replaying, so nothing is lost in between; anything
+	// delivered twice is skipped by its ID. IDs are handed out as events are
+	// written but events arrive as they commit, so an event can arrive after
+	// one with a higher ID and the last ID sent isn't enough to go by.
+	sub := h.hub.Subscribe(userID)
+	defer h.hub.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Stop proxies from buffering the stream
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", retryMillis)
+
+	ctx := r.Context()
+	sent := newSeenSet(seenLimit)
+	send := func(event *Event) error {
+		if !sent.add(event.ID) {
+			return nil
+		}
+		msg, err := h.service.Render(ctx, event)
+		if err != nil {
+			return err
+		}
+		if msg == nil {
+			return nil
+		}
+		return writeMessage(w, msg)
+	}
+
+	if lastEventID > 0 {
+		if err := h.service.Missed(ctx, userID, lastEventID, send); err != nil {
+			log.Printf("stream: failed to replay events for user %d: %v", userID, err)
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.Done():
+			// Dropped by the hub; the client reconnects and catches up
+			return
+		case event := <-sub.Events():
+			if err := send(event); err != nil {
+				log.Printf("stream: failed to send event %d to user %d: %v", event.ID, userID, err)
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// writeMessage writes a message in the SSE wire format
+func writeMessage(w http.ResponseWriter, msg *Message) error {
+	data, err := json.Marshal(msg.Data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", msg.ID, msg.Event, data)
+	return err
+}
+
+// seenSet remembers the most recent IDs added to it, forgetting the oldest
+// once it holds limit of them
+type seenSet struct {
+	ids   map[int64]struct{}
+	order []int64
+	next  int // Where the next ID goes in order once it is full
+}
+
+func newSeenSet(limit int) *seenSet {
+	return &seenSet{ids: make(map[int64]struct{}, limit), order: make([]int64, 0, limit)}
+}
+
+// add records id and reports whether it was new
+func (s *seenSet) add(id int64) bool {
+	if _, ok := s.ids[id]; ok {
+		return false
+	}
+	if len(s.order) < cap(s.order) {
+		s.order = append(s.order, id)
+	} else {
+		delete(s.ids, s.order[s.next])
+		s.order[s.next] = id
+		s.next = (s.next + 1) % len(s.order)
+	}
+	s.ids[id] = struct{}{}
+	return true
+}
@@ -0,0 +1,224 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// channel is the Postgres NOTIFY channel the stream_events trigger announces events on
+const channel = "stream_events"
+
+// subscriptionBuffer is how many events a connection can fall behind by
+// before it is dropped and has to catch up through Last-Event-ID
+const subscriptionBuffer = 64
+
+// Subscription receives the events of one user for one connection
+type Subscription struct {
+	UserID int64
+	events chan *Event
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Events returns the channel new events arrive on
+func (s *Subscription) Events() <-chan *Event {
+	return s.events
+}
+
+// Done is closed when the hub drops the subscription, because it fell behind
+// or events may have been missed. The client should reconnect.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *Subscription) close() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// Hub fans events out to the connections of each user on this instance. It
+// listens for the events every instance writes through Postgres LISTEN/NOTIFY,
+// so a user gets events no matter which instance handled the change.
+type Hub struct {
+	repo      *Repository
+	dsn       string
+	retention time.Duration // How long events are kept for reconnecting clients
+
+	mu   sync.Mutex
+	subs map[int64]map[*Subscription]struct{}
+}
+
+// NewHub creates a new hub that listens on the database at dsn
+func NewHub(repo *Repository, dsn string, retention time.Duration) *Hub {
+	return &Hub{
+		repo:      repo,
+		dsn:       dsn,
+		retention: retention,
+		subs:      make(map[int64]map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe registers a connection for a user's events
+func (h *Hub) Subscribe(userID int64) *Subscription {
+	sub := &Subscription{
+		UserID: userID,
+		events: make(chan *Event, subscriptionBuffer),
+		done:   make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[*Subscription]struct{})
+	}
+	h.subs[userID][sub] = struct{}{}
+
+	return sub
+}
+
+// Unsubscribe removes a connection's subscription
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(sub)
+}
+
+// remove drops a subscription; the caller holds h.mu
+func (h *Hub) remove(sub *Subscription) {
+	if subs, ok := h.subs[sub.UserID]; ok {
+		delete(subs, sub)
+		if len(subs) == 0 {
+			delete(h.subs, sub.UserID)
+		}
+	}
+	sub.close()
+}
+
+// Publish hands an event to the user's connections on this instance.
+// Connections that have fallen too far behind are dropped.
+func (h *Hub) Publish(event *Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs[event.UserID] {
+		select {
+		case sub.events <- event:
+		default:
+			h.remove(sub)
+		}
+	}
+}
+
+// dropAll drops every subscription, so clients reconnect and catch up
+func (h *Hub) dropAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, subs := range h.subs {
+		for sub := range subs {
+			h.remove(sub)
+		}
+	}
+}
+
+// Start listens for events and removes expired ones until the context is cancelled
+func (h *Hub) Start(ctx context.Context) {
+	go h.listen(ctx)
+
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for {
+			h.prune(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// listen receives events announced by the stream_events trigger and publishes them,
+// starting over with increasing delays if it can't listen
+func (h *Hub) listen(ctx context.Context) {
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		err := h.listenOnce(ctx, attempt > 0)
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Printf("stream: failed to listen on %s, retrying in %s: %v", channel, backoff, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, time.Minute)
+	}
+}
+
+// listenOnce listens on the channel until the context is cancelled. It only
+// returns an error if it couldn't start listening. When resuming after such a
+// failure, connections are dropped so clients catch up on what they missed.
+func (h *Hub) listenOnce(ctx context.Context, resuming bool) error {
+	listener := pq.NewListener(h.dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("stream: listener: %v", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(channel); err != nil {
+		return err
+	}
+	if resuming {
+		h.dropAll()
+	}
+
+	// Pinging notices a dead connection sooner than waiting for a notification
+	ping := time.NewTicker(time.Minute)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n := <-listener.Notify:
+			if n == nil {
+				// The connection was re-established and notifications sent
+				// in the meantime are lost; clients catch up on reconnect
+				h.dropAll()
+				continue
+			}
+
+			event := &Event{}
+			if err := json.Unmarshal([]byte(n.Extra), event); err != nil {
+				log.Printf("stream: invalid event %q: %v", n.Extra, err)
+				continue
+			}
+			h.Publish(event)
+		case <-ping.C:
+			go listener.Ping()
+		}
+	}
+}
+
+// prune removes events older than the retention period
+func (h *Hub) prune(ctx context.Context) {
+	deleted, err := h.repo.DeleteOlderThan(ctx, h.retention)
+	if err != nil {
+		log.Printf("stream: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("stream: removed %d expired events", deleted)
+	}
+}
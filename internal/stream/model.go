@@ -0,0 +1,38 @@
+package stream
+
+import (
+	"strings"
+	"time"
+)
+
+// EventType is what a stream event is about
+type EventType string
+
+const (
+	EventNotification EventType = "NOTIFICATION" // EntityID is the new notification
+	EventActivity     EventType = "ACTIVITY"     // EntityID is the new group activity
+	EventBalance      EventType = "BALANCE"      // EntityID is the user the balance is with
+)
+
+// Name is the SSE event name clients listen for, e.g. "notification"
+func (t EventType) Name() string {
+	return strings.ToLower(string(t))
+}
+
+// Event is something a user should be told about in real time. Events are
+// written by database triggers and only point at what changed; the payload
+// is loaded when the event is sent, in the connection's language.
+type Event struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Type      EventType `json:"type"`
+	EntityID  int64     `json:"entity_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Message is an event ready to send to a client
+type Message struct {
+	ID    int64
+	Event string
+	Data  any
+}
@@ -0,0 +1,89 @@
+package stream
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Repository handles stream event persistence
+type Repository struct {
+	db *sql.DB
+}
+
+// NewRepository creates a new stream repository
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// ListAfter retrieves a user's events after the given ID, oldest first
+func (r *Repository) ListAfter(ctx context.Context, userID, afterID int64, limit int) ([]*Event, error) {
+	query := `
+		SELECT id, user_id, type, entity_id, created_at
+		FROM stream_events
+		WHERE user_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stream events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*Event
+	for rows.Next() {
+		e := &Event{}
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Type, &e.EntityID, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan stream event: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// ListShortlyBefore retrieves a user's events before the given ID that were written
+// within lookback of it, oldest first. Events are numbered when they are written but
+// announced when their transaction commits, so these may not have been sent yet when
+// the given event was.
+func (r *Repository) ListShortlyBefore(ctx context.Context, userID, beforeID int64, lookback time.Duration) ([]*Event, error) {
+	query := `
+		SELECT e.id, e.user_id, e.type, e.entity_id, e.created_at
+		FROM stream_events e
+		JOIN stream_events b ON b.id = $2
+		WHERE e.user_id = $1 AND e.id < $2
+		  AND e.created_at >= b.created_at - make_interval(secs => $3)
+		ORDER BY e.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, beforeID, lookback.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stream events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*Event
+	for rows.Next() {
+		e := &Event{}
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Type, &e.EntityID, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan stream event: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// DeleteOlderThan removes events older than age and returns how many were removed
+func (r *Repository) DeleteOlderThan(ctx context.Context, age time.Duration) (int64, error) {
+	query := `DELETE FROM stream_events WHERE created_at < NOW() - make_interval(secs => $1)`
+
+	result, err := r.db.ExecContext(ctx, query, age.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete stream events: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,117 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fkhayef/splitwise/internal/activity"
+	"github.com/fkhayef/splitwise/internal/notification"
+	"github.com/fkhayef/splitwise/internal/settlement"
+	"github.com/fkhayef/splitwise/pkg/i18n"
+)
+
+// Replaying missed events to a reconnecting client
+const (
+	replayPageSize = 500         // Events loaded at a time
+	replayLookback = time.Minute // How far before Last-Event-ID to look for events committed late
+)
+
+// Service turns stream events into the messages sent to clients
+type Service struct {
+	repo          *Repository
+	notifications *notification.Service
+	activities    *activity.Service
+	settlements   *settlement.Service
+}
+
+// NewService creates a new stream service
+func NewService(repo *Repository, notifications *notification.Service, activities *activity.Service, settlements *settlement.Service) *Service {
+	return &Service{
+		repo:          repo,
+		notifications: notifications,
+		activities:    activities,
+		settlements:   settlements,
+	}
+}
+
+// Missed calls fn for each event a user may not have received since lastEventID, oldest
+// first. Events committed shortly before it are included, as they can arrive after it;
+// some of them may already have been sent, so clients should skip IDs they've seen.
+func (s *Service) Missed(ctx context.Context, userID, lastEventID int64, fn func(*Event) error) error {
+	late, err := s.repo.ListShortlyBefore(ctx, userID, lastEventID, replayLookback)
+	if err != nil {
+		return err
+	}
+	for _, event := range late {
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	after := lastEventID
+	for {
+		events, err := s.repo.ListAfter(ctx, userID, after, replayPageSize)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := fn(event); err != nil {
+				return err
+			}
+		}
+		if len(events) < replayPageSize {
+			return nil
+		}
+		after = events[len(events)-1].ID
+	}
+}
+
+// NotificationMessage is sent for a new notification, with the count clients used to poll for
+type NotificationMessage struct {
+	Notification *notification.NotificationResponse `json:"notification"`
+	UnreadCount  int                                `json:"unread_count"`
+}
+
+// Render loads what an event points at as it is now, in the context's language.
+// It returns nil if there is nothing left to send, e.g. a deleted notification.
+func (s *Service) Render(ctx context.Context, event *Event) (*Message, error) {
+	var data any
+	switch event.Type {
+	case EventNotification:
+		n, err := s.notifications.GetByID(ctx, event.EntityID)
+		if errors.Is(err, notification.ErrNotificationNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		count, err := s.notifications.GetUnreadCount(ctx, event.UserID)
+		if err != nil {
+			return nil, err
+		}
+		data = &NotificationMessage{Notification: n.ToResponse(), UnreadCount: count}
+
+	case EventActivity:
+		a, err := s.activities.GetByID(ctx, event.EntityID)
+		if err != nil {
+			return nil, err
+		}
+		if a == nil {
+			return nil, nil
+		}
+		data = a.ToResponse(i18n.Language(ctx))
+
+	case EventBalance:
+		balance, err := s.settlements.GetNetBalanceWithUser(ctx, event.UserID, event.EntityID, i18n.T(i18n.Language(ctx), "balance.unknown_user"))
+		if err != nil {
+			return nil, err
+		}
+		data = balance
+
+	default:
+		return nil, nil
+	}
+
+	return &Message{ID: event.ID, Event: event.Type.Name(), Data: data}, nil
+}
@@ -5,12 +5,14 @@ type CreateUserRequest struct {
 	Username  string  `json:"username" validate:"required,min=3,max=50"`
 	Email     string  `json:"email" validate:"required,email"`
 	AvatarURL *string `json:"avatar_url,omitempty"`
+	Language  *string `json:"language,omitempty"` // Defaults to the request's Accept-Language
 }
 
 // UpdateUserRequest represents the request body for updating a user
 type UpdateUserRequest struct {
 	Username  *string `json:"username,omitempty" validate:"omitempty,min=3,max=50"`
 	AvatarURL *string `json:"avatar_url,omitempty"`
+	Language  *string `json:"language,omitempty"`
 }
 
 // UserResponse represents the response for a single user
@@ -19,6 +21,7 @@ type UserResponse struct {
 	Username  string  `json:"username"`
 	Email     string  `json:"email"`
 	AvatarURL *string `json:"avatar_url,omitempty"`
+	Language  string  `json:"language"`
 	CreatedAt string  `json:"created_at"`
 }
 
@@ -29,6 +32,7 @@ func (u *User) ToResponse() *UserResponse {
 		Username:  u.Username,
 		Email:     u.Email,
 		AvatarURL: u.AvatarURL,
+		Language:  u.Language,
 		CreatedAt: u.CreatedAt.Format("2006-01-02T15:04:05Z"),
 	}
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/fkhayef/splitwise/pkg/i18n"
 	"github.com/fkhayef/splitwise/pkg/response"
 )
 
@@ -48,6 +49,10 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 			response.Conflict(w, err.Error())
 			return
 		}
+		if errors.Is(err, i18n.ErrUnsupportedLanguage) {
+			response.BadRequest(w, err.Error())
+			return
+		}
 		response.InternalError(w, "Failed to create user")
 		return
 	}
@@ -132,6 +137,10 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 			response.NotFound(w, err.Error())
 			return
 		}
+		if errors.Is(err, i18n.ErrUnsupportedLanguage) {
+			response.BadRequest(w, err.Error())
+			return
+		}
 		response.InternalError(w, "Failed to update user")
 		return
 	}
@@ -149,6 +158,10 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.service.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, ErrUnsettledSplits) {
+			response.Conflict(w, err.Error())
+			return
+		}
 		response.InternalError(w, "Failed to delete user")
 		return
 	}
@@ -8,5 +8,6 @@ type User struct {
 	Username  string    `json:"username"`
 	Email     string    `json:"email"`
 	AvatarURL *string   `json:"avatar_url,omitempty"`
+	Language  string    `json:"language"` // Preferred language for notifications, e.g. "en" or "ar"
 	CreatedAt time.Time `json:"created_at"`
 }
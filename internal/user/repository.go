@@ -19,17 +19,18 @@ func NewRepository(db *sql.DB) *Repository {
 // Create inserts a new user into the database
 func (r *Repository) Create(ctx context.Context, req *CreateUserRequest) (*User, error) {
 	query := `
-		INSERT INTO users (username, email, avatar_url)
-		VALUES ($1, $2, $3)
-		RETURNING id, username, email, avatar_url, created_at
+		INSERT INTO users (username, email, avatar_url, language)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, username, email, avatar_url, language, created_at
 	`
 
 	user := &User{}
-	err := r.db.QueryRowContext(ctx, query, req.Username, req.Email, req.AvatarURL).Scan(
+	err := r.db.QueryRowContext(ctx, query, req.Username, req.Email, req.AvatarURL, req.Language).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
 		&user.AvatarURL,
+		&user.Language,
 		&user.CreatedAt,
 	)
 	if err != nil {
@@ -42,7 +43,7 @@ func (r *Repository) Create(ctx context.Context, req *CreateUserRequest) (*User,
 // GetByID retrieves a user by their ID
 func (r *Repository) GetByID(ctx context.Context, id int64) (*User, error) {
 	query := `
-		SELECT id, username, email, avatar_url, created_at
+		SELECT id, username, email, avatar_url, language, created_at
 		FROM users
 		WHERE id = $1
 	`
@@ -53,6 +54,7 @@ func (r *Repository) GetByID(ctx context.Context, id int64) (*User, error) {
 		&user.Username,
 		&user.Email,
 		&user.AvatarURL,
+		&user.Language,
 		&user.CreatedAt,
 	)
 	if err != nil {
@@ -68,7 +70,7 @@ func (r *Repository) GetByID(ctx context.Context, id int64) (*User, error) {
 // GetByEmail retrieves a user by their email
 func (r *Repository) GetByEmail(ctx context.Context, email string) (*User, error) {
 	query := `
-		SELECT id, username, email, avatar_url, created_at
+		SELECT id, username, email, avatar_url, language, created_at
 		FROM users
 		WHERE email = $1
 	`
@@ -79,6 +81,7 @@ func (r *Repository) GetByEmail(ctx context.Context, email string) (*User, error
 		&user.Username,
 		&user.Email,
 		&user.AvatarURL,
+		&user.Language,
 		&user.CreatedAt,
 	)
 	if err != nil {
@@ -102,7 +105,7 @@ func (r *Repository) List(ctx context.Context, limit, offset int) ([]*User, int,
 
 	// Get users
 	query := `
-		SELECT id, username, email, avatar_url, created_at
+		SELECT id, username, email, avatar_url, language, created_at
 		FROM users
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
@@ -122,6 +125,7 @@ func (r *Repository) List(ctx context.Context, limit, offset int) ([]*User, int,
 			&user.Username,
 			&user.Email,
 			&user.AvatarURL,
+			&user.Language,
 			&user.CreatedAt,
 		); err != nil {
 			return nil, 0, fmt.Errorf("failed to scan user: %w", err)
@@ -137,17 +141,19 @@ func (r *Repository) Update(ctx context.Context, id int64, req *UpdateUserReques
 	query := `
 		UPDATE users
 		SET username = COALESCE($2, username),
-		    avatar_url = COALESCE($3, avatar_url)
+		    avatar_url = COALESCE($3, avatar_url),
+		    language = COALESCE($4, language)
 		WHERE id = $1
-		RETURNING id, username, email, avatar_url, created_at
+		RETURNING id, username, email, avatar_url, language, created_at
 	`
 
 	user := &User{}
-	err := r.db.QueryRowContext(ctx, query, id, req.Username, req.AvatarURL).Scan(
+	err := r.db.QueryRowContext(ctx, query, id, req.Username, req.AvatarURL, req.Language).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
 		&user.AvatarURL,
+		&user.Language,
 		&user.CreatedAt,
 	)
 	if err != nil {
@@ -160,6 +166,24 @@ func (r *Repository) Update(ctx context.Context, id int64, req *UpdateUserReques
 	return user, nil
 }
 
+// CountUnsettledSplits counts splits the user owes or is owed that are not yet confirmed
+func (r *Repository) CountUnsettledSplits(ctx context.Context, userID int64) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM splits s
+		JOIN expenses e ON s.expense_id = e.id
+		WHERE (s.borrower_id = $1 OR e.payer_id = $1)
+		  AND s.borrower_id != e.payer_id
+		  AND s.status IN ('PENDING', 'PAID', 'DISPUTED')
+	`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unsettled splits: %w", err)
+	}
+	return count, nil
+}
+
 // Delete removes a user from the database
 func (r *Repository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM users WHERE id = $1`
@@ -3,12 +3,15 @@ package user
 import (
 	"context"
 	"errors"
+
+	"github.com/fkhayef/splitwise/pkg/i18n"
 )
 
 // Common errors
 var (
 	ErrUserNotFound      = errors.New("user not found")
 	ErrEmailAlreadyInUse = errors.New("email already in use")
+	ErrUnsettledSplits   = errors.New("user has unsettled splits and cannot be deleted")
 )
 
 // Service handles user business logic
@@ -32,6 +35,15 @@ func (s *Service) Create(ctx context.Context, req *CreateUserRequest) (*User, er
 		return nil, ErrEmailAlreadyInUse
 	}
 
+	// Without a language, use the one the request was made in
+	lang := i18n.Language(ctx)
+	if req.Language != nil {
+		if lang, err = i18n.Normalize(*req.Language); err != nil {
+			return nil, err
+		}
+	}
+	req.Language = &lang
+
 	return s.repo.Create(ctx, req)
 }
 
@@ -71,10 +83,28 @@ func (s *Service) Update(ctx context.Context, id int64, req *UpdateUserRequest)
 		return nil, ErrUserNotFound
 	}
 
+	if req.Language != nil {
+		lang, err := i18n.Normalize(*req.Language)
+		if err != nil {
+			return nil, err
+		}
+		req.Language = &lang
+	}
+
 	return s.repo.Update(ctx, id, req)
 }
 
-// Delete removes a user
+// Delete removes a user.
+// Deleting cascades to group memberships, so users who still owe or are owed
+// money are kept until their splits are settled.
 func (s *Service) Delete(ctx context.Context, id int64) error {
+	count, err := s.repo.CountUnsettledSplits(ctx, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrUnsettledSplits
+	}
+
 	return s.repo.Delete(ctx, id)
 }
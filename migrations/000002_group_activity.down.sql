@@ -0,0 +1,3 @@
+-- Rollback migration: Drop group activity feed
+
+DROP TABLE IF EXISTS group_activities;
@@ -0,0 +1,16 @@
+-- Group activity feed
+-- Chronological record of what happened in a group (expenses, payments, members)
+
+CREATE TABLE group_activities (
+    id SERIAL PRIMARY KEY,
+    group_id INTEGER NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    actor_id INTEGER NOT NULL REFERENCES users(id),
+    type VARCHAR(50) NOT NULL, -- e.g., 'EXPENSE_ADDED', 'SPLIT_PAID', 'MEMBER_JOINED'
+    entity_type VARCHAR(50), -- e.g., 'EXPENSE', 'SPLIT', 'SETTLEMENT', 'GROUP'
+    entity_id INTEGER,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_group_activities_group_id ON group_activities(group_id, created_at DESC);
+CREATE INDEX idx_group_activities_actor_id ON group_activities(actor_id);
@@ -0,0 +1,5 @@
+-- Rollback migration: Drop group invitations
+
+DROP TABLE IF EXISTS group_invitations;
+
+DROP TYPE IF EXISTS invitation_status;
@@ -0,0 +1,24 @@
+-- Group invitations by email
+-- Lets members invite people who may not have an account yet
+
+CREATE TYPE invitation_status AS ENUM ('PENDING', 'ACCEPTED', 'DECLINED', 'REVOKED');
+
+CREATE TABLE group_invitations (
+    id SERIAL PRIMARY KEY,
+    group_id INTEGER NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role member_role DEFAULT 'MEMBER',
+    invited_by INTEGER NOT NULL REFERENCES users(id),
+    status invitation_status DEFAULT 'PENDING',
+    expires_at TIMESTAMP NOT NULL,
+    responded_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_group_invitations_group_id ON group_invitations(group_id);
+CREATE INDEX idx_group_invitations_email ON group_invitations(email);
+
+-- Only one open invitation per email and group
+CREATE UNIQUE INDEX idx_group_invitations_pending
+    ON group_invitations(group_id, email)
+    WHERE status = 'PENDING';
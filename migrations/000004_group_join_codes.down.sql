@@ -0,0 +1,8 @@
+-- Rollback migration: Drop group join codes
+
+ALTER TABLE groups
+    DROP COLUMN IF EXISTS join_code_uses,
+    DROP COLUMN IF EXISTS join_code_max_uses,
+    DROP COLUMN IF EXISTS join_code_expires_at,
+    DROP COLUMN IF EXISTS join_code_enabled,
+    DROP COLUMN IF EXISTS join_code;
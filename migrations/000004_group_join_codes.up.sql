@@ -0,0 +1,9 @@
+-- Shareable join codes for groups
+-- One active code per group; admins can rotate, limit or disable it
+
+ALTER TABLE groups
+    ADD COLUMN join_code VARCHAR(16) UNIQUE,
+    ADD COLUMN join_code_enabled BOOLEAN DEFAULT FALSE,
+    ADD COLUMN join_code_expires_at TIMESTAMP,
+    ADD COLUMN join_code_max_uses INTEGER CHECK (join_code_max_uses > 0),
+    ADD COLUMN join_code_uses INTEGER DEFAULT 0;
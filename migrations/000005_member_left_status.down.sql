@@ -0,0 +1,7 @@
+-- Rollback migration: Drop left_at
+-- PostgreSQL cannot remove an enum value, so 'LEFT' stays in member_status;
+-- former members are removed instead so no row keeps the unused status.
+
+DELETE FROM group_members WHERE status = 'LEFT';
+
+ALTER TABLE group_members DROP COLUMN IF EXISTS left_at;
@@ -0,0 +1,6 @@
+-- Members who leave keep their membership row (status LEFT)
+-- so their expenses and splits stay visible in the group's history
+
+ALTER TYPE member_status ADD VALUE IF NOT EXISTS 'LEFT';
+
+ALTER TABLE group_members ADD COLUMN left_at TIMESTAMP;
@@ -0,0 +1,11 @@
+-- Rollback migration: Drop group lifecycle columns
+
+DELETE FROM group_activities WHERE actor_id IS NULL;
+ALTER TABLE group_activities ALTER COLUMN actor_id SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_groups_lifecycle;
+
+ALTER TABLE groups
+    DROP COLUMN IF EXISTS settle_reminder_sent_at,
+    DROP COLUMN IF EXISTS archived_at,
+    DROP COLUMN IF EXISTS end_date;
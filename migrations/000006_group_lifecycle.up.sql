@@ -0,0 +1,12 @@
+-- Group lifecycle: end dates for temporary groups and archiving
+-- Archived groups are read-only but stay visible in history
+
+ALTER TABLE groups
+    ADD COLUMN end_date DATE,
+    ADD COLUMN archived_at TIMESTAMP,
+    ADD COLUMN settle_reminder_sent_at TIMESTAMP;
+
+CREATE INDEX idx_groups_lifecycle ON groups(end_date) WHERE is_temporary AND archived_at IS NULL;
+
+-- System events (e.g. automatic archiving) have no acting user
+ALTER TABLE group_activities ALTER COLUMN actor_id DROP NOT NULL;
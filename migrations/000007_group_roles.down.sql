@@ -0,0 +1,8 @@
+-- Rollback migration: Drop group ownership and settings
+
+DROP INDEX IF EXISTS idx_groups_owner_id;
+
+ALTER TABLE groups
+    DROP COLUMN IF EXISTS only_admins_invite,
+    DROP COLUMN IF EXISTS only_admins_add_expenses,
+    DROP COLUMN IF EXISTS owner_id;
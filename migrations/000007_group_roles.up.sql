@@ -0,0 +1,20 @@
+-- Group ownership and admin-controlled settings
+
+ALTER TABLE groups
+    ADD COLUMN owner_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN only_admins_add_expenses BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN only_admins_invite BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Existing groups are owned by their longest-standing admin
+UPDATE groups g
+SET owner_id = (
+    SELECT gm.user_id
+    FROM group_members gm
+    WHERE gm.group_id = g.id
+      AND gm.role = 'ADMIN'
+      AND gm.status = 'JOINED'
+    ORDER BY gm.joined_at, gm.id
+    LIMIT 1
+);
+
+CREATE INDEX idx_groups_owner_id ON groups(owner_id);
@@ -0,0 +1,5 @@
+-- Rollback migration: Drop expense categories
+
+DROP INDEX IF EXISTS idx_expenses_group_category;
+
+ALTER TABLE expenses DROP COLUMN IF EXISTS category;
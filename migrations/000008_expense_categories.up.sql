@@ -0,0 +1,5 @@
+-- Expense categories for spending summaries
+
+ALTER TABLE expenses ADD COLUMN category VARCHAR(50) NOT NULL DEFAULT 'General';
+
+CREATE INDEX idx_expenses_group_category ON expenses(group_id, category);
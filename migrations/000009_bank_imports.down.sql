@@ -0,0 +1,7 @@
+-- Rollback migration: Drop bank statement imports
+
+DROP TABLE IF EXISTS bank_import_transactions;
+DROP TABLE IF EXISTS bank_import_batches;
+
+DROP TYPE IF EXISTS bank_transaction_status;
+DROP TYPE IF EXISTS bank_import_status;
@@ -0,0 +1,41 @@
+-- Bank statement imports
+-- Statements are parsed into a batch of transactions that the user reviews
+-- before confirming them as expenses in a group
+
+CREATE TYPE bank_import_status AS ENUM ('OPEN', 'COMPLETED', 'DISCARDED');
+CREATE TYPE bank_transaction_status AS ENUM ('PROPOSED', 'DUPLICATE', 'IGNORED', 'IMPORTED');
+
+CREATE TABLE bank_import_batches (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    format VARCHAR(10) NOT NULL, -- OFX, CSV
+    filename VARCHAR(255),
+    currency_code VARCHAR(3),
+    status bank_import_status NOT NULL DEFAULT 'OPEN',
+    group_id INTEGER REFERENCES groups(id) ON DELETE SET NULL, -- Set on confirm
+    created_at TIMESTAMP DEFAULT NOW(),
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX idx_bank_import_batches_user_id ON bank_import_batches(user_id);
+
+CREATE TABLE bank_import_transactions (
+    id SERIAL PRIMARY KEY,
+    batch_id INTEGER NOT NULL REFERENCES bank_import_batches(id) ON DELETE CASCADE,
+    external_id VARCHAR(255) NOT NULL, -- FITID for OFX, a content hash for CSV
+    posted_at DATE NOT NULL,
+    description VARCHAR(255) NOT NULL,
+    amount DECIMAL(12,2) NOT NULL, -- Negative for money leaving the account
+    category VARCHAR(50),
+    status bank_transaction_status NOT NULL,
+    reason VARCHAR(255),
+    match_expense_id INTEGER REFERENCES expenses(id) ON DELETE SET NULL,
+    match_score REAL,
+    expense_id INTEGER REFERENCES expenses(id) ON DELETE SET NULL,
+
+    -- A statement can't list the same transaction twice
+    UNIQUE(batch_id, external_id)
+);
+
+CREATE INDEX idx_bank_import_transactions_batch_id ON bank_import_transactions(batch_id);
+CREATE INDEX idx_bank_import_transactions_external_id ON bank_import_transactions(external_id);
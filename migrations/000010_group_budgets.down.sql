@@ -0,0 +1,4 @@
+-- Rollback migration: Drop group budgets
+
+DROP TABLE IF EXISTS budget_alerts;
+DROP TABLE IF EXISTS group_budgets;
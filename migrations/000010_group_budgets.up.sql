@@ -0,0 +1,24 @@
+-- Monthly group budgets, overall or per expense category,
+-- and a record of the threshold alerts already sent each month
+
+CREATE TABLE group_budgets (
+    id SERIAL PRIMARY KEY,
+    group_id INTEGER NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    category VARCHAR(50), -- NULL for the overall budget
+    monthly_limit DECIMAL(10,2) NOT NULL CHECK (monthly_limit > 0),
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- One overall budget and one budget per category in each group
+CREATE UNIQUE INDEX idx_group_budgets_scope ON group_budgets(group_id, LOWER(COALESCE(category, '')));
+
+CREATE TABLE budget_alerts (
+    budget_id INTEGER NOT NULL REFERENCES group_budgets(id) ON DELETE CASCADE,
+    month DATE NOT NULL, -- First day of the month
+    threshold SMALLINT NOT NULL, -- Percent of the limit, e.g. 80 or 100
+    sent_at TIMESTAMP DEFAULT NOW(),
+
+    PRIMARY KEY (budget_id, month, threshold)
+);
@@ -0,0 +1,5 @@
+-- Rollback migration: Drop partial settlements
+
+DROP TABLE IF EXISTS settlement_allocations;
+ALTER TABLE splits DROP COLUMN IF EXISTS amount_settled;
+ALTER TABLE settlements DROP COLUMN IF EXISTS is_partial;
@@ -0,0 +1,19 @@
+-- Partial settlements: a settlement can pay part of what is owed.
+-- Splits it covers in full are locked to it as before; the split it only
+-- partly covers stays open, with the covered part tracked per settlement.
+
+ALTER TABLE settlements ADD COLUMN is_partial BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Part of a split already covered by partial settlements that weren't rejected
+ALTER TABLE splits ADD COLUMN amount_settled DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (amount_settled >= 0);
+
+CREATE TABLE settlement_allocations (
+    settlement_id INTEGER NOT NULL REFERENCES settlements(id) ON DELETE CASCADE,
+    split_id INTEGER NOT NULL REFERENCES splits(id) ON DELETE CASCADE,
+    amount DECIMAL(10,2) NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP DEFAULT NOW(),
+
+    PRIMARY KEY (settlement_id, split_id)
+);
+
+CREATE INDEX idx_settlement_allocations_split_id ON settlement_allocations(split_id);
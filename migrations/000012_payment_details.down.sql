@@ -0,0 +1,15 @@
+-- Rollback migration: Drop payment details
+
+ALTER TABLE splits
+    DROP COLUMN IF EXISTS paid_at,
+    DROP COLUMN IF EXISTS payment_note,
+    DROP COLUMN IF EXISTS payment_reference,
+    DROP COLUMN IF EXISTS payment_method;
+
+ALTER TABLE settlements
+    DROP COLUMN IF EXISTS paid_at,
+    DROP COLUMN IF EXISTS payment_note,
+    DROP COLUMN IF EXISTS payment_reference,
+    DROP COLUMN IF EXISTS payment_method;
+
+DROP TYPE IF EXISTS payment_method;
@@ -0,0 +1,15 @@
+-- How splits and settlements were paid outside the app
+
+CREATE TYPE payment_method AS ENUM ('CASH', 'BANK_TRANSFER', 'CARD', 'WALLET');
+
+ALTER TABLE settlements
+    ADD COLUMN payment_method payment_method,
+    ADD COLUMN payment_reference VARCHAR(100), -- External transaction ID
+    ADD COLUMN payment_note VARCHAR(500),
+    ADD COLUMN paid_at TIMESTAMP;
+
+ALTER TABLE splits
+    ADD COLUMN payment_method payment_method,
+    ADD COLUMN payment_reference VARCHAR(100),
+    ADD COLUMN payment_note VARCHAR(500),
+    ADD COLUMN paid_at TIMESTAMP;
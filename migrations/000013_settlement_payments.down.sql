@@ -0,0 +1,6 @@
+-- Rollback migration: Drop settlement payments
+
+DROP TABLE IF EXISTS payment_webhook_events;
+DROP TABLE IF EXISTS payment_intents;
+DROP TYPE IF EXISTS reconciliation_status;
+DROP TYPE IF EXISTS payment_intent_status;
@@ -0,0 +1,35 @@
+-- Paying settlements through payment providers: one row per payment intent,
+-- plus the webhook events already handled so redeliveries are ignored
+
+CREATE TYPE payment_intent_status AS ENUM ('PENDING', 'PROCESSING', 'SUCCEEDED', 'FAILED');
+CREATE TYPE reconciliation_status AS ENUM ('MATCHED', 'UNDERPAID', 'OVERPAID', 'CURRENCY_MISMATCH');
+
+CREATE TABLE payment_intents (
+    id SERIAL PRIMARY KEY,
+    settlement_id INTEGER NOT NULL REFERENCES settlements(id) ON DELETE CASCADE,
+    provider VARCHAR(30) NOT NULL,
+    external_id VARCHAR(100) NOT NULL, -- The provider's ID for the intent
+    amount DECIMAL(10,2) NOT NULL,
+    currency_code VARCHAR(3) NOT NULL,
+    status payment_intent_status NOT NULL DEFAULT 'PENDING',
+    checkout_url TEXT,
+    amount_received DECIMAL(10,2),
+    currency_received VARCHAR(3),
+    reconciliation reconciliation_status, -- Set once the money arrives
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+
+    UNIQUE (provider, external_id)
+);
+
+CREATE INDEX idx_payment_intents_settlement_id ON payment_intents(settlement_id);
+
+CREATE TABLE payment_webhook_events (
+    provider VARCHAR(30) NOT NULL,
+    event_id VARCHAR(100) NOT NULL,
+    external_id VARCHAR(100) NOT NULL,
+    status VARCHAR(30) NOT NULL, -- Provider-specific status
+    received_at TIMESTAMP DEFAULT NOW(),
+
+    PRIMARY KEY (provider, event_id)
+);
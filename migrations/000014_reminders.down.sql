@@ -0,0 +1,4 @@
+-- Rollback migration: Drop payment reminders
+
+DROP TABLE IF EXISTS reminders;
+DROP TABLE IF EXISTS reminder_settings;
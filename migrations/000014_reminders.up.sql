@@ -0,0 +1,21 @@
+-- Payment reminders: per-user opt-out settings and a log of every reminder
+-- sent, used to space out automatic reminders and rate-limit manual nudges
+
+CREATE TABLE reminder_settings (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    automatic BOOLEAN NOT NULL DEFAULT TRUE, -- Scheduled reminders about open debts
+    nudges BOOLEAN NOT NULL DEFAULT TRUE,    -- Reminders sent by the people owed
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE reminders (
+    id SERIAL PRIMARY KEY,
+    from_user_id INTEGER REFERENCES users(id) ON DELETE CASCADE, -- NULL for automatic reminders
+    to_user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    entity_type VARCHAR(20) NOT NULL, -- 'SPLIT' or 'SETTLEMENT'
+    entity_id INTEGER NOT NULL,
+    sent_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_reminders_entity ON reminders(entity_type, entity_id, sent_at);
+CREATE INDEX idx_reminders_pair ON reminders(from_user_id, to_user_id, sent_at);
@@ -0,0 +1,5 @@
+-- Rollback migration: Drop settlement cancellation
+-- PostgreSQL cannot remove an enum value, so 'CANCELLED' stays in settlement_status;
+-- cancelled settlements are recorded as rejected instead, which also releases their splits.
+
+UPDATE settlements SET status = 'REJECTED' WHERE status = 'CANCELLED';
@@ -0,0 +1,3 @@
+-- The payer can cancel a settlement they created while it is still PENDING
+
+ALTER TYPE settlement_status ADD VALUE IF NOT EXISTS 'CANCELLED';
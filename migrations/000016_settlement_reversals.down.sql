@@ -0,0 +1,9 @@
+-- Rollback migration: Drop settlement reversals
+-- PostgreSQL cannot remove an enum value, so 'REVERSED' stays in settlement_status;
+-- reversed settlements are recorded as rejected instead, since their splits are already reopened.
+
+UPDATE settlements SET status = 'REJECTED' WHERE status = 'REVERSED';
+
+DROP TABLE IF EXISTS settlement_reversal_splits;
+DROP TABLE IF EXISTS settlement_reversals;
+ALTER TABLE settlements DROP COLUMN IF EXISTS confirmed_at;
@@ -0,0 +1,29 @@
+-- Confirmed settlements can be reversed by the receiver for a while after
+-- confirmation (e.g. when a bank transfer bounces). A reversal is never
+-- deleted: it records who reversed the settlement and why, and every split
+-- it reopened along with the state the split was in beforehand.
+
+ALTER TYPE settlement_status ADD VALUE IF NOT EXISTS 'REVERSED';
+
+-- When the settlement was confirmed; the reversal window starts here
+ALTER TABLE settlements ADD COLUMN confirmed_at TIMESTAMP;
+UPDATE settlements SET confirmed_at = COALESCE(paid_at, created_at) WHERE status = 'CONFIRMED';
+
+CREATE TABLE settlement_reversals (
+    id SERIAL PRIMARY KEY,
+    settlement_id INTEGER NOT NULL UNIQUE REFERENCES settlements(id) ON DELETE CASCADE,
+    reversed_by INTEGER NOT NULL REFERENCES users(id),
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Compensating records: what the reversal gave back on each split
+CREATE TABLE settlement_reversal_splits (
+    reversal_id INTEGER NOT NULL REFERENCES settlement_reversals(id) ON DELETE CASCADE,
+    split_id INTEGER NOT NULL REFERENCES splits(id) ON DELETE CASCADE,
+    previous_status split_status NOT NULL,
+    was_locked BOOLEAN NOT NULL,   -- Locked to the settlement in full, or only partly covered by it
+    amount DECIMAL(10,2) NOT NULL, -- Amount reopened on the split
+
+    PRIMARY KEY (reversal_id, split_id)
+);
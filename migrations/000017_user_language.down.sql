@@ -0,0 +1,3 @@
+-- Rollback migration: Drop users' preferred language
+
+ALTER TABLE users DROP COLUMN IF EXISTS language;
@@ -0,0 +1,4 @@
+-- Users' preferred language, used for messages rendered outside a request
+-- (notifications); API responses follow the request's Accept-Language
+
+ALTER TABLE users ADD COLUMN language VARCHAR(10) NOT NULL DEFAULT 'en';
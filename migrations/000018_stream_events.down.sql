@@ -0,0 +1,14 @@
+-- Rollback migration: Drop real-time stream events and their triggers
+
+DROP TRIGGER IF EXISTS splits_stream_balance_update ON splits;
+DROP TRIGGER IF EXISTS splits_stream_balance_delete ON splits;
+DROP TRIGGER IF EXISTS splits_stream_balance_insert ON splits;
+DROP TRIGGER IF EXISTS group_activities_stream ON group_activities;
+DROP TRIGGER IF EXISTS notifications_stream ON notifications;
+
+DROP FUNCTION IF EXISTS stream_split_balance();
+DROP FUNCTION IF EXISTS stream_activity();
+DROP FUNCTION IF EXISTS stream_notification();
+
+DROP TABLE IF EXISTS stream_events;
+DROP FUNCTION IF EXISTS stream_events_notify();
@@ -0,0 +1,102 @@
+-- Real-time stream: events pushed to connected clients over /api/v1/stream.
+-- Triggers on the tables the events describe write them, so every API instance
+-- produces them, and each new event is announced with NOTIFY on the
+-- 'stream_events' channel for the instances holding the user's connections.
+-- Clients that reconnect with Last-Event-ID are replayed the events they missed.
+
+CREATE TABLE stream_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL, -- 'NOTIFICATION', 'ACTIVITY' or 'BALANCE'
+    entity_id INTEGER NOT NULL, -- Notification or activity ID, or the other user of a balance
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_stream_events_user_id ON stream_events(user_id, id);
+CREATE INDEX idx_stream_events_created_at ON stream_events(created_at);
+
+-- Announce every event with its full row, so listeners don't have to read it back
+CREATE FUNCTION stream_events_notify() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('stream_events', json_build_object(
+        'id', NEW.id, 'user_id', NEW.user_id, 'type', NEW.type, 'entity_id', NEW.entity_id
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER stream_events_notify AFTER INSERT ON stream_events
+    FOR EACH ROW EXECUTE FUNCTION stream_events_notify();
+
+-- New notifications go to their recipient
+CREATE FUNCTION stream_notification() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO stream_events (user_id, type, entity_id) VALUES (NEW.recipient_id, 'NOTIFICATION', NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER notifications_stream AFTER INSERT ON notifications
+    FOR EACH ROW EXECUTE FUNCTION stream_notification();
+
+-- New group activity goes to every current member of the group
+CREATE FUNCTION stream_activity() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO stream_events (user_id, type, entity_id)
+    SELECT user_id, 'ACTIVITY', NEW.id FROM group_members
+    WHERE group_id = NEW.group_id AND status = 'JOINED';
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER group_activities_stream AFTER INSERT ON group_activities
+    FOR EACH ROW EXECUTE FUNCTION stream_activity();
+
+-- Any change to what a split owes changes the balance between its borrower and
+-- the expense's payer, so both are told. Settlements lock, settle and release
+-- splits, so they are covered too. The triggers run once per statement, so a
+-- settlement touching many splits between two users tells each of them once.
+CREATE FUNCTION stream_split_balance() RETURNS trigger AS $$
+DECLARE
+    expense_ids INTEGER[];
+    borrower_ids INTEGER[];
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        SELECT array_agg(expense_id), array_agg(borrower_id) INTO expense_ids, borrower_ids
+        FROM new_splits;
+    ELSIF TG_OP = 'DELETE' THEN
+        SELECT array_agg(expense_id), array_agg(borrower_id) INTO expense_ids, borrower_ids
+        FROM old_splits;
+    ELSE
+        SELECT array_agg(n.expense_id), array_agg(n.borrower_id) INTO expense_ids, borrower_ids
+        FROM new_splits n
+        JOIN old_splits o ON o.id = n.id
+        WHERE (o.status, o.amount_owed, o.amount_settled, o.settlement_id)
+              IS DISTINCT FROM (n.status, n.amount_owed, n.amount_settled, n.settlement_id);
+    END IF;
+
+    WITH pairs AS (
+        SELECT DISTINCT c.borrower_id, e.payer_id
+        FROM unnest(expense_ids, borrower_ids) AS c(expense_id, borrower_id)
+        JOIN expenses e ON e.id = c.expense_id
+        WHERE e.payer_id <> c.borrower_id
+    )
+    INSERT INTO stream_events (user_id, type, entity_id)
+    SELECT borrower_id, 'BALANCE', payer_id FROM pairs
+    UNION
+    SELECT payer_id, 'BALANCE', borrower_id FROM pairs;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER splits_stream_balance_insert AFTER INSERT ON splits
+    REFERENCING NEW TABLE AS new_splits
+    FOR EACH STATEMENT EXECUTE FUNCTION stream_split_balance();
+
+CREATE TRIGGER splits_stream_balance_delete AFTER DELETE ON splits
+    REFERENCING OLD TABLE AS old_splits
+    FOR EACH STATEMENT EXECUTE FUNCTION stream_split_balance();
+
+CREATE TRIGGER splits_stream_balance_update AFTER UPDATE ON splits
+    REFERENCING OLD TABLE AS old_splits NEW TABLE AS new_splits
+    FOR EACH STATEMENT EXECUTE FUNCTION stream_split_balance();
@@ -0,0 +1,10 @@
+-- Rollback migration: Drop notification delivery channels and the outbox
+
+DROP TABLE IF EXISTS notification_deliveries;
+DROP TABLE IF EXISTS webhook_endpoints;
+DROP TABLE IF EXISTS push_subscriptions;
+DROP TABLE IF EXISTS notification_preferences;
+
+ALTER TABLE notifications DROP COLUMN IF EXISTS type;
+
+DROP TYPE IF EXISTS delivery_status;
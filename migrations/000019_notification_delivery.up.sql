@@ -0,0 +1,56 @@
+-- Notification delivery: notifications are also sent by email, Web Push and
+-- outbound webhooks. Each delivery is queued in an outbox and retried with
+-- backoff until it is sent or runs out of attempts.
+
+CREATE TYPE delivery_status AS ENUM ('PENDING', 'SENT', 'FAILED', 'SKIPPED');
+
+-- Stored so users can choose channels per type; NULL for older notifications
+ALTER TABLE notifications ADD COLUMN type VARCHAR(30);
+
+-- Channel choices users have changed; anything missing uses the channel's default
+CREATE TABLE notification_preferences (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    notification_type VARCHAR(30) NOT NULL, -- e.g. 'EXPENSE_ADDED', 'REMINDER'
+    channel VARCHAR(20) NOT NULL, -- 'EMAIL', 'PUSH' or 'WEBHOOK'
+    enabled BOOLEAN NOT NULL,
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (user_id, notification_type, channel)
+);
+
+-- Browser push subscriptions (one per device)
+CREATE TABLE push_subscriptions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    endpoint TEXT NOT NULL UNIQUE,
+    p256dh VARCHAR(100) NOT NULL, -- Subscription public key, base64url
+    auth VARCHAR(50) NOT NULL,    -- Subscription auth secret, base64url
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_push_subscriptions_user_id ON push_subscriptions(user_id);
+
+-- Where a user's webhooks are posted, and the key they are signed with
+CREATE TABLE webhook_endpoints (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Outbox of deliveries, one per notification and channel
+CREATE TABLE notification_deliveries (
+    id SERIAL PRIMARY KEY,
+    notification_id INTEGER NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL,
+    status delivery_status NOT NULL DEFAULT 'PENDING',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    sent_at TIMESTAMP,
+    UNIQUE (notification_id, channel)
+);
+
+CREATE INDEX idx_notification_deliveries_due ON notification_deliveries(next_attempt_at) WHERE status = 'PENDING';
@@ -0,0 +1,6 @@
+-- Rollback migration: Block deleting users who have activity again
+
+ALTER TABLE group_activities
+    DROP CONSTRAINT group_activities_actor_id_fkey,
+    ADD CONSTRAINT group_activities_actor_id_fkey
+        FOREIGN KEY (actor_id) REFERENCES users(id);
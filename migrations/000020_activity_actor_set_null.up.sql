@@ -0,0 +1,7 @@
+-- Keep activity when its actor's account is deleted; the entry shows no actor,
+-- like system events (actor_id is nullable since 000006)
+
+ALTER TABLE group_activities
+    DROP CONSTRAINT group_activities_actor_id_fkey,
+    ADD CONSTRAINT group_activities_actor_id_fkey
+        FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,5 @@
+-- Rollback migration: Drop the SETTLEMENT_CLOSED reconciliation status
+-- PostgreSQL cannot remove an enum value, so 'SETTLEMENT_CLOSED' stays in reconciliation_status;
+-- those payments are recorded as overpaid instead, which keeps them in the mismatched list.
+
+UPDATE payment_intents SET reconciliation = 'OVERPAID' WHERE reconciliation = 'SETTLEMENT_CLOSED';
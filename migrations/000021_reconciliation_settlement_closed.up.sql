@@ -0,0 +1,4 @@
+-- Provider payments that arrive after their settlement was confirmed, rejected or
+-- cancelled are flagged so the receiver can refund or reconcile them
+
+ALTER TYPE reconciliation_status ADD VALUE IF NOT EXISTS 'SETTLEMENT_CLOSED';
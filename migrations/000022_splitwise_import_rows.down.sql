@@ -0,0 +1,3 @@
+-- Rollback migration: Drop remembered Splitwise import rows
+
+DROP TABLE IF EXISTS splitwise_import_rows;
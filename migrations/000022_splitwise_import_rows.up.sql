@@ -0,0 +1,12 @@
+-- Splitwise import rows
+-- Each imported row is remembered by a hash of its content, so importing
+-- the same export into a group again skips the rows it already created
+
+CREATE TABLE splitwise_import_rows (
+    group_id INTEGER NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    row_hash VARCHAR(64) NOT NULL, -- Date, description, cost and shares
+    expense_id INTEGER NOT NULL REFERENCES expenses(id) ON DELETE CASCADE, -- Deleting the expense lets the row be imported again
+    created_at TIMESTAMP DEFAULT NOW(),
+
+    PRIMARY KEY (group_id, row_hash)
+);
@@ -0,0 +1,128 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Format is the file format of an export
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// ErrUnsupportedFormat is returned for formats other than csv and json
+var ErrUnsupportedFormat = errors.New("format must be csv or json")
+
+// ParseFormat reads a format query value, defaulting to CSV
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(value)) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// Writer streams an export to the client as CSV rows or as a JSON array,
+// one record at a time so large exports never sit in memory.
+//
+// Nothing is sent until the first record (or Close), so a handler can still
+// reply with a normal error response if the export fails before producing output.
+type Writer struct {
+	w        http.ResponseWriter
+	format   Format
+	filename string
+	header   []string
+
+	csv     *csv.Writer
+	json    *json.Encoder
+	started bool
+	records int
+}
+
+// NewWriter creates a writer that sends filename (without extension) in the
+// given format. header is the CSV column row and is ignored for JSON.
+func NewWriter(w http.ResponseWriter, format Format, filename string, header []string) *Writer {
+	return &Writer{w: w, format: format, filename: filename, header: header}
+}
+
+// Format returns the format being written
+func (x *Writer) Format() Format {
+	return x.format
+}
+
+// Started reports whether any output has been sent
+func (x *Writer) Started() bool {
+	return x.started
+}
+
+// WriteCSV writes one CSV row
+func (x *Writer) WriteCSV(row []string) error {
+	if err := x.start(); err != nil {
+		return err
+	}
+	return x.csv.Write(row)
+}
+
+// WriteJSON writes one element of the JSON array
+func (x *Writer) WriteJSON(v interface{}) error {
+	if err := x.start(); err != nil {
+		return err
+	}
+	if x.records > 0 {
+		if _, err := x.w.Write([]byte(",")); err != nil {
+			return err
+		}
+	}
+	x.records++
+	return x.json.Encode(v)
+}
+
+// Close finishes the export, sending the headers first if no records were written
+func (x *Writer) Close() error {
+	if err := x.start(); err != nil {
+		return err
+	}
+
+	if x.format == FormatJSON {
+		_, err := x.w.Write([]byte("]\n"))
+		return err
+	}
+
+	x.csv.Flush()
+	return x.csv.Error()
+}
+
+// start sends the response headers and the opening CSV header row or JSON bracket
+func (x *Writer) start() error {
+	if x.started {
+		return nil
+	}
+	x.started = true
+
+	contentType := "text/csv; charset=utf-8"
+	if x.format == FormatJSON {
+		contentType = "application/json"
+	}
+	x.w.Header().Set("Content-Type", contentType)
+	x.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", x.filename+"."+string(x.format)))
+	x.w.WriteHeader(http.StatusOK)
+
+	if x.format == FormatJSON {
+		x.json = json.NewEncoder(x.w)
+		_, err := x.w.Write([]byte("["))
+		return err
+	}
+
+	x.csv = csv.NewWriter(x.w)
+	return x.csv.Write(x.header)
+}
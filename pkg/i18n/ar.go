@@ -0,0 +1,103 @@
+package i18n
+
+// ar is the Arabic message catalog
+var ar = map[string]string{
+	// Balances
+	"balance.you_owe":      "أنت مدين لـ %[1]s بمبلغ %[2]s",
+	"balance.owes_you":     "%[1]s مدين لك بمبلغ %[2]s",
+	"balance.settled_up":   "لا توجد مبالغ مستحقة بينك وبين %[1]s",
+	"balance.in_dispute":   " (%[1]s قيد الاعتراض)",
+	"balance.unknown_user": "مستخدم",
+
+	// Ledger
+	"ledger.settlement_you_paid":    "تسوية دفعتها",
+	"ledger.settlement_paid_to_you": "تسوية دُفعت لك",
+
+	// Budgets
+	"budget.overall":  "الميزانية الشهرية",
+	"budget.category": "ميزانية %[1]s",
+
+	// Notifications
+	"notification.group_invite":              "تمت دعوتك للانضمام إلى المجموعة: %[1]s",
+	"notification.expense_added":             "أضاف %[1]s مصروفًا وعليك مبلغ مستحق",
+	"notification.split_paid":                "يقول %[1]s إنه دفع لك. يرجى التأكيد.",
+	"notification.settlement_created":        "يريد %[1]s تسوية الحساب معك",
+	"notification.settle_reminder":           "تنتهي \"%[1]s\" في %[2]s. يرجى تسوية أرصدتك المفتوحة.",
+	"notification.budget_over":               "تجاوزت \"%[1]s\" %[2]s لشهر %[3]s: أُنفق %[4]s من %[5]s",
+	"notification.budget_threshold":          "استخدمت \"%[1]s\" %[2]s من %[3]s لشهر %[4]s: أُنفق %[5]s من %[6]s",
+	"notification.split_reminder_nudge":      "ذكّرك %[1]s بأن عليك %[2]s مقابل \"%[3]s\"",
+	"notification.split_reminder":            "تذكير: لا يزال عليك لـ %[1]s مبلغ %[2]s مقابل \"%[3]s\"",
+	"notification.settlement_reminder_nudge": "ذكّرك %[1]s بدفع تسويتك البالغة %[2]s",
+	"notification.settlement_reminder":       "تذكير: تسويتك البالغة %[1]s مع %[2]s لا تزال بانتظار الدفع",
+
+	// Notification delivery (email subjects and push titles)
+	"delivery.subject":                 "إشعار جديد",
+	"delivery.subject.GROUP_INVITE":    "تمت دعوتك إلى مجموعة",
+	"delivery.subject.EXPENSE_ADDED":   "مصروف جديد",
+	"delivery.subject.SPLIT_ASSIGNED":  "مصروف جديد",
+	"delivery.subject.SPLIT_PAID":      "دفعة بانتظار تأكيدك",
+	"delivery.subject.SPLIT_CONFIRMED": "تم تأكيد الدفعة",
+	"delivery.subject.SETTLEMENT":      "طلب تسوية",
+	"delivery.subject.BUDGET_ALERT":    "تنبيه الميزانية",
+	"delivery.subject.REMINDER":        "تذكير بالدفع",
+	"delivery.email_footer":            "تصلك هذه الرسالة بناءً على تفضيلات الإشعارات الخاصة بك. يمكنك تغييرها من %[1]s.",
+
+	// Group activity
+	"activity.expense_added":         "أضاف %[1]s \"%[2]s\" (%[3]s)",
+	"activity.expense_updated":       "عدّل %[1]s \"%[2]s\"",
+	"activity.expense_deleted":       "حذف %[1]s \"%[2]s\" (%[3]s)",
+	"activity.split_paid":            "دفع %[1]s حصته من \"%[2]s\" (%[3]s)",
+	"activity.settlement_confirmed":  "أكّد %[1]s تسوية بمبلغ %[2]s من %[3]s",
+	"activity.settlement_reversed":   "ألغى %[1]s تسوية بمبلغ %[2]s من %[3]s: %[4]s",
+	"activity.member_joined":         "انضم %[1]s إلى المجموعة",
+	"activity.member_left":           "غادر %[1]s المجموعة",
+	"activity.member_removed":        "أزال %[1]s %[2]s من المجموعة",
+	"activity.group_auto_archived":   "أُرشفت المجموعة تلقائيًا بعد أن سوّى الجميع حساباتهم",
+	"activity.group_archived":        "أرشف %[1]s المجموعة",
+	"activity.group_unarchived":      "ألغى %[1]s أرشفة المجموعة",
+	"activity.member_made_admin":     "جعل %[1]s %[2]s مشرفًا",
+	"activity.member_made_member":    "غيّر %[1]s دور %[2]s إلى عضو",
+	"activity.ownership_transferred": "نقل %[1]s ملكية المجموعة إلى %[2]s",
+	"activity.other":                 "%[1]s: %[2]s",
+
+	// Group invitation emails
+	"invite.subject": "دعاك %[1]s إلى %[2]s",
+	"invite.body": "دعاك %[1]s للانضمام إلى \"%[2]s\" على Splitwise.\n\n" +
+		"اقبل الدعوة: %[3]s\n\n" +
+		"تنتهي صلاحية هذا الرابط في %[4]s. إذا لم ترغب في الانضمام، يمكنك تجاهل هذه الرسالة أو الرفض من الرابط أعلاه.\n",
+
+	// Member statements
+	"statement.title":             "%[1]s - كشف حساب %[2]s",
+	"statement.for":               "كشف حساب %[1]s",
+	"statement.group_ended":       "انتهت المجموعة في %[1]s",
+	"statement.generated":         "أُنشئ في %[1]s",
+	"statement.expenses":          "المصروفات",
+	"statement.payments":          "المدفوعات",
+	"statement.summary":           "الملخص",
+	"statement.no_expenses":       "لا توجد مصروفات.",
+	"statement.no_payments":       "لا توجد مدفوعات.",
+	"statement.date":              "التاريخ",
+	"statement.description":       "الوصف",
+	"statement.category":          "الفئة",
+	"statement.paid_by":           "دفعها",
+	"statement.total":             "الإجمالي",
+	"statement.your_share":        "حصتك",
+	"statement.status":            "الحالة",
+	"statement.for_expense":       "مقابل",
+	"statement.from":              "من",
+	"statement.to":                "إلى",
+	"statement.amount":            "المبلغ",
+	"statement.you_paid":          "دفعتها أنت",
+	"statement.settlement_ref":    "(تسوية رقم %[1]d)",
+	"statement.total_paid":        "مصروفات دفعتها",
+	"statement.total_share":       "حصتك من المصروفات",
+	"statement.payments_made":     "مدفوعات قمت بها",
+	"statement.payments_received": "مدفوعات استلمتها",
+	"statement.settled_up":        "لا توجد مبالغ مستحقة عليك أو لك.",
+	"statement.you_owe":           "عليك %[1]s.",
+	"statement.owed":              "لك %[1]s.",
+	"statement.status.PENDING":    "قيد الانتظار",
+	"statement.status.PAID":       "مدفوعة",
+	"statement.status.CONFIRMED":  "مؤكدة",
+	"statement.status.DISPUTED":   "معترض عليها",
+}
@@ -0,0 +1,103 @@
+package i18n
+
+// en is the English message catalog, and the fallback for missing translations
+var en = map[string]string{
+	// Balances
+	"balance.you_owe":      "You owe %[1]s %[2]s",
+	"balance.owes_you":     "%[1]s owes you %[2]s",
+	"balance.settled_up":   "You and %[1]s are settled up",
+	"balance.in_dispute":   " (%[1]s in dispute)",
+	"balance.unknown_user": "User",
+
+	// Ledger
+	"ledger.settlement_you_paid":    "Settlement you paid",
+	"ledger.settlement_paid_to_you": "Settlement paid to you",
+
+	// Budgets
+	"budget.overall":  "monthly budget",
+	"budget.category": "%[1]s budget",
+
+	// Notifications
+	"notification.group_invite":              "You have been invited to join group: %[1]s",
+	"notification.expense_added":             "%[1]s added an expense and you owe money",
+	"notification.split_paid":                "%[1]s says they paid you. Please confirm.",
+	"notification.settlement_created":        "%[1]s wants to settle up with you",
+	"notification.settle_reminder":           "\"%[1]s\" ends on %[2]s. Please settle up your open balances.",
+	"notification.budget_over":               "\"%[1]s\" is over its %[2]s for %[3]s: %[4]s of %[5]s spent",
+	"notification.budget_threshold":          "\"%[1]s\" has used %[2]s of its %[3]s for %[4]s: %[5]s of %[6]s spent",
+	"notification.split_reminder_nudge":      "%[1]s reminded you that you owe %[2]s for \"%[3]s\"",
+	"notification.split_reminder":            "Reminder: you still owe %[1]s %[2]s for \"%[3]s\"",
+	"notification.settlement_reminder_nudge": "%[1]s reminded you to pay your %[2]s settlement",
+	"notification.settlement_reminder":       "Reminder: your %[1]s settlement with %[2]s is still waiting to be paid",
+
+	// Notification delivery (email subjects and push titles)
+	"delivery.subject":                 "New notification",
+	"delivery.subject.GROUP_INVITE":    "You've been invited to a group",
+	"delivery.subject.EXPENSE_ADDED":   "New expense",
+	"delivery.subject.SPLIT_ASSIGNED":  "New expense",
+	"delivery.subject.SPLIT_PAID":      "Payment to confirm",
+	"delivery.subject.SPLIT_CONFIRMED": "Payment confirmed",
+	"delivery.subject.SETTLEMENT":      "Settlement request",
+	"delivery.subject.BUDGET_ALERT":    "Budget alert",
+	"delivery.subject.REMINDER":        "Payment reminder",
+	"delivery.email_footer":            "You're getting this email because of your notification preferences. You can change them at %[1]s.",
+
+	// Group activity
+	"activity.expense_added":         "%[1]s added \"%[2]s\" (%[3]s)",
+	"activity.expense_updated":       "%[1]s edited \"%[2]s\"",
+	"activity.expense_deleted":       "%[1]s deleted \"%[2]s\" (%[3]s)",
+	"activity.split_paid":            "%[1]s paid their share of \"%[2]s\" (%[3]s)",
+	"activity.settlement_confirmed":  "%[1]s confirmed a settlement of %[2]s from %[3]s",
+	"activity.settlement_reversed":   "%[1]s reversed a settlement of %[2]s from %[3]s: %[4]s",
+	"activity.member_joined":         "%[1]s joined the group",
+	"activity.member_left":           "%[1]s left the group",
+	"activity.member_removed":        "%[1]s removed %[2]s from the group",
+	"activity.group_auto_archived":   "The group was archived automatically after everyone settled up",
+	"activity.group_archived":        "%[1]s archived the group",
+	"activity.group_unarchived":      "%[1]s unarchived the group",
+	"activity.member_made_admin":     "%[1]s made %[2]s an admin",
+	"activity.member_made_member":    "%[1]s changed %[2]s's role to member",
+	"activity.ownership_transferred": "%[1]s transferred ownership of the group to %[2]s",
+	"activity.other":                 "%[1]s: %[2]s",
+
+	// Group invitation emails
+	"invite.subject": "%[1]s invited you to %[2]s",
+	"invite.body": "%[1]s invited you to join \"%[2]s\" on Splitwise.\n\n" +
+		"Accept the invitation: %[3]s\n\n" +
+		"This link expires on %[4]s. If you don't want to join, you can ignore this email or decline from the link above.\n",
+
+	// Member statements
+	"statement.title":             "%[1]s - statement for %[2]s",
+	"statement.for":               "Statement for %[1]s",
+	"statement.group_ended":       "group ended %[1]s",
+	"statement.generated":         "generated %[1]s",
+	"statement.expenses":          "Expenses",
+	"statement.payments":          "Payments",
+	"statement.summary":           "Summary",
+	"statement.no_expenses":       "No expenses.",
+	"statement.no_payments":       "No payments.",
+	"statement.date":              "Date",
+	"statement.description":       "Description",
+	"statement.category":          "Category",
+	"statement.paid_by":           "Paid by",
+	"statement.total":             "Total",
+	"statement.your_share":        "Your share",
+	"statement.status":            "Status",
+	"statement.for_expense":       "For",
+	"statement.from":              "From",
+	"statement.to":                "To",
+	"statement.amount":            "Amount",
+	"statement.you_paid":          "You paid",
+	"statement.settlement_ref":    "(settlement #%[1]d)",
+	"statement.total_paid":        "Expenses you paid for",
+	"statement.total_share":       "Your share of expenses",
+	"statement.payments_made":     "Payments you made",
+	"statement.payments_received": "Payments you received",
+	"statement.settled_up":        "You are settled up.",
+	"statement.you_owe":           "You owe %[1]s.",
+	"statement.owed":              "You are owed %[1]s.",
+	"statement.status.PENDING":    "Pending",
+	"statement.status.PAID":       "Paid",
+	"statement.status.CONFIRMED":  "Confirmed",
+	"statement.status.DISPUTED":   "Disputed",
+}
@@ -0,0 +1,147 @@
+package i18n
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultCurrency is the currency amounts are in when nothing says otherwise.
+// Settlements are created in it, and expenses and balances don't carry one.
+const DefaultCurrency = "SAR"
+
+// currencyDecimals lists currencies that don't use two minor digits
+var currencyDecimals = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+	"BHD": 3,
+	"JOD": 3,
+	"KWD": 3,
+	"OMR": 3,
+}
+
+// currencySymbols are the symbols shown instead of the ISO code, per language
+var currencySymbols = map[string]map[string]string{
+	English: {"USD": "$", "EUR": "€", "GBP": "£"},
+	Arabic:  {"SAR": "ر.س", "AED": "د.إ", "KWD": "د.ك", "BHD": "د.ب", "QAR": "ر.ق", "OMR": "ر.ع", "EGP": "ج.م", "USD": "US$", "EUR": "€", "GBP": "£"},
+}
+
+// FormatMoney formats an amount in a currency for lang, using the currency's
+// number of minor digits: "SAR 1,234.50" or "$1,234.50" in English and
+// "١٬٢٣٤٫٥٠ ر.س" in Arabic. Unknown currencies are shown with their ISO code.
+func FormatMoney(lang string, amount float64, currency string) string {
+	currency = strings.ToUpper(currency)
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	decimals, ok := currencyDecimals[currency]
+	if !ok {
+		decimals = 2
+	}
+
+	number := formatNumber(lang, amount, decimals)
+	symbol, hasSymbol := currencySymbols[lang][currency]
+
+	if lang == Arabic {
+		if !hasSymbol {
+			symbol = currency
+		}
+		return number + " " + symbol
+	}
+	if !hasSymbol {
+		return currency + " " + number
+	}
+	if strings.HasPrefix(number, "-") {
+		return "-" + symbol + number[1:]
+	}
+	return symbol + number
+}
+
+// FormatPercent formats a whole percentage for lang, e.g. "80%" or "٨٠٪"
+func FormatPercent(lang string, percent int) string {
+	if lang == Arabic {
+		return formatNumber(lang, float64(percent), 0) + "٪"
+	}
+	return strconv.Itoa(percent) + "%"
+}
+
+// arabicMonths names the months for FormatDate and FormatMonth
+var arabicMonths = [...]string{
+	"يناير", "فبراير", "مارس", "أبريل", "مايو", "يونيو",
+	"يوليو", "أغسطس", "سبتمبر", "أكتوبر", "نوفمبر", "ديسمبر",
+}
+
+// FormatDate formats a calendar date for lang, e.g. "Mar 5, 2024" or "٥ مارس ٢٠٢٤"
+func FormatDate(lang string, t time.Time) string {
+	if lang == Arabic {
+		return localizeDigits(lang, strconv.Itoa(t.Day())) + " " + arabicMonths[t.Month()-1] + " " +
+			localizeDigits(lang, strconv.Itoa(t.Year()))
+	}
+	return t.Format("Jan 2, 2006")
+}
+
+// FormatDateTime formats a date and time of day for lang, with the time zone,
+// e.g. "Mar 5, 2024 14:30 UTC" or "٥ مارس ٢٠٢٤ ١٤:٣٠ UTC"
+func FormatDateTime(lang string, t time.Time) string {
+	if lang == Arabic {
+		return FormatDate(lang, t) + " " + localizeDigits(lang, t.Format("15:04")) + " " + t.Format("MST")
+	}
+	return t.Format("Jan 2, 2006 15:04 MST")
+}
+
+// FormatMonth formats a month for lang, e.g. "March 2024" or "مارس ٢٠٢٤"
+func FormatMonth(lang string, t time.Time) string {
+	if lang == Arabic {
+		return arabicMonths[t.Month()-1] + " " + localizeDigits(lang, strconv.Itoa(t.Year()))
+	}
+	return t.Format("January 2006")
+}
+
+// formatNumber rounds a number to decimals and groups its thousands for lang
+func formatNumber(lang string, value float64, decimals int) string {
+	negative := value < 0
+	scale := math.Pow(10, float64(decimals))
+	value = math.Round(math.Abs(value)*scale) / scale
+	if value == 0 {
+		negative = false
+	}
+
+	digits := strconv.FormatFloat(value, 'f', decimals, 64)
+	whole, fraction, _ := strings.Cut(digits, ".")
+
+	groupSep, decimalSep := ",", "."
+	if lang == Arabic {
+		groupSep, decimalSep = "٬", "٫"
+	}
+
+	var b strings.Builder
+	if negative {
+		b.WriteByte('-')
+	}
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(groupSep)
+		}
+		b.WriteRune(r)
+	}
+	if fraction != "" {
+		b.WriteString(decimalSep)
+		b.WriteString(fraction)
+	}
+
+	return localizeDigits(lang, b.String())
+}
+
+// localizeDigits writes ASCII digits in lang's numerals (Arabic-Indic for Arabic)
+func localizeDigits(lang, s string) string {
+	if lang != Arabic {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return '٠' + (r - '0')
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,123 @@
+// Package i18n translates user-facing messages and formats money, numbers
+// and dates for the supported languages.
+package i18n
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Supported languages
+const (
+	English = "en"
+	Arabic  = "ar"
+
+	// Default is used when a request or user doesn't ask for a supported language
+	Default = English
+)
+
+// ErrUnsupportedLanguage is returned for languages without a message catalog
+var ErrUnsupportedLanguage = errors.New("language must be one of: en, ar")
+
+// catalogs holds the messages of every supported language, keyed by message key.
+// Messages are fmt formats using explicit argument indexes (%[1]s) so translations
+// can put the arguments in their own order.
+var catalogs = map[string]map[string]string{
+	English: en,
+	Arabic:  ar,
+}
+
+// Normalize returns the supported language for a tag such as "ar-SA" or "EN"
+func Normalize(tag string) (string, error) {
+	base := strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(base, "-_"); i >= 0 {
+		base = base[:i]
+	}
+	if _, ok := catalogs[base]; !ok {
+		return "", ErrUnsupportedLanguage
+	}
+	return base, nil
+}
+
+// Negotiate picks the supported language a client prefers most from an
+// Accept-Language header, e.g. "ar-SA,ar;q=0.9,en;q=0.8". It falls back to Default.
+func Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				v, err := strconv.ParseFloat(param[2:], 64)
+				if err != nil {
+					v = 0
+				}
+				q = v
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		if tag == "*" {
+			candidates = append(candidates, candidate{Default, q})
+			continue
+		}
+		if lang, err := Normalize(tag); err == nil {
+			candidates = append(candidates, candidate{lang, q})
+		}
+	}
+
+	// Stable, so equally weighted languages keep the client's order
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	if len(candidates) == 0 {
+		return Default
+	}
+	return candidates[0].lang
+}
+
+// T translates a message into lang, falling back to English and then to the key itself
+func T(lang, key string, args ...any) string {
+	format, ok := catalogs[lang][key]
+	if !ok {
+		if format, ok = en[key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+type contextKey struct{}
+
+// WithLanguage returns a context carrying the language to reply in
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// Language returns the context's language, or Default if it has none
+func Language(ctx context.Context) string {
+	if lang, ok := ctx.Value(contextKey{}).(string); ok && lang != "" {
+		return lang
+	}
+	return Default
+}
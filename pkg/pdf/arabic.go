@@ -0,0 +1,277 @@
+package pdf
+
+import "unicode"
+
+// PDF text is drawn glyph by glyph, left to right, so Arabic has to be shaped
+// (each letter swapped for its joined form) and put in visual order before it
+// is drawn. This covers the Arabic alphabet and simple mixed text such as a
+// name followed by an amount; it is not a full Unicode bidi implementation.
+
+// arabicForm is how a letter joins: its isolated form in Arabic Presentation
+// Forms-B, followed by its final form and, for dual-joining letters, its
+// initial and medial forms
+type arabicForm struct {
+	isolated rune
+	dual     bool // Joins the letter after it as well as the one before
+}
+
+var arabicForms = map[rune]arabicForm{
+	'ء': {0xFE80, false}, // hamza; joins neither side
+	'آ': {0xFE81, false},
+	'أ': {0xFE83, false},
+	'ؤ': {0xFE85, false},
+	'إ': {0xFE87, false},
+	'ئ': {0xFE89, true},
+	'ا': {0xFE8D, false},
+	'ب': {0xFE8F, true},
+	'ة': {0xFE93, false},
+	'ت': {0xFE95, true},
+	'ث': {0xFE99, true},
+	'ج': {0xFE9D, true},
+	'ح': {0xFEA1, true},
+	'خ': {0xFEA5, true},
+	'د': {0xFEA9, false},
+	'ذ': {0xFEAB, false},
+	'ر': {0xFEAD, false},
+	'ز': {0xFEAF, false},
+	'س': {0xFEB1, true},
+	'ش': {0xFEB5, true},
+	'ص': {0xFEB9, true},
+	'ض': {0xFEBD, true},
+	'ط': {0xFEC1, true},
+	'ظ': {0xFEC5, true},
+	'ع': {0xFEC9, true},
+	'غ': {0xFECD, true},
+	'ف': {0xFED1, true},
+	'ق': {0xFED5, true},
+	'ك': {0xFED9, true},
+	'ل': {0xFEDD, true},
+	'م': {0xFEE1, true},
+	'ن': {0xFEE5, true},
+	'ه': {0xFEE9, true},
+	'و': {0xFEED, false},
+	'ى': {0xFEEF, false},
+	'ي': {0xFEF1, true},
+}
+
+// lamAlef maps the alef that follows a lam to the isolated form of their ligature
+var lamAlef = map[rune]rune{
+	'آ': 0xFEF5,
+	'أ': 0xFEF7,
+	'إ': 0xFEF9,
+	'ا': 0xFEFB,
+}
+
+const (
+	lam     = 'ل'
+	hamza   = 'ء'
+	tatweel = 'ـ'
+)
+
+// visual returns text ready to draw: Arabic shaped and laid out right to left,
+// with Latin text and numbers inside it kept left to right. Text that starts
+// with a Latin letter reads left to right, with each stretch of Arabic in it
+// turned around.
+func visual(text string) string {
+	runes := []rune(text)
+	if !hasRTL(runes) {
+		return text
+	}
+
+	shaped := shapeArabic(runes)
+	if startsRTL(shaped) {
+		return string(reverseRTL(shaped))
+	}
+
+	for i := 0; i < len(shaped); {
+		if !isRTL(shaped[i]) {
+			i++
+			continue
+		}
+		// The stretch runs to the last Arabic letter before the next Latin one
+		end := i + 1
+		for j := i + 1; j < len(shaped) && !isLatin(shaped[j]); j++ {
+			if isRTL(shaped[j]) {
+				end = j + 1
+			}
+		}
+		copy(shaped[i:end], reverseRTL(shaped[i:end]))
+		i = end
+	}
+	return string(shaped)
+}
+
+// reverseRTL lays out right-to-left text for drawing left to right: everything
+// is reversed, then runs of Latin text and numbers are turned back around
+func reverseRTL(runes []rune) []rune {
+	out := make([]rune, len(runes))
+	for i, r := range runes {
+		out[len(runes)-1-i] = mirror(r)
+	}
+	for i := 0; i < len(out); {
+		if !isLTR(out[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(out) && !isRTL(out[j]) {
+			j++
+		}
+		// Spaces and punctuation at the edges of the run belong to the Arabic around it
+		end := j
+		for end > i && !isLTR(out[end-1]) {
+			end--
+		}
+		for a, b := i, end-1; a < b; a, b = a+1, b-1 {
+			out[a], out[b] = mirror(out[b]), mirror(out[a])
+		}
+		i = j
+	}
+	return out
+}
+
+// shapeArabic replaces Arabic letters with their initial, medial, final or
+// isolated forms depending on their neighbours, and joins lam-alef pairs
+func shapeArabic(runes []rune) []rune {
+	out := make([]rune, 0, len(runes))
+	prevJoins := false // Whether the previous letter connects to this one
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if isTransparent(r) {
+			out = append(out, r)
+			continue
+		}
+		if r == tatweel {
+			out = append(out, r)
+			prevJoins = true
+			continue
+		}
+
+		form, ok := arabicForms[r]
+		if !ok {
+			out = append(out, r)
+			prevJoins = false
+			continue
+		}
+
+		joinsPrev := prevJoins && r != hamza
+		next := nextLetter(runes, i)
+
+		if r == lam {
+			if ligature, ok := lamAlef[next]; ok {
+				if joinsPrev {
+					ligature++ // Final form
+				}
+				out = append(out, ligature)
+				i = skipTo(runes, i, next)
+				prevJoins = false
+				continue
+			}
+		}
+
+		_, nextIsLetter := arabicForms[next]
+		joinsNext := form.dual && (nextIsLetter && next != hamza || next == tatweel)
+
+		switch {
+		case joinsPrev && joinsNext:
+			out = append(out, form.isolated+3)
+		case joinsNext:
+			out = append(out, form.isolated+2)
+		case joinsPrev:
+			out = append(out, form.isolated+1)
+		default:
+			out = append(out, form.isolated)
+		}
+		prevJoins = joinsNext
+	}
+	return out
+}
+
+// nextLetter returns the next rune after i that isn't a vowel mark, or 0
+func nextLetter(runes []rune, i int) rune {
+	for j := i + 1; j < len(runes); j++ {
+		if !isTransparent(runes[j]) {
+			return runes[j]
+		}
+	}
+	return 0
+}
+
+// skipTo returns the index of the first occurrence of r after i
+func skipTo(runes []rune, i int, r rune) int {
+	for j := i + 1; j < len(runes); j++ {
+		if runes[j] == r {
+			return j
+		}
+	}
+	return i
+}
+
+// isTransparent reports whether r is a vowel mark, which doesn't affect joining
+func isTransparent(r rune) bool {
+	return r >= 0x064B && r <= 0x065F || r == 0x0670
+}
+
+func hasRTL(runes []rune) bool {
+	for _, r := range runes {
+		if isRTL(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRTL reports whether r is written right to left. Arabic-Indic digits and
+// their separators are numbers, which read left to right.
+func isRTL(r rune) bool {
+	switch {
+	case r >= 0x0660 && r <= 0x066C: // Arabic-Indic digits, percent and separators
+		return false
+	case r >= 0x0600 && r <= 0x06FF, r >= 0xFB50 && r <= 0xFDFF, r >= 0xFE70 && r <= 0xFEFC:
+		return true
+	}
+	return false
+}
+
+// startsRTL reports whether the first letter of the text is written right to left
+func startsRTL(runes []rune) bool {
+	for _, r := range runes {
+		if isRTL(r) {
+			return true
+		}
+		if isLatin(r) {
+			return false
+		}
+	}
+	return false
+}
+
+// isLatin reports whether r is a letter written left to right
+func isLatin(r rune) bool {
+	return unicode.IsLetter(r) && !isRTL(r)
+}
+
+// isLTR reports whether r starts or ends a left-to-right run: a Latin letter or a digit
+func isLTR(r rune) bool {
+	return isLatin(r) || unicode.IsDigit(r) && !isRTL(r)
+}
+
+// mirror swaps brackets, which point the other way in right-to-left text
+func mirror(r rune) rune {
+	switch r {
+	case '(':
+		return ')'
+	case ')':
+		return '('
+	case '[':
+		return ']'
+	case ']':
+		return '['
+	case '<':
+		return '>'
+	case '>':
+		return '<'
+	}
+	return r
+}
@@ -0,0 +1,164 @@
+// Package pdf writes simple text documents as PDF. It covers what printable
+// reports need: headings, paragraphs, table rows with left or right aligned
+// cells, horizontal rules and automatic page breaks. Text is set in an
+// embedded DejaVu Sans, so names and amounts in any language the font covers,
+// Arabic included, print as written.
+package pdf
+
+import (
+	"bytes"
+	"io"
+
+	"codeberg.org/go-fonts/dejavu/dejavusans"
+	"codeberg.org/go-fonts/dejavu/dejavusansbold"
+	"codeberg.org/go-pdf/fpdf"
+)
+
+// A4 portrait in points, with 1.5cm margins
+const (
+	pageWidth    = 595.28
+	pageHeight   = 841.89
+	margin       = 42.52
+	ContentWidth = pageWidth - 2*margin
+)
+
+// Font sizes and line spacing
+const (
+	fontFamily  = "DejaVuSans"
+	headingSize = 16
+	textSize    = 9
+	lineFactor  = 1.4
+	cellPadding = 8
+)
+
+// Align positions text within a table cell
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignRight
+)
+
+// Cell is one column of a table row
+type Cell struct {
+	Text  string
+	Width float64 // In points; widths of a row should add up to ContentWidth
+	Align Align
+}
+
+// Document is a PDF being built page by page
+type Document struct {
+	pdf *fpdf.Fpdf
+	rtl bool // Lay lines and rows out from the right, for right-to-left languages
+}
+
+// New creates an empty A4 document
+func New() *Document {
+	f := fpdf.New("P", "pt", "A4", "")
+	f.SetMargins(margin, margin, margin)
+	f.SetAutoPageBreak(false, margin) // Rows break pages themselves so they stay whole
+	f.SetCellMargin(0)
+	f.AddUTF8FontFromBytes(fontFamily, "", dejavusans.TTF)
+	f.AddUTF8FontFromBytes(fontFamily, "B", dejavusansbold.TTF)
+	f.AddPage()
+	return &Document{pdf: f}
+}
+
+// SetRightToLeft lays out everything added after it from the right: text is
+// aligned right, and the first cell of a row is the rightmost one
+func (d *Document) SetRightToLeft(rtl bool) {
+	d.rtl = rtl
+}
+
+// Heading adds a line of large bold text
+func (d *Document) Heading(text string) {
+	d.pdf.SetFont(fontFamily, "B", headingSize)
+	d.line(headingSize)
+	d.pdf.CellFormat(ContentWidth, headingSize*lineFactor, d.fit(text, ContentWidth), "", 1, d.align(AlignLeft), false, 0, "")
+}
+
+// Text adds a line of regular text; long lines are cut to the page width
+func (d *Document) Text(text string) {
+	d.pdf.SetFont(fontFamily, "", textSize)
+	d.line(textSize)
+	d.pdf.CellFormat(ContentWidth, textSize*lineFactor, d.fit(text, ContentWidth), "", 1, d.align(AlignLeft), false, 0, "")
+}
+
+// Row adds a table row, optionally in bold
+func (d *Document) Row(cells []Cell, bold bool) {
+	style := ""
+	if bold {
+		style = "B"
+	}
+	d.pdf.SetFont(fontFamily, style, textSize)
+	d.line(textSize)
+
+	x := margin
+	if d.rtl {
+		x = pageWidth - margin
+	}
+	for _, c := range cells {
+		// Leave a gutter between columns, on the side the next column is on
+		textX := x
+		if d.rtl {
+			x -= c.Width
+			textX = x + cellPadding
+		} else {
+			x += c.Width
+		}
+		d.pdf.SetX(textX)
+		d.pdf.CellFormat(c.Width-cellPadding, textSize*lineFactor, d.fit(c.Text, c.Width-cellPadding), "", 0, d.align(c.Align), false, 0, "")
+	}
+	d.pdf.Ln(textSize * lineFactor)
+}
+
+// Rule draws a thin horizontal line across the page
+func (d *Document) Rule() {
+	d.line(textSize * 0.5)
+	y := d.pdf.GetY() + textSize*0.25
+	d.pdf.SetLineWidth(0.5)
+	d.pdf.Line(margin, y, pageWidth-margin, y)
+	d.pdf.Ln(textSize * 0.5)
+}
+
+// Gap adds vertical space, in points
+func (d *Document) Gap(points float64) {
+	d.pdf.Ln(points)
+}
+
+// WriteTo writes the finished document
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	if err := d.pdf.Output(&buf); err != nil {
+		return 0, err
+	}
+	return buf.WriteTo(w)
+}
+
+// align returns the fpdf alignment for a, mirrored in right-to-left documents
+func (d *Document) align(a Align) string {
+	if (a == AlignRight) != d.rtl {
+		return "R"
+	}
+	return "L"
+}
+
+// line starts a new page if a line of the given font size doesn't fit on this one
+func (d *Document) line(size float64) {
+	if d.pdf.GetY()+size*lineFactor > pageHeight-margin {
+		d.pdf.AddPage()
+	}
+}
+
+// fit prepares text for drawing in the current font, shortening it with an
+// ellipsis so it is at most width points wide
+func (d *Document) fit(text string, width float64) string {
+	if d.pdf.GetStringWidth(visual(text)) <= width {
+		return visual(text)
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && d.pdf.GetStringWidth(visual(string(runes)+"...")) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return visual(string(runes) + "...")
+}